@@ -0,0 +1,352 @@
+// Command mknames generates the name tables of constant families, to turn
+// 0x0112 back into "WM_SYSCOMMAND". Each argument is a family:
+//
+//	PREFIX            an enumeration, WM_ or VK_
+//	PREFIX:flags      a bitmask, SWP_
+//	PREFIX:flags:M,.. a bitmask whose bits under each mask M are enumerated
+//	                  values rather than flags, MB_:flags:0xF,0xF0
+//
+// A constant belongs to the family with the longest matching prefix, WS_EX_
+// before WS_. When several constants have the same value, the first declared
+// wins, except that aliases (X = Y) and range markers (*FIRST, *LAST) lose to
+// any other name. Masks (*MASK) are left out.
+//
+// Usage, in the package that declares the constants:
+//
+//	//go:generate go run ./cmd/mknames -output znames.go WM_ SWP_:flags
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	output = flag.String("output", "", "output file, standard output when empty")
+	dir    = flag.String("dir", ".", "package directory")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: mknames [-output file] [-dir dir] PREFIX[:flags[:mask,...]]...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("mknames: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+
+	var families []*Family
+	for _, arg := range flag.Args() {
+		f, err := parseFamily(arg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		families = append(families, f)
+	}
+	pkg, consts, err := parseDir(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, c := range consts {
+		if f := familyOf(families, c.Name); f != nil {
+			f.Consts = append(f.Consts, c)
+		}
+	}
+	src, err := generate(pkg, families)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type Family struct {
+	Prefix string
+	Flags  bool
+	Masks  []uint32
+	Consts []Const
+}
+
+type Const struct {
+	Name  string
+	Value uint32
+	Alias bool // declared as another constant, or a range marker
+}
+
+func parseFamily(arg string) (*Family, error) {
+	parts := strings.Split(arg, ":")
+	f := &Family{Prefix: parts[0]}
+	if f.Prefix == "" || len(parts) > 3 || len(parts) > 1 && parts[1] != "flags" {
+		return nil, fmt.Errorf("bad family %q", arg)
+	}
+	f.Flags = len(parts) > 1
+	if len(parts) == 3 {
+		for _, m := range strings.Split(parts[2], ",") {
+			v, err := strconv.ParseUint(m, 0, 32)
+			if err != nil || v == 0 {
+				return nil, fmt.Errorf("bad mask %q in family %q", m, arg)
+			}
+			f.Masks = append(f.Masks, uint32(v))
+		}
+	}
+	return f, nil
+}
+
+func familyOf(families []*Family, name string) *Family {
+	var best *Family
+	for _, f := range families {
+		if strings.HasPrefix(name, f.Prefix) && (best == nil || len(f.Prefix) > len(best.Prefix)) {
+			best = f
+		}
+	}
+	return best
+}
+
+// parseDir returns the package name and the constants of the package in dir
+// that have a value fitting in 32 bits, in declaration order, the files of
+// every platform included.
+func parseDir(dir string) (string, []Const, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	files := append(append([]string(nil), bp.GoFiles...), bp.IgnoredGoFiles...)
+	sort.Strings(files)
+
+	fset := token.NewFileSet()
+	exprs := map[string]ast.Expr{}
+	var names []string
+	for _, name := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		if f.Name.Name != bp.Name {
+			continue
+		}
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, id := range vs.Names {
+					if i < len(vs.Values) && id.Name != "_" {
+						exprs[id.Name] = vs.Values[i]
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	}
+
+	e := evaluator{exprs: exprs, values: map[string]constant.Value{}}
+	var consts []Const
+	for _, name := range names {
+		v := e.eval(name)
+		if v == nil || v.Kind() != constant.Int {
+			continue
+		}
+		u, exact := constant.Uint64Val(v)
+		if !exact || u > 0xFFFFFFFF || strings.HasSuffix(name, "MASK") {
+			continue
+		}
+		x := exprs[name]
+		for p, ok := x.(*ast.ParenExpr); ok; p, ok = x.(*ast.ParenExpr) {
+			x = p.X
+		}
+		_, alias := x.(*ast.Ident)
+		alias = alias || strings.HasSuffix(name, "FIRST") || strings.HasSuffix(name, "LAST")
+		consts = append(consts, Const{Name: name, Value: uint32(u), Alias: alias})
+	}
+	return bp.Name, consts, nil
+}
+
+// evaluator computes the untyped value of constants from their declarations,
+// nil for what it can't: iota, conversions, constants of other packages.
+type evaluator struct {
+	exprs  map[string]ast.Expr
+	values map[string]constant.Value
+}
+
+func (e *evaluator) eval(name string) constant.Value {
+	if v, ok := e.values[name]; ok {
+		return v
+	}
+	e.values[name] = nil // breaks cycles
+	var v constant.Value
+	if x, ok := e.exprs[name]; ok {
+		v = e.expr(x)
+	}
+	e.values[name] = v
+	return v
+}
+
+func (e *evaluator) expr(x ast.Expr) constant.Value {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.INT {
+			return nil
+		}
+		// 0X1F is as valid as 0x1F
+		v := constant.MakeFromLiteral(strings.Replace(x.Value, "0X", "0x", 1), x.Kind, 0)
+		if v.Kind() == constant.Unknown {
+			return nil
+		}
+		return v
+	case *ast.Ident:
+		return e.eval(x.Name)
+	case *ast.ParenExpr:
+		return e.expr(x.X)
+	case *ast.UnaryExpr:
+		v := e.expr(x.X)
+		if v == nil {
+			return nil
+		}
+		if x.Op == token.XOR { // untyped ^x is -x-1
+			return constant.BinaryOp(constant.MakeInt64(-1), token.SUB, v)
+		}
+		return constant.UnaryOp(x.Op, v, 0)
+	case *ast.BinaryExpr:
+		a, b := e.expr(x.X), e.expr(x.Y)
+		if a == nil || b == nil {
+			return nil
+		}
+		switch x.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(b)
+			if !ok {
+				return nil
+			}
+			return constant.Shift(a, x.Op, uint(s))
+		case token.QUO:
+			return constant.BinaryOp(a, token.QUO_ASSIGN, b) // integer division
+		case token.ADD, token.SUB, token.MUL, token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+			return constant.BinaryOp(a, x.Op, b)
+		}
+	case *ast.CallExpr:
+		// conversions to the family types, BoxType(0x10)
+		if len(x.Args) == 1 {
+			if _, ok := x.Fun.(*ast.Ident); ok {
+				return e.expr(x.Args[0])
+			}
+		}
+	}
+	return nil
+}
+
+func generate(pkg string, families []*Family) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by mknames; DO NOT EDIT.\n\npackage %s\n", pkg)
+	for _, f := range families {
+		if len(f.Consts) == 0 {
+			return nil, fmt.Errorf("no constants with prefix %s", f.Prefix)
+		}
+		consts := preferred(f.Consts)
+		fmt.Fprintf(&b, "\n// %s*\n", f.Prefix)
+		if !f.Flags {
+			sort.SliceStable(consts, func(i, j int) bool { return consts[i].Value < consts[j].Value })
+			fmt.Fprintf(&b, "var %s = enumNames{\n", varName(f.Prefix))
+			writeConsts(&b, consts)
+			b.WriteString("}\n")
+			continue
+		}
+
+		var zero string
+		var nonzero []Const
+		for _, c := range consts {
+			if c.Value == 0 {
+				zero = c.Name
+			} else {
+				nonzero = append(nonzero, c)
+			}
+		}
+		// combined flags first, so that WS_CAPTION wins over WS_BORDER|WS_DLGFRAME
+		sort.SliceStable(nonzero, func(i, j int) bool {
+			return bits.OnesCount32(nonzero[i].Value) > bits.OnesCount32(nonzero[j].Value)
+		})
+		fmt.Fprintf(&b, "var %s = flagNames{\n", varName(f.Prefix))
+		if zero != "" {
+			fmt.Fprintf(&b, "zero: %q,\n", zero)
+		}
+		if len(f.Masks) > 0 {
+			b.WriteString("masks: []uint32{")
+			for i, m := range f.Masks {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				fmt.Fprintf(&b, "%#x", m)
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("names: []constName{\n")
+		writeConsts(&b, nonzero)
+		b.WriteString("},\n}\n")
+	}
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not parse: %v\n%s", err, b.String())
+	}
+	return src, nil
+}
+
+// preferred keeps one constant per value, in declaration order.
+func preferred(consts []Const) []Const {
+	best := map[uint32]int{}
+	for i, c := range consts {
+		if j, ok := best[c.Value]; !ok || consts[j].Alias && !c.Alias {
+			best[c.Value] = i
+		}
+	}
+	var out []Const
+	for i, c := range consts {
+		if best[c.Value] == i {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func writeConsts(b *strings.Builder, consts []Const) {
+	for _, c := range consts {
+		fmt.Fprintf(b, "{%#x, %q},\n", c.Value, c.Name)
+	}
+}
+
+// varName returns the table of a prefix, wsExNames for WS_EX_.
+func varName(prefix string) string {
+	var s string
+	for i, part := range strings.Split(strings.Trim(prefix, "_"), "_") {
+		part = strings.ToLower(part)
+		if i > 0 && part != "" {
+			part = strings.ToUpper(part[:1]) + part[1:]
+		}
+		s += part
+	}
+	return s + "Names"
+}
@@ -0,0 +1,479 @@
+// Command mkwinapi generates DLL bindings from //sys comments, in the style of
+// golang.org/x/sys/windows/mkwinsyscall. A declaration is one line:
+//
+//	//sys	Name(params) (results) [failretval<cond>] = dll.Symbol
+//
+// The results, the failretval clause and the dll are optional: dll defaults
+// to kernel32 and Symbol to Name. The comment lines right above a //sys line
+// become the doc comment of the wrapper. The wrappers call the procedures
+// through internal/proc, so they build on every platform and can be run
+// against a proc.Fake.
+//
+// Parameters are passed as follows:
+//
+//	string     NUL terminated UTF-16, or bytes when Symbol ends in A; the
+//	           wrapper must return err
+//	optstring  a string passed as NULL when empty, it is a string in the
+//	           wrapper
+//	bool       1 or 0
+//	[]T        a pointer to the first element followed by the length
+//	*T         the pointer, which stays valid during the call
+//	int64      two words on 32 bit platforms, low word first, as do uint64,
+//	           LONGLONG, ULONGLONG and DWORD64
+//	others     converted with uintptr(), structs passed by value and floating
+//	           point are not supported
+//
+// Results are at most a value and an error. A bool result is r1 != 0, a 64
+// bit result is built from r1 and r2 on 32 bit platforms. An error result
+// named err is set to a *winapi.Error from the last error when the failure
+// condition holds: the value followed by <cond>, !value for bools, value == 0
+// otherwise, or r1 == 0 without a value. An error result with another name is
+// the *winapi.Error of r1 itself, for the functions that return their error
+// code:
+//
+//	//sys	RegCloseKey(key HKEY) (regerrno error) = advapi32.RegCloseKey
+//
+// Usage, next to the declarations:
+//
+//	//go:generate go run ./cmd/mkwinapi -output zsys.go sys.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	output   = flag.String("output", "", "output file, standard output when empty")
+	procPath = flag.String("proc", "github.com/FxStar/winapi/internal/proc", "import path of the proc package")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: mkwinapi [-output file] [-proc importpath] file.go...\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("mkwinapi: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+
+	var g Generator
+	for _, file := range flag.Args() {
+		if err := g.ParseFile(file); err != nil {
+			log.Fatal(err)
+		}
+	}
+	src, err := g.Generate()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var (
+	sysRegexp  = regexp.MustCompile(`^(\w+)\s*\(([^()]*)\)\s*(?:\(([^()]*)\))?\s*(?:\[failretval(.+?)\])?\s*(?:=\s*(?:(\w+)\.)?(\w+))?$`)
+	pkgRegexp  = regexp.MustCompile(`^package\s+(\w+)`)
+	wide64     = map[string]bool{"int64": true, "uint64": true, "LONGLONG": true, "ULONGLONG": true, "DWORD64": true}
+	floatTypes = map[string]bool{"float32": true, "float64": true, "FLOAT": true, "DOUBLE": true}
+)
+
+type Param struct {
+	Name, Type string
+}
+
+// Fn is a parsed //sys declaration.
+type Fn struct {
+	Name     string
+	Params   []Param
+	Ret      *Param // the value result, nil if none
+	Err      *Param // the error result, nil if none
+	FailCond string // appended to the value, "" for the default rule
+	DLL      string
+	Symbol   string
+	Doc      []string
+	Pos      string
+}
+
+type Generator struct {
+	Package string
+	Funcs   []*Fn
+
+	syscall, unsafe bool
+}
+
+func (g *Generator) ParseFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var doc []string
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		t := strings.TrimSpace(s.Text())
+		pos := fmt.Sprintf("%s:%d", file, line)
+		switch {
+		case strings.HasPrefix(t, "//sys ") || strings.HasPrefix(t, "//sys\t"):
+			fn, err := parseSys(strings.TrimSpace(t[len("//sys"):]))
+			if err != nil {
+				return fmt.Errorf("%s: %v", pos, err)
+			}
+			fn.Doc, fn.Pos = doc, pos
+			g.Funcs = append(g.Funcs, fn)
+			doc = nil
+		case strings.HasPrefix(t, "//go:"):
+			doc = nil
+		case strings.HasPrefix(t, "//"):
+			doc = append(doc, t)
+		default:
+			doc = nil
+			if m := pkgRegexp.FindStringSubmatch(t); m != nil {
+				if g.Package != "" && g.Package != m[1] {
+					return fmt.Errorf("%s: package %s, expected %s", pos, m[1], g.Package)
+				}
+				g.Package = m[1]
+			}
+		}
+	}
+	return s.Err()
+}
+
+func parseSys(decl string) (*Fn, error) {
+	m := sysRegexp.FindStringSubmatch(decl)
+	if m == nil {
+		return nil, fmt.Errorf("malformed //sys declaration %q", decl)
+	}
+	fn := &Fn{Name: m[1], FailCond: strings.TrimSpace(m[4]), DLL: m[5], Symbol: m[6]}
+	if fn.DLL == "" {
+		fn.DLL = "kernel32"
+	}
+	if fn.Symbol == "" {
+		fn.Symbol = fn.Name
+	}
+	params, err := parseParams(m[2])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name, err)
+	}
+	for _, p := range params {
+		if floatTypes[p.Type] {
+			return nil, fmt.Errorf("%s: %s: floating point arguments are not supported", fn.Name, p.Name)
+		}
+	}
+	fn.Params = params
+
+	rets, err := parseParams(m[3])
+	if err != nil {
+		return nil, fmt.Errorf("%s: results: %v", fn.Name, err)
+	}
+	for i := range rets {
+		r := &rets[i]
+		switch {
+		case r.Type == "error" && fn.Err == nil:
+			fn.Err = r
+		case r.Type != "error" && fn.Ret == nil && fn.Err == nil:
+			if strings.HasPrefix(r.Type, "*") || r.Type == "string" || floatTypes[r.Type] {
+				return nil, fmt.Errorf("%s: %s results are not supported, return uintptr", fn.Name, r.Type)
+			}
+			fn.Ret = r
+		default:
+			return nil, fmt.Errorf("%s: results must be a value, an error or a value and an error", fn.Name)
+		}
+	}
+	errResult := fn.Err != nil && fn.Err.Name == "err"
+	if fn.FailCond != "" && !errResult {
+		return nil, fmt.Errorf("%s: failretval without an err error result", fn.Name)
+	}
+	if fn.Err != nil && !errResult && fn.Ret != nil {
+		return nil, fmt.Errorf("%s: %s is the returned error code, there is no other value", fn.Name, fn.Err.Name)
+	}
+	for _, p := range params {
+		if (p.Type == "string" || p.Type == "optstring") && !errResult {
+			return nil, fmt.Errorf("%s: %s: string arguments need an err error result", fn.Name, p.Name)
+		}
+	}
+	return fn, nil
+}
+
+// parseParams parses "a, b int32, p *POINT", the results included.
+func parseParams(list string) ([]Param, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, nil
+	}
+	var ps []Param
+	untyped := 0
+	for _, f := range strings.Split(list, ",") {
+		f = strings.TrimSpace(f)
+		i := strings.IndexAny(f, " \t")
+		if i < 0 {
+			if !isIdent(f) {
+				return nil, fmt.Errorf("bad parameter %q", f)
+			}
+			ps = append(ps, Param{Name: f})
+			untyped++
+			continue
+		}
+		p := Param{Name: f[:i], Type: strings.TrimSpace(f[i:])}
+		if !isIdent(p.Name) {
+			return nil, fmt.Errorf("bad parameter %q, parameters must be named", f)
+		}
+		for ; untyped > 0; untyped-- {
+			ps[len(ps)-untyped].Type = p.Type
+		}
+		ps = append(ps, p)
+	}
+	if untyped > 0 {
+		return nil, fmt.Errorf("parameter %s has no type", ps[len(ps)-1].Name)
+	}
+	return ps, nil
+}
+
+func isIdent(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (g *Generator) Generate() ([]byte, error) {
+	if g.Package == "" {
+		return nil, fmt.Errorf("no package clause")
+	}
+	if len(g.Funcs) == 0 {
+		return nil, fmt.Errorf("no //sys declarations")
+	}
+	seen := map[string]string{}
+	for _, fn := range g.Funcs {
+		if pos, ok := seen[fn.Name]; ok {
+			return nil, fmt.Errorf("%s: %s already declared at %s", fn.Pos, fn.Name, pos)
+		}
+		seen[fn.Name] = fn.Pos
+	}
+
+	var body bytes.Buffer
+	for _, fn := range g.Funcs {
+		g.writeFunc(&body, fn)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mkwinapi; DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.Package)
+	if g.syscall {
+		b.WriteString("\t\"syscall\"\n")
+	}
+	if g.unsafe {
+		b.WriteString("\t\"unsafe\"\n")
+	}
+	fmt.Fprintf(&b, "\n\t%q\n)\n\n", *procPath)
+
+	dlls := map[string]bool{}
+	procs := map[string]string{}
+	for _, fn := range g.Funcs {
+		dlls[fn.DLL] = true
+		procs[fn.Symbol] = fn.DLL
+	}
+	b.WriteString("var (\n")
+	for _, dll := range sortedKeys(dlls) {
+		fmt.Fprintf(&b, "\t%s = proc.NewDLL(%q)\n", dllVar(dll), dll+".dll")
+	}
+	b.WriteString("\n")
+	for _, sym := range sortedKeys(procs) {
+		fmt.Fprintf(&b, "\tproc%s = %s.NewProc(%q)\n", sym, dllVar(procs[sym]), sym)
+	}
+	b.WriteString(")\n")
+	b.Write(body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not parse: %v\n%s", err, b.Bytes())
+	}
+	return src, nil
+}
+
+func (g *Generator) writeFunc(b *bytes.Buffer, fn *Fn) {
+	b.WriteString("\n")
+	for _, d := range fn.Doc {
+		b.WriteString(d + "\n")
+	}
+	var params, rets []string
+	for _, p := range fn.Params {
+		typ := p.Type
+		if typ == "optstring" {
+			typ = "string"
+		}
+		params = append(params, p.Name+" "+typ)
+	}
+	for _, r := range []*Param{fn.Ret, fn.Err} {
+		if r != nil {
+			rets = append(rets, r.Name+" "+r.Type)
+		}
+	}
+	fmt.Fprintf(b, "func %s(%s) ", fn.Name, strings.Join(params, ", "))
+	if len(rets) > 0 {
+		fmt.Fprintf(b, "(%s) ", strings.Join(rets, ", "))
+	}
+	b.WriteString("{\n")
+
+	// arguments, split in the words passed on 64 and on 32 bit platforms
+	var args64, args32 []string
+	arg := func(both ...string) {
+		args64 = append(args64, both...)
+		args32 = append(args32, both...)
+	}
+	utf16 := !strings.HasSuffix(fn.Symbol, "A")
+	for i, p := range fn.Params {
+		tmp := fmt.Sprintf("_p%d", i)
+		switch {
+		case p.Type == "string" || p.Type == "optstring":
+			typ, conv := "*uint16", "proc.UTF16PtrFromString"
+			if !utf16 {
+				typ, conv = "*byte", "proc.BytePtrFromString"
+			}
+			convert := fmt.Sprintf("%s, %s = %s(%s)\nif %s != nil {\nreturn\n}\n", tmp, fn.Err.Name, conv, p.Name, fn.Err.Name)
+			if p.Type == "optstring" {
+				convert = fmt.Sprintf("if %s != \"\" {\n%s}\n", p.Name, convert)
+			}
+			fmt.Fprintf(b, "\tvar %s %s\n%s", tmp, typ, convert)
+			arg(g.pointer(tmp))
+		case p.Type == "bool":
+			fmt.Fprintf(b, "\tvar %s uint32\n\tif %s {\n\t\t%s = 1\n\t}\n", tmp, p.Name, tmp)
+			arg("uintptr(" + tmp + ")")
+		case strings.HasPrefix(p.Type, "[]"):
+			fmt.Fprintf(b, "\tvar %s *%s\n\tif len(%s) > 0 {\n\t\t%s = &%s[0]\n\t}\n", tmp, p.Type[2:], p.Name, tmp, p.Name)
+			arg(g.pointer(tmp), "uintptr(len("+p.Name+"))")
+		case strings.HasPrefix(p.Type, "*"):
+			arg(g.pointer(p.Name))
+		case p.Type == "uintptr" || p.Type == "unsafe.Pointer":
+			if p.Type == "unsafe.Pointer" {
+				g.unsafe = true
+			}
+			arg("uintptr(" + p.Name + ")")
+		case wide64[p.Type]:
+			args64 = append(args64, "uintptr("+p.Name+")")
+			args32 = append(args32, "uintptr("+p.Name+")", "uintptr("+p.Name+">>32)")
+		default:
+			arg("uintptr(" + p.Name + ")")
+		}
+	}
+
+	r1, r2, lastErr := "_", "_", "_"
+	if fn.Ret != nil || fn.Err != nil {
+		r1 = "r1"
+	}
+	if fn.Ret != nil && wide64[fn.Ret.Type] {
+		r2 = "r2"
+	}
+	if fn.Err != nil && fn.Err.Name == "err" {
+		lastErr = "e1"
+	}
+	lhs := r1 + ", " + r2 + ", " + lastErr
+	call64 := fmt.Sprintf("proc%s.Call(%s)", fn.Symbol, strings.Join(args64, ", "))
+	call32 := fmt.Sprintf("proc%s.Call(%s)", fn.Symbol, strings.Join(args32, ", "))
+	if call64 == call32 {
+		if lhs == "_, _, _" {
+			fmt.Fprintf(b, "\t%s\n", call64)
+		} else {
+			fmt.Fprintf(b, "\t%s := %s\n", lhs, call64)
+		}
+	} else {
+		g.unsafe = true
+		for _, v := range []struct{ name, typ string }{{r1, "uintptr"}, {r2, "uintptr"}, {lastErr, "error"}} {
+			if v.name != "_" {
+				fmt.Fprintf(b, "\tvar %s %s\n", v.name, v.typ)
+			}
+		}
+		assign := " = "
+		if lhs == "_, _, _" {
+			lhs, assign = "", ""
+		}
+		fmt.Fprintf(b, "\tif unsafe.Sizeof(uintptr(0)) == 4 {\n\t\t%s%s%s\n\t} else {\n\t\t%s%s%s\n\t}\n",
+			lhs, assign, call32, lhs, assign, call64)
+	}
+
+	if r := fn.Ret; r != nil {
+		switch {
+		case r.Type == "bool":
+			fmt.Fprintf(b, "\t%s = r1 != 0\n", r.Name)
+		case wide64[r.Type]:
+			g.unsafe = true
+			wide := "uint64(r1) | uint64(r2)<<32"
+			if r.Type != "uint64" {
+				wide = r.Type + "(" + wide + ")"
+			}
+			fmt.Fprintf(b, "\tif unsafe.Sizeof(uintptr(0)) == 4 {\n\t\t%s = %s\n\t} else {\n\t\t%s = %s(r1)\n\t}\n",
+				r.Name, wide, r.Name, r.Type)
+		default:
+			fmt.Fprintf(b, "\t%s = %s(r1)\n", r.Name, r.Type)
+		}
+	}
+	if e := fn.Err; e != nil {
+		if e.Name != "err" {
+			g.syscall = true
+			fmt.Fprintf(b, "\tif r1 != 0 {\n\t\t%s = proc.LastError(%q, syscall.Errno(r1))\n\t}\n", e.Name, fn.Symbol)
+		} else {
+			fmt.Fprintf(b, "\tif %s {\n\t\terr = proc.LastError(%q, e1)\n\t}\n", fn.failure(), fn.Symbol)
+		}
+	}
+	if fn.Ret != nil || fn.Err != nil {
+		b.WriteString("\treturn\n")
+	}
+	b.WriteString("}\n")
+}
+
+// failure returns the condition under which the call failed.
+func (fn *Fn) failure() string {
+	switch {
+	case fn.Ret == nil:
+		if fn.FailCond != "" {
+			return "r1" + fn.FailCond
+		}
+		return "r1 == 0"
+	case fn.FailCond != "":
+		return fn.Ret.Name + " " + fn.FailCond
+	case fn.Ret.Type == "bool":
+		return "!" + fn.Ret.Name
+	default:
+		return fn.Ret.Name + " == 0"
+	}
+}
+
+func (g *Generator) pointer(name string) string {
+	g.unsafe = true
+	return "uintptr(unsafe.Pointer(" + name + "))"
+}
+
+// dllVar returns the variable of a dll, dllUser32 for user32.
+func dllVar(dll string) string {
+	return "dll" + strings.ToUpper(dll[:1]) + dll[1:]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/zsys.golden")
+
+func generate(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var g Generator
+	for _, file := range files {
+		if err := g.ParseFile(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src, err := g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestGolden(t *testing.T) {
+	got := generate(t, "testdata/sys.go")
+	golden := filepath.Join("testdata", "zsys.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, run go test -update if the change is expected:\n%s", golden, got)
+	}
+}
+
+// TestZsys checks that the zsys.go of the module was regenerated.
+func TestZsys(t *testing.T) {
+	got := generate(t, "../../sys.go")
+	want, err := os.ReadFile("../../zsys.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("zsys.go is out of date, run go generate in the module root")
+	}
+}
+
+func TestParseSys(t *testing.T) {
+	tests := []struct {
+		decl string
+		want Fn
+	}{
+		{"GetTickCount() (ms uint32)", Fn{Name: "GetTickCount", Ret: &Param{"ms", "uint32"}, DLL: "kernel32", Symbol: "GetTickCount"}},
+		{"Sleep(ms uint32)", Fn{Name: "Sleep", Params: []Param{{"ms", "uint32"}}, DLL: "kernel32", Symbol: "Sleep"}},
+		{"IsWindow(hwnd HWND) (ok bool) = user32.IsWindow", Fn{Name: "IsWindow", Params: []Param{{"hwnd", "HWND"}}, Ret: &Param{"ok", "bool"}, DLL: "user32", Symbol: "IsWindow"}},
+		{"GetModuleHandle(name string) (h HANDLE, err error) = GetModuleHandleW", Fn{
+			Name: "GetModuleHandle", Params: []Param{{"name", "string"}},
+			Ret: &Param{"h", "HANDLE"}, Err: &Param{"err", "error"}, DLL: "kernel32", Symbol: "GetModuleHandleW",
+		}},
+		{"MoveWindow(hwnd HWND, x, y, w, h int32, repaint bool) (err error) = user32.MoveWindow", Fn{
+			Name:   "MoveWindow",
+			Params: []Param{{"hwnd", "HWND"}, {"x", "int32"}, {"y", "int32"}, {"w", "int32"}, {"h", "int32"}, {"repaint", "bool"}},
+			Err:    &Param{"err", "error"}, DLL: "user32", Symbol: "MoveWindow",
+		}},
+		{"GetMenuItemCount(menu HMENU) (n int32, err error) [failretval==-1] = user32.GetMenuItemCount", Fn{
+			Name: "GetMenuItemCount", Params: []Param{{"menu", "HMENU"}},
+			Ret: &Param{"n", "int32"}, Err: &Param{"err", "error"}, FailCond: "==-1", DLL: "user32", Symbol: "GetMenuItemCount",
+		}},
+		{"RegCloseKey(key HKEY) (regerrno error) = advapi32.RegCloseKey", Fn{
+			Name: "RegCloseKey", Params: []Param{{"key", "HKEY"}}, Err: &Param{"regerrno", "error"}, DLL: "advapi32", Symbol: "RegCloseKey",
+		}},
+	}
+	for _, tt := range tests {
+		fn, err := parseSys(tt.decl)
+		if err != nil {
+			t.Errorf("parseSys(%q): %v", tt.decl, err)
+			continue
+		}
+		if !reflect.DeepEqual(*fn, tt.want) {
+			t.Errorf("parseSys(%q) = %+v, want %+v", tt.decl, *fn, tt.want)
+		}
+	}
+}
+
+func TestParseSysErrors(t *testing.T) {
+	tests := []struct {
+		decl, err string
+	}{
+		{"GetTickCount", "malformed"},
+		{"Foo(a int) (b int) = user32.", "malformed"},
+		{"Foo(a int, b) (err error)", "has no type"},
+		{"Foo(int32) (err error)", "has no type"},
+		{"Foo(a-b int32) (err error)", "must be named"},
+		{"Foo(f float64) (err error)", "floating point"},
+		{"Foo() (p *byte)", "not supported"},
+		{"Foo() (s string, err error)", "not supported"},
+		{"Foo() (a int32, b int32)", "results must be"},
+		{"Foo() (err error, n int32)", "results must be"},
+		{"Foo(s string)", "need an err error result"},
+		{"Foo(s string) (errno error)", "need an err error result"},
+		{"Foo(s optstring) (n int32)", "need an err error result"},
+		{"Foo() (n int32) [failretval==-1]", "failretval without"},
+		{"Foo() (n int32, errno error)", "returned error code"},
+	}
+	for _, tt := range tests {
+		if _, err := parseSys(tt.decl); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseSys(%q) error = %v, want %q", tt.decl, err, tt.err)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	var g Generator
+	if err := g.ParseFile("testdata/sys.go"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Package != "sample" || len(g.Funcs) != 14 {
+		t.Fatalf("package %q, %d functions", g.Package, len(g.Funcs))
+	}
+	if fn := g.Funcs[0]; fn.Name != "Sleep" || !reflect.DeepEqual(fn.Doc, []string{"// Sleep has no results."}) || fn.Pos != "testdata/sys.go:4" {
+		t.Errorf("Funcs[0] = %+v", fn)
+	}
+	if fn := g.Funcs[1]; fn.Doc != nil {
+		t.Errorf("%s has doc %q", fn.Name, fn.Doc)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		src, err string
+	}{
+		{"//sys\tSleep(ms uint32)\n", "no package clause"},
+		{"package p\n", "no //sys declarations"},
+		{"package p\n//sys\tSleep(ms uint32)\n//sys\tSleep(ms uint32) = SleepEx\n", "already declared at"},
+	}
+	for i, tt := range tests {
+		file := filepath.Join(dir, "sys.go")
+		if err := os.WriteFile(file, []byte(tt.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var g Generator
+		err := g.ParseFile(file)
+		if err == nil {
+			_, err = g.Generate()
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%d: error = %v, want %q", i, err, tt.err)
+		}
+	}
+
+	var g Generator
+	for _, src := range []string{"package a\n//sys\tSleep(ms uint32)\n", "package b\n"} {
+		file := filepath.Join(dir, "sys.go")
+		os.WriteFile(file, []byte(src), 0o644)
+		if err := g.ParseFile(file); err != nil {
+			if !strings.Contains(err.Error(), "package b, expected a") {
+				t.Errorf("ParseFile() error = %v", err)
+			}
+			return
+		}
+	}
+	t.Error("ParseFile() accepted two packages")
+}
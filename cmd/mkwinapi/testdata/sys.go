@@ -0,0 +1,17 @@
+package sample
+
+// Sleep has no results.
+//sys	Sleep(ms uint32) = kernel32.Sleep
+//sys	GetTickCount64() (ms uint64)
+//sys	IsWindow(hwnd HWND) (ok bool) = user32.IsWindow
+//sys	EnableWindow(hwnd HWND, enable bool) (wasDisabled bool) = user32.EnableWindow
+//sys	SetWindowText(hwnd HWND, text string) (err error) = user32.SetWindowTextW
+//sys	GetModuleHandle(name string) (module HANDLE, err error) = GetModuleHandleA
+//sys	FindWindow(class optstring, title optstring) (hwnd HWND, err error) = user32.FindWindowW
+//sys	GetMenuItemCount(menu HMENU) (n int32, err error) [failretval==-1] = user32.GetMenuItemCount
+//sys	SetFilePointerEx(file HANDLE, distance int64, newPos *int64, method uint32) (err error)
+//sys	ReadFile(file HANDLE, buf []byte, done *uint32, overlapped unsafe.Pointer) (err error)
+//sys	GetFileSizeEx(file HANDLE, size *int64) (ok bool, err error)
+//sys	VerSetConditionMask(mask ULONGLONG, typeMask uint32, cond uint8) (ret ULONGLONG)
+//sys	RegCloseKey(key HKEY) (regerrno error) = advapi32.RegCloseKey
+//sys	WaitForSingleObject(h HANDLE, ms uint32) (event uint32, err error) [failretval==0xffffffff]
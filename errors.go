@@ -0,0 +1,99 @@
+package winapi
+
+import (
+	"syscall"
+
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/FxStar/winapi/internal/winerr"
+)
+
+// ErrNotSupported is returned by the stubs built on other platforms than
+// Windows.
+var ErrNotSupported = proc.ErrNotSupported
+
+// Error is a failed call: the API, the errno it set and its message. The
+// generated wrappers return it, errors.Is matches the errno:
+//
+//	_, err := winapi.GetMenuItemCount(menu)
+//	if errors.Is(err, winapi.ERROR_INVALID_MENU_HANDLE) {
+//	}
+//	var e *winapi.Error
+//	if errors.As(err, &e) {
+//		log.Println(e.API, uint32(e.Errno))
+//	}
+type Error = winerr.Error
+
+// ErrorText returns the message of errno, from a table embedded for the
+// common codes so that it is the same on every platform.
+func ErrorText(errno syscall.Errno) string {
+	return winerr.Text(errno)
+}
+
+// System error codes.
+const (
+	ERROR_SUCCESS                   = winerr.ERROR_SUCCESS
+	ERROR_INVALID_FUNCTION          = winerr.ERROR_INVALID_FUNCTION
+	ERROR_FILE_NOT_FOUND            = winerr.ERROR_FILE_NOT_FOUND
+	ERROR_PATH_NOT_FOUND            = winerr.ERROR_PATH_NOT_FOUND
+	ERROR_ACCESS_DENIED             = winerr.ERROR_ACCESS_DENIED
+	ERROR_INVALID_HANDLE            = winerr.ERROR_INVALID_HANDLE
+	ERROR_NOT_ENOUGH_MEMORY         = winerr.ERROR_NOT_ENOUGH_MEMORY
+	ERROR_INVALID_ACCESS            = winerr.ERROR_INVALID_ACCESS
+	ERROR_INVALID_DATA              = winerr.ERROR_INVALID_DATA
+	ERROR_OUTOFMEMORY               = winerr.ERROR_OUTOFMEMORY
+	ERROR_NOT_READY                 = winerr.ERROR_NOT_READY
+	ERROR_OUT_OF_PAPER              = winerr.ERROR_OUT_OF_PAPER
+	ERROR_GEN_FAILURE               = winerr.ERROR_GEN_FAILURE
+	ERROR_SHARING_VIOLATION         = winerr.ERROR_SHARING_VIOLATION
+	ERROR_HANDLE_EOF                = winerr.ERROR_HANDLE_EOF
+	ERROR_NOT_SUPPORTED             = winerr.ERROR_NOT_SUPPORTED
+	ERROR_DEV_NOT_EXIST             = winerr.ERROR_DEV_NOT_EXIST
+	ERROR_PRINTQ_FULL               = winerr.ERROR_PRINTQ_FULL
+	ERROR_PRINT_CANCELLED           = winerr.ERROR_PRINT_CANCELLED
+	ERROR_INVALID_PARAMETER         = winerr.ERROR_INVALID_PARAMETER
+	ERROR_BROKEN_PIPE               = winerr.ERROR_BROKEN_PIPE
+	ERROR_CALL_NOT_IMPLEMENTED      = winerr.ERROR_CALL_NOT_IMPLEMENTED
+	ERROR_INSUFFICIENT_BUFFER       = winerr.ERROR_INSUFFICIENT_BUFFER
+	ERROR_INVALID_NAME              = winerr.ERROR_INVALID_NAME
+	ERROR_MOD_NOT_FOUND             = winerr.ERROR_MOD_NOT_FOUND
+	ERROR_PROC_NOT_FOUND            = winerr.ERROR_PROC_NOT_FOUND
+	ERROR_BUSY                      = winerr.ERROR_BUSY
+	ERROR_ALREADY_EXISTS            = winerr.ERROR_ALREADY_EXISTS
+	ERROR_MORE_DATA                 = winerr.ERROR_MORE_DATA
+	WAIT_TIMEOUT                    = winerr.WAIT_TIMEOUT
+	ERROR_NO_MORE_ITEMS             = winerr.ERROR_NO_MORE_ITEMS
+	ERROR_OPERATION_ABORTED         = winerr.ERROR_OPERATION_ABORTED
+	ERROR_IO_INCOMPLETE             = winerr.ERROR_IO_INCOMPLETE
+	ERROR_IO_PENDING                = winerr.ERROR_IO_PENDING
+	ERROR_NOACCESS                  = winerr.ERROR_NOACCESS
+	ERROR_INVALID_FLAGS             = winerr.ERROR_INVALID_FLAGS
+	ERROR_NO_UNICODE_TRANSLATION    = winerr.ERROR_NO_UNICODE_TRANSLATION
+	ERROR_DEVICE_NOT_CONNECTED      = winerr.ERROR_DEVICE_NOT_CONNECTED
+	ERROR_NOT_FOUND                 = winerr.ERROR_NOT_FOUND
+	ERROR_CANCELLED                 = winerr.ERROR_CANCELLED
+	ERROR_INVALID_WINDOW_HANDLE     = winerr.ERROR_INVALID_WINDOW_HANDLE
+	ERROR_INVALID_MENU_HANDLE       = winerr.ERROR_INVALID_MENU_HANDLE
+	ERROR_INVALID_CURSOR_HANDLE     = winerr.ERROR_INVALID_CURSOR_HANDLE
+	ERROR_INVALID_HOOK_HANDLE       = winerr.ERROR_INVALID_HOOK_HANDLE
+	ERROR_HOTKEY_ALREADY_REGISTERED = winerr.ERROR_HOTKEY_ALREADY_REGISTERED
+	ERROR_CLASS_ALREADY_EXISTS      = winerr.ERROR_CLASS_ALREADY_EXISTS
+	ERROR_CLASS_DOES_NOT_EXIST      = winerr.ERROR_CLASS_DOES_NOT_EXIST
+	ERROR_CLASS_HAS_WINDOWS         = winerr.ERROR_CLASS_HAS_WINDOWS
+	ERROR_INVALID_INDEX             = winerr.ERROR_INVALID_INDEX
+	ERROR_HOTKEY_NOT_REGISTERED     = winerr.ERROR_HOTKEY_NOT_REGISTERED
+	ERROR_HOOK_NEEDS_HMOD           = winerr.ERROR_HOOK_NEEDS_HMOD
+	ERROR_INVALID_THREAD_ID         = winerr.ERROR_INVALID_THREAD_ID
+	ERROR_NO_SYSTEM_RESOURCES       = winerr.ERROR_NO_SYSTEM_RESOURCES
+	ERROR_TIMEOUT                   = winerr.ERROR_TIMEOUT
+	ERROR_UNKNOWN_PRINTER_DRIVER    = winerr.ERROR_UNKNOWN_PRINTER_DRIVER
+	ERROR_UNKNOWN_PRINTPROCESSOR    = winerr.ERROR_UNKNOWN_PRINTPROCESSOR
+	ERROR_INVALID_PRINTER_NAME      = winerr.ERROR_INVALID_PRINTER_NAME
+	ERROR_PRINTER_ALREADY_EXISTS    = winerr.ERROR_PRINTER_ALREADY_EXISTS
+	ERROR_INVALID_PRINTER_COMMAND   = winerr.ERROR_INVALID_PRINTER_COMMAND
+	ERROR_INVALID_DATATYPE          = winerr.ERROR_INVALID_DATATYPE
+	ERROR_INVALID_ENVIRONMENT       = winerr.ERROR_INVALID_ENVIRONMENT
+	ERROR_PRINTER_DELETED           = winerr.ERROR_PRINTER_DELETED
+	ERROR_INVALID_PRINTER_STATE     = winerr.ERROR_INVALID_PRINTER_STATE
+	ERROR_PRINTER_DRIVER_IN_USE     = winerr.ERROR_PRINTER_DRIVER_IN_USE
+	ERROR_PRINTER_NOT_FOUND         = winerr.ERROR_PRINTER_NOT_FOUND
+)
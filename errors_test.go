@@ -0,0 +1,39 @@
+package winapi
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+func TestErrorSentinels(t *testing.T) {
+	tests := []struct {
+		errno syscall.Errno
+		text  string
+	}{
+		{ERROR_ACCESS_DENIED, "Access is denied"},
+		{ERROR_INSUFFICIENT_BUFFER, "The data area passed to a system call is too small"},
+		{ERROR_INVALID_PRINTER_NAME, "The printer name is invalid"},
+		{ERROR_PRINTER_NOT_FOUND, "No printers were found"},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("open printer: %w", &Error{API: "OpenPrinterW", Errno: tt.errno})
+		if !errors.Is(err, tt.errno) {
+			t.Errorf("errors.Is(%v, %d) = false", err, tt.errno)
+		}
+		var e *Error
+		if !errors.As(err, &e) || e.Errno != tt.errno {
+			t.Errorf("errors.As(%v) = %+v", err, e)
+		}
+		if want := "open printer: OpenPrinterW: " + tt.text; err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err, want)
+		}
+		if s := ErrorText(tt.errno); s != tt.text {
+			t.Errorf("ErrorText(%d) = %q, want %q", tt.errno, s, tt.text)
+		}
+	}
+	if errors.Is(fmt.Errorf("w: %w", &Error{Errno: ERROR_ACCESS_DENIED}), ERROR_PRINTER_NOT_FOUND) {
+		t.Error("errors.Is matched another errno")
+	}
+}
@@ -1,3 +1,6 @@
+//go:build windows
+// +build windows
+
 package main
 
 import (
@@ -107,7 +110,10 @@ func main() {
 	verbPrint("<= LoadBitmapFromMemory: 0x%x", img)
 	log.Printf("create bmp image success, width:%d, height:%d", imgWidth, imgHeight)
 	defer gdi.DeleteObject(img) //When you are finished using a bitmap you loaded without specifying the LR_SHARED flag, you can release its associated memory by calling DeleteObject.
-	obj := gdi.SelectObject(hdcMem, img)
+	obj, err := gdi.SelectObject(hdcMem, img)
+	if err != nil {
+		Fatal("SelectObject failed: %v", err)
+	}
 	verbPrint("SelectObject: 0x%x", obj)
 
 	verbPrint("=> OpenPrinter")
@@ -134,9 +140,9 @@ func main() {
 		verbPrint("<= StartPage")
 		// Alternative: use StretchBlt to scale
 		verbPrint("=> BitBlt")
-		res := gdi.BitBlt(prn, 0, 0, imgWidth, imgHeight,
+		err = gdi.BitBlt(prn, 0, 0, imgWidth, imgHeight,
 			hdcMem, 0, 0, gdi.SRCCOPY)
-		verbPrint("<= BitBlt result: %v", res)
+		verbPrint("<= BitBlt: %v", err)
 		verbPrint("=> EndPage")
 		err = prn.EndPage()
 		verbPrint("<= EndPage: %v", err)
@@ -151,7 +157,7 @@ func main() {
 	log.Printf("====== end doc ======")
 	if *shouldCheck {
 		if err := hPrinter.SetJobCommand(jobId, winspool.JOB_CONTROL_RETAIN); err != nil {
-			Fatal("retain job failed:", err)
+			Fatal("retain job failed: %v", err)
 		}
 		log.Printf(">>> job retained")
 		start := time.Now()
@@ -207,7 +213,7 @@ func checkJob(hPrinter winspool.HANDLE, jobId int32) error {
 	log.Printf("  printed: %d/%d, status: %v", ji.GetPagesPrinted(), ji.GetTotalPages(), curstatus)
 	if complete {
 		if err := hPrinter.SetJobCommand(jobId, winspool.JOB_CONTROL_RELEASE); err != nil {
-			Fatal("release job failed:", err)
+			Fatal("release job failed: %v", err)
 		}
 		Fatal("detect complete")
 	}
@@ -9,6 +9,7 @@ import (
 	"golang.org/x/image/bmp"
 
 	. "github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/internal/winerr"
 )
 
 var (
@@ -27,11 +28,6 @@ var (
 	procStretchBlt             = modgdi32.NewProc("StretchBlt")
 )
 
-type DIBBITMAPINFO struct {
-	bfOffBits uint32
-	BITMAPINFO
-}
-
 func CreateCompatibleDC(hwnd HWND) (hdc HDC) {
 	r0, _, _ := syscall.Syscall(procCreateCompatibleDC.Addr(), 1, uintptr(hwnd), 0, 0)
 	hdc = HDC(r0)
@@ -68,9 +64,13 @@ func GetObjectW(hgdiobj HANDLE, cbBuffer uintptr, object uintptr) (size uint32)
 	return size
 }
 
-func SelectObject(hdc HDC, hgdiobj HANDLE) HDC {
-	r0, _, _ := syscall.Syscall(procSelectObject.Addr(), 2, uintptr(hdc), uintptr(hgdiobj), 0)
-	return HDC(r0)
+// SelectObject returns the object hgdiobj replaces in hdc.
+func SelectObject(hdc HDC, hgdiobj HANDLE) (HANDLE, error) {
+	r0, _, err := procSelectObject.Call(uintptr(hdc), uintptr(hgdiobj))
+	if r0 == 0 || r0 == HGDI_ERROR {
+		return 0, winerr.New("SelectObject", err)
+	}
+	return HANDLE(r0), nil
 }
 
 func DeleteObject(hgdiobj HANDLE) HANDLE {
@@ -86,7 +86,7 @@ func CreateCompatibleBitmap(hdc HDC, width, height uintptr) (hbitmap HANDLE) {
 func CreateDIBSection(hdc HDC, pbmi *BITMAPINFO, iUsage uint, ppvBits uintptr, hSection uint32, dwOffset uint32) (HANDLE, error) {
 	r0, _, err := syscall.Syscall6(procCreateDIBSection.Addr(), 6, uintptr(hdc), uintptr(unsafe.Pointer(pbmi)), uintptr(iUsage), ppvBits, uintptr(hSection), uintptr(dwOffset))
 	if r0 == 0 {
-		return 0, err
+		return 0, winerr.New("CreateDIBSection", err)
 	}
 	return HANDLE(r0), nil
 }
@@ -94,14 +94,17 @@ func CreateDIBSection(hdc HDC, pbmi *BITMAPINFO, iUsage uint, ppvBits uintptr, h
 func SetDIBits(hdc HDC, hbm HBITMAP, start, cLines int32, pixels []byte, pbmi *BITMAPINFO, colorUse uint) error {
 	r0, _, err := procSetDIBits.Call(uintptr(hdc), uintptr(hbm), uintptr(start), uintptr(cLines), uintptr(unsafe.Pointer(&pixels[0])), uintptr(unsafe.Pointer(pbmi)), uintptr(colorUse))
 	if r0 == 0 {
-		return err
+		return winerr.New("SetDIBits", err)
 	}
 	return nil
 }
 
-func BitBlt(hdc HDC, nXDest, nYDest, nWidth, nHeight int, hdcSrc HDC, nXSrc, nYSrc int, dwRop uint32) bool {
-	r0, _, _ := syscall.Syscall9(procBitBlt.Addr(), 9, uintptr(hdc), uintptr(nXDest), uintptr(nYDest), uintptr(nWidth), uintptr(nHeight), uintptr(hdcSrc), uintptr(nXSrc), uintptr(nYSrc), uintptr(dwRop))
-	return r0 != 0
+func BitBlt(hdc HDC, nXDest, nYDest, nWidth, nHeight int, hdcSrc HDC, nXSrc, nYSrc int, dwRop uint32) error {
+	r0, _, err := procBitBlt.Call(uintptr(hdc), uintptr(nXDest), uintptr(nYDest), uintptr(nWidth), uintptr(nHeight), uintptr(hdcSrc), uintptr(nXSrc), uintptr(nYSrc), uintptr(dwRop))
+	if r0 == 0 {
+		return winerr.New("BitBlt", err)
+	}
+	return nil
 }
 
 func StretchBlt(hdc HDC, nXDest, nYDest, nWidth, nHeight int, hdcSrc HDC, nXSrc, nYSrc, wSrc, hSrc int, dwRop uint32) bool {
@@ -4,6 +4,7 @@ import (
 	"unsafe"
 
 	. "github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/wstr"
 )
 
 const (
@@ -30,6 +31,9 @@ const (
 	WHITENESS   = 0xFF0062
 )
 
+// HGDI_ERROR is returned by SelectObject for a region it can't select.
+const HGDI_ERROR = ^uintptr(0)
+
 type BITMAP struct {
 	Type       int32
 	Width      int32
@@ -59,6 +63,11 @@ type BITMAPINFO struct {
 	Colors [1]RGBQUAD
 }
 
+type DIBBITMAPINFO struct {
+	bfOffBits uint32
+	BITMAPINFO
+}
+
 type RGBQUAD struct {
 	Blue     byte
 	Green    byte
@@ -125,3 +134,12 @@ type LOGFONTW struct {
 }
 
 var LOGFONTW_SIZE = UINT(unsafe.Sizeof(LOGFONTW{}))
+
+func (lf *LOGFONTW) FaceName() string {
+	return wstr.FromArray((*[LF_FACESIZE]uint16)(unsafe.Pointer(&lf.LfFaceName))[:])
+}
+
+// SetFaceName sets LfFaceName, truncated to the LF_FACESIZE-1 units it holds.
+func (lf *LOGFONTW) SetFaceName(name string) {
+	wstr.Copy((*[LF_FACESIZE]uint16)(unsafe.Pointer(&lf.LfFaceName))[:], name)
+}
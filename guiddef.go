@@ -1,5 +1,12 @@
 package winapi
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type GUID struct {
 	Data1 ULONG
 	Data2 WORD
@@ -12,3 +19,37 @@ type CLSID GUID
 func NewGUID(d1 ULONG, d2, d3 WORD, d40, d41, d42, d43, d44, d45, d46, d47 BYTE) *GUID {
 	return &GUID{d1, d2, d3, [8]BYTE{d40, d41, d42, d43, d44, d45, d46, d47}}
 }
+
+var ErrInvalidGUID = errors.New("invalid GUID")
+
+// ParseGUID parses the registry form {xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx},
+// braces are optional and case is ignored.
+func ParseGUID(s string) (g GUID, err error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	parts := strings.Split(s, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return g, ErrInvalidGUID
+	}
+	d1, err1 := strconv.ParseUint(parts[0], 16, 32)
+	d2, err2 := strconv.ParseUint(parts[1], 16, 16)
+	d3, err3 := strconv.ParseUint(parts[2], 16, 16)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return g, ErrInvalidGUID
+	}
+	g.Data1, g.Data2, g.Data3 = ULONG(d1), WORD(d2), WORD(d3)
+	d4 := parts[3] + parts[4]
+	for i := range g.Data4 {
+		b, err := strconv.ParseUint(d4[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return g, ErrInvalidGUID
+		}
+		g.Data4[i] = BYTE(b)
+	}
+	return g, nil
+}
+
+// String formats the GUID in registry form, e.g. {28d78fad-5a12-11d1-ae5b-0000f803a8c2}
+func (g GUID) String() string {
+	return fmt.Sprintf("{%08x-%04x-%04x-%02x%02x-%02x%02x%02x%02x%02x%02x}", g.Data1, g.Data2, g.Data3,
+		g.Data4[0], g.Data4[1], g.Data4[2], g.Data4[3], g.Data4[4], g.Data4[5], g.Data4[6], g.Data4[7])
+}
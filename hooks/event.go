@@ -0,0 +1,158 @@
+// Package hooks runs low level keyboard and mouse hooks and delivers what
+// they see as Events on one channel.
+package hooks
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/FxStar/winapi"
+)
+
+// Source selects the hooks to install.
+type Source int
+
+const (
+	Keyboard Source = 1 << iota // WH_KEYBOARD_LL
+	Mouse                       // WH_MOUSE_LL
+
+	AllSources = Keyboard | Mouse
+)
+
+type Kind int
+
+const (
+	KeyDown Kind = iota + 1
+	KeyUp
+	MouseMove
+	ButtonDown
+	ButtonUp
+	Wheel  // vertical, WheelDelta > 0 is away from the user
+	HWheel // horizontal, WheelDelta > 0 is to the right
+)
+
+var kindNames = [...]string{
+	KeyDown:    "KeyDown",
+	KeyUp:      "KeyUp",
+	MouseMove:  "MouseMove",
+	ButtonDown: "ButtonDown",
+	ButtonUp:   "ButtonUp",
+	Wheel:      "Wheel",
+	HWheel:     "HWheel",
+}
+
+func (k Kind) String() string {
+	if k > 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Source returns the hook that delivers events of this kind.
+func (k Kind) Source() Source {
+	if k == KeyDown || k == KeyUp {
+		return Keyboard
+	}
+	return Mouse
+}
+
+type Button int
+
+const (
+	NoButton Button = iota
+	LeftButton
+	RightButton
+	MiddleButton
+	XButton1
+	XButton2
+)
+
+// Event is a decoded KBDLLHOOKSTRUCT or MSLLHOOKSTRUCT. Key fields are only
+// set for keyboard events, pointer fields for mouse events.
+type Event struct {
+	Kind    Kind
+	Message uint32    // WM_KEYDOWN, WM_MOUSEMOVE, ...
+	Time    time.Time // when the hook was called
+	Tick    uint32    // the hook struct time stamp, in GetTickCount milliseconds
+
+	VkCode   uint32
+	ScanCode uint32
+
+	X, Y       int32 // screen coordinates
+	Button     Button
+	WheelDelta int16 // multiple of WHEEL_DELTA
+
+	Flags     uint32 // LLKHF_* or LLMHF_*
+	ExtraInfo uintptr
+}
+
+// Injected reports whether the event came from SendInput or similar.
+func (e *Event) Injected() bool {
+	if e.Kind.Source() == Keyboard {
+		return e.Flags&winapi.LLKHF_INJECTED != 0
+	}
+	return e.Flags&winapi.LLMHF_INJECTED != 0
+}
+
+// KeyboardEvent decodes the arguments of a WH_KEYBOARD_LL hook, WM_SYSKEY*
+// are reported as KeyDown and KeyUp with Message kept.
+func KeyboardEvent(wParam winapi.WPARAM, k *winapi.KBDLLHOOKSTRUCT) Event {
+	ev := Event{
+		Kind:      KeyDown,
+		Message:   uint32(wParam),
+		Time:      time.Now(),
+		Tick:      k.Time,
+		VkCode:    k.VkCode,
+		ScanCode:  k.ScanCode,
+		Flags:     k.Flags,
+		ExtraInfo: k.DwExtraInfo,
+	}
+	if wParam == winapi.WM_KEYUP || wParam == winapi.WM_SYSKEYUP {
+		ev.Kind = KeyUp
+	}
+	return ev
+}
+
+// MouseEvent decodes the arguments of a WH_MOUSE_LL hook, it returns false
+// for messages it doesn't know.
+func MouseEvent(wParam winapi.WPARAM, m *winapi.MSLLHOOKSTRUCT) (Event, bool) {
+	ev := Event{
+		Message:   uint32(wParam),
+		Time:      time.Now(),
+		Tick:      m.Time,
+		X:         m.X,
+		Y:         m.Y,
+		Flags:     m.Flags,
+		ExtraInfo: m.DwExtraInfo,
+	}
+	switch wParam {
+	case winapi.WM_MOUSEMOVE:
+		ev.Kind = MouseMove
+	case winapi.WM_LBUTTONDOWN, winapi.WM_RBUTTONDOWN, winapi.WM_MBUTTONDOWN, winapi.WM_XBUTTONDOWN:
+		ev.Kind = ButtonDown
+	case winapi.WM_LBUTTONUP, winapi.WM_RBUTTONUP, winapi.WM_MBUTTONUP, winapi.WM_XBUTTONUP:
+		ev.Kind = ButtonUp
+	case winapi.WM_MOUSEWHEEL:
+		ev.Kind = Wheel
+	case winapi.WM_MOUSEHWHEEL:
+		ev.Kind = HWheel
+	default:
+		return Event{}, false
+	}
+	switch wParam {
+	case winapi.WM_LBUTTONDOWN, winapi.WM_LBUTTONUP:
+		ev.Button = LeftButton
+	case winapi.WM_RBUTTONDOWN, winapi.WM_RBUTTONUP:
+		ev.Button = RightButton
+	case winapi.WM_MBUTTONDOWN, winapi.WM_MBUTTONUP:
+		ev.Button = MiddleButton
+	case winapi.WM_XBUTTONDOWN, winapi.WM_XBUTTONUP:
+		ev.Button = XButton1
+		if m.MouseData>>16 == winapi.XBUTTON2 {
+			ev.Button = XButton2
+		}
+	case winapi.WM_MOUSEWHEEL, winapi.WM_MOUSEHWHEEL:
+		ev.WheelDelta = int16(m.MouseData >> 16)
+	}
+	return ev, true
+}
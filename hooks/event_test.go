@@ -0,0 +1,86 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+func TestKeyboardEvent(t *testing.T) {
+	tests := []struct {
+		wParam   winapi.WPARAM
+		flags    uint32
+		kind     Kind
+		injected bool
+	}{
+		{winapi.WM_KEYDOWN, 0, KeyDown, false},
+		{winapi.WM_KEYUP, winapi.LLKHF_UP, KeyUp, false},
+		{winapi.WM_SYSKEYDOWN, winapi.LLKHF_ALTDOWN, KeyDown, false},
+		{winapi.WM_SYSKEYUP, winapi.LLKHF_UP, KeyUp, false},
+		{winapi.WM_KEYDOWN, winapi.LLKHF_INJECTED, KeyDown, true},
+		{winapi.WM_KEYUP, winapi.LLKHF_UP | winapi.LLKHF_INJECTED, KeyUp, true},
+	}
+	for _, tt := range tests {
+		k := winapi.KBDLLHOOKSTRUCT{VkCode: 'A', ScanCode: 0x1e, Flags: tt.flags, Time: 1234, DwExtraInfo: 7}
+		ev := KeyboardEvent(tt.wParam, &k)
+		if ev.Kind != tt.kind || ev.Injected() != tt.injected {
+			t.Errorf("KeyboardEvent(%s, %#x) = %v, injected %v, want %v, %v", winapi.MessageName(uint32(tt.wParam)), tt.flags, ev.Kind, ev.Injected(), tt.kind, tt.injected)
+		}
+		if ev.Message != uint32(tt.wParam) || ev.VkCode != 'A' || ev.ScanCode != 0x1e || ev.Tick != 1234 || ev.ExtraInfo != 7 || ev.Kind.Source() != Keyboard {
+			t.Errorf("KeyboardEvent(%s) = %+v", winapi.MessageName(uint32(tt.wParam)), ev)
+		}
+	}
+}
+
+func TestMouseEvent(t *testing.T) {
+	negative := int16(-2 * winapi.WHEEL_DELTA)
+	tests := []struct {
+		wParam    winapi.WPARAM
+		mouseData uint32
+		flags     uint32
+		kind      Kind
+		button    Button
+		delta     int16
+		injected  bool
+	}{
+		{winapi.WM_MOUSEMOVE, 0, 0, MouseMove, NoButton, 0, false},
+		{winapi.WM_LBUTTONDOWN, 0, 0, ButtonDown, LeftButton, 0, false},
+		{winapi.WM_RBUTTONUP, 0, 0, ButtonUp, RightButton, 0, false},
+		{winapi.WM_MBUTTONDOWN, 0, 0, ButtonDown, MiddleButton, 0, false},
+		{winapi.WM_XBUTTONDOWN, winapi.XBUTTON1 << 16, 0, ButtonDown, XButton1, 0, false},
+		{winapi.WM_XBUTTONUP, winapi.XBUTTON2 << 16, 0, ButtonUp, XButton2, 0, false},
+		{winapi.WM_MOUSEWHEEL, winapi.WHEEL_DELTA << 16, 0, Wheel, NoButton, winapi.WHEEL_DELTA, false},
+		{winapi.WM_MOUSEWHEEL, uint32(uint16(negative)) << 16, 0, Wheel, NoButton, negative, false},
+		{winapi.WM_MOUSEHWHEEL, winapi.WHEEL_DELTA << 16, 0, HWheel, NoButton, winapi.WHEEL_DELTA, false},
+		{winapi.WM_MOUSEHWHEEL, uint32(uint16(negative)) << 16, 0, HWheel, NoButton, negative, false},
+		{winapi.WM_LBUTTONDOWN, 0, winapi.LLMHF_INJECTED, ButtonDown, LeftButton, 0, true},
+	}
+	for _, tt := range tests {
+		m := winapi.MSLLHOOKSTRUCT{X: -10, Y: 20, MouseData: tt.mouseData, Flags: tt.flags, Time: 99}
+		name := winapi.MessageName(uint32(tt.wParam))
+		ev, ok := MouseEvent(tt.wParam, &m)
+		if !ok {
+			t.Errorf("MouseEvent(%s) not decoded", name)
+			continue
+		}
+		if ev.Kind != tt.kind || ev.Button != tt.button || ev.WheelDelta != tt.delta || ev.Injected() != tt.injected {
+			t.Errorf("MouseEvent(%s, %#x) = %v %v delta %d injected %v, want %v %v delta %d injected %v",
+				name, tt.mouseData, ev.Kind, ev.Button, ev.WheelDelta, ev.Injected(), tt.kind, tt.button, tt.delta, tt.injected)
+		}
+		if ev.X != -10 || ev.Y != 20 || ev.Tick != 99 || ev.Kind.Source() != Mouse {
+			t.Errorf("MouseEvent(%s) = %+v", name, ev)
+		}
+	}
+	if _, ok := MouseEvent(winapi.WM_LBUTTONDBLCLK, &winapi.MSLLHOOKSTRUCT{}); ok {
+		t.Error("MouseEvent(WM_LBUTTONDBLCLK) decoded")
+	}
+}
+
+func TestKindString(t *testing.T) {
+	if s := HWheel.String(); s != "HWheel" {
+		t.Errorf("HWheel.String() = %q", s)
+	}
+	if s := Kind(0).String(); s != "Kind(0)" {
+		t.Errorf("Kind(0).String() = %q", s)
+	}
+}
@@ -0,0 +1,8 @@
+package hooks
+
+import "github.com/pkg/errors"
+
+var (
+	ErrRunning       = errors.New("hook already running")
+	ErrEventsDropped = errors.New("hook events dropped, channel full")
+)
@@ -0,0 +1,61 @@
+//go:build !windows
+// +build !windows
+
+package hooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/FxStar/winapi"
+)
+
+// Hook needs low level hooks, on other platforms Start fails with
+// winapi.ErrNotSupported.
+type Hook struct {
+	Sources Source
+	Filter  func(*Event) bool
+
+	events chan Event
+	errs   chan error
+}
+
+func New(sources Source) *Hook {
+	return &Hook{
+		Sources: sources,
+		events:  make(chan Event),
+		errs:    make(chan error),
+	}
+}
+
+func (h *Hook) Events() <-chan Event {
+	return h.events
+}
+
+func (h *Hook) Errors() <-chan error {
+	return h.errs
+}
+
+func (h *Hook) LastActivity() time.Time {
+	return time.Time{}
+}
+
+func (h *Hook) Idle() time.Duration {
+	return 0
+}
+
+func (h *Hook) Start(ctx context.Context) error {
+	return winapi.ErrNotSupported
+}
+
+func (h *Hook) Stop() error {
+	return nil
+}
+
+func (h *Hook) Done() <-chan struct{} {
+	return nil
+}
+
+func (h *Hook) Err() error {
+	return nil
+}
@@ -0,0 +1,263 @@
+package hooks
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/pkg/errors"
+)
+
+var (
+	hookProcOnce sync.Once
+	keyboardProc uintptr
+	mouseProc    uintptr
+	running      sync.Map // hook thread id -> *Hook
+)
+
+// Hook runs low level hooks on its own locked OS thread:
+//
+//	h := hooks.New(hooks.Keyboard | hooks.Mouse)
+//	if err := h.Start(ctx); err != nil {
+//	}
+//	for {
+//		select {
+//		case ev := <-h.Events():
+//		case <-h.Done():
+//			return h.Err()
+//		}
+//	}
+//
+// A Hook literal queues no events, only its Filter and LastActivity see them.
+// A stopped Hook can be started again, its channels are kept.
+type Hook struct {
+	Sources Source // AllSources when 0
+
+	// Filter is called on the hook thread for every event, before it is
+	// queued. Returning true swallows the event: the other hooks and the
+	// focused window never see it. Windows removes hooks that take too long,
+	// keep it quick.
+	Filter func(*Event) bool
+
+	events   chan Event
+	errs     chan error
+	activity atomic.Int64 // unix nanoseconds
+
+	mu       sync.Mutex
+	threadID uint32
+	done     chan struct{}
+	err      error
+}
+
+func New(sources Source) *Hook {
+	return &Hook{
+		Sources: sources,
+		events:  make(chan Event, 256),
+		errs:    make(chan error, 16),
+	}
+}
+
+// Events delivers every event, swallowed ones included. The hook never
+// blocks on it: events are dropped with ErrEventsDropped when it is full.
+func (h *Hook) Events() <-chan Event {
+	return h.events
+}
+
+// Errors delivers the errors that don't stop the hook.
+func (h *Hook) Errors() <-chan error {
+	return h.errs
+}
+
+// LastActivity returns the time of the last event, or of Start when there was
+// none yet.
+func (h *Hook) LastActivity() time.Time {
+	return time.Unix(0, h.activity.Load())
+}
+
+// Idle returns how long there was no keyboard or mouse activity.
+func (h *Hook) Idle() time.Duration {
+	return time.Since(h.LastActivity())
+}
+
+// Start installs the hooks, they are removed when ctx is done or Stop is
+// called.
+func (h *Hook) Start(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.done != nil {
+		select {
+		case <-h.done:
+		default:
+			return ErrRunning
+		}
+	}
+	if h.Sources == 0 {
+		h.Sources = AllSources
+	}
+	h.activity.Store(time.Now().UnixNano())
+	h.err = nil
+	done := make(chan struct{})
+	h.done = done
+	ready := make(chan error, 1)
+	go h.run(done, ready)
+	if err := <-ready; err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			h.Stop()
+		case <-done:
+		}
+	}()
+	return nil
+}
+
+// Stop posts WM_QUIT to the hook thread and waits for the hooks to be removed.
+func (h *Hook) Stop() error {
+	h.mu.Lock()
+	done, threadID := h.done, h.threadID
+	h.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+	if err := winapi.PostThreadMessage(threadID, winapi.WM_QUIT, 0, 0); err != nil {
+		return errors.Wrap(err, "post quit message failed")
+	}
+	<-done
+	return nil
+}
+
+// Done is closed when the hook stops.
+func (h *Hook) Done() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.done
+}
+
+// Err returns the error that stopped the hook, nil after Stop.
+func (h *Hook) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+func (h *Hook) run(done chan struct{}, ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(done)
+
+	var msg winapi.Msg
+	// make sure the thread has a message queue before anyone posts WM_QUIT to it
+	winapi.PeekMessage(&msg, 0, winapi.WM_USER, winapi.WM_USER, winapi.PM_NOREMOVE)
+	tid, err := winapi.GetCurrentThreadId()
+	if err != nil {
+		ready <- errors.Wrap(err, "get current thread id failed")
+		return
+	}
+	mod, err := winapi.GetModuleHandle("")
+	if err != nil {
+		ready <- errors.Wrap(err, "get module handle failed")
+		return
+	}
+	h.threadID = uint32(tid)
+	running.Store(h.threadID, h)
+	defer running.Delete(h.threadID)
+
+	hookProcOnce.Do(func() {
+		keyboardProc = syscall.NewCallback(keyboardHookProc)
+		mouseProc = syscall.NewCallback(mouseHookProc)
+	})
+	if h.Sources&Keyboard != 0 {
+		hook, err := winapi.SetWindowsHookEx(winapi.WH_KEYBOARD_LL, keyboardProc, mod, 0)
+		if err != nil {
+			ready <- errors.Wrap(err, "set keyboard hook failed")
+			return
+		}
+		defer hook.UnhookWindowsHookEx()
+	}
+	if h.Sources&Mouse != 0 {
+		hook, err := winapi.SetWindowsHookEx(winapi.WH_MOUSE_LL, mouseProc, mod, 0)
+		if err != nil {
+			ready <- errors.Wrap(err, "set mouse hook failed")
+			return
+		}
+		defer hook.UnhookWindowsHookEx()
+	}
+	ready <- nil
+
+	for { // the hooks are called from GetMessage
+		ret, err := winapi.GetMessage(&msg, 0, 0, 0)
+		if err != nil {
+			h.mu.Lock()
+			h.err = errors.Wrap(err, "get message failed")
+			h.mu.Unlock()
+			return
+		}
+		if ret == 0 { // WM_QUIT
+			return
+		}
+	}
+}
+
+func runningHook() *Hook {
+	tid, _ := winapi.GetCurrentThreadId()
+	if v, ok := running.Load(uint32(tid)); ok {
+		return v.(*Hook)
+	}
+	return nil
+}
+
+func keyboardHookProc(nCode int, wParam winapi.WPARAM, lParam winapi.LPARAM) winapi.LRESULT {
+	if nCode == winapi.HC_ACTION {
+		if h := runningHook(); h != nil {
+			ev := KeyboardEvent(wParam, (*winapi.KBDLLHOOKSTRUCT)(proc.Pointer(uintptr(lParam))))
+			if h.deliver(&ev) {
+				return 1
+			}
+		}
+	}
+	return winapi.HHOOK(0).CallNextHookEx(nCode, wParam, lParam)
+}
+
+func mouseHookProc(nCode int, wParam winapi.WPARAM, lParam winapi.LPARAM) winapi.LRESULT {
+	if nCode == winapi.HC_ACTION {
+		if h := runningHook(); h != nil {
+			if ev, ok := MouseEvent(wParam, (*winapi.MSLLHOOKSTRUCT)(proc.Pointer(uintptr(lParam)))); ok && h.deliver(&ev) {
+				return 1
+			}
+		}
+	}
+	return winapi.HHOOK(0).CallNextHookEx(nCode, wParam, lParam)
+}
+
+// deliver reports whether the event must be swallowed.
+func (h *Hook) deliver(ev *Event) bool {
+	h.activity.Store(ev.Time.UnixNano())
+	swallow := h.Filter != nil && h.Filter(ev)
+	if h.events != nil {
+		select {
+		case h.events <- *ev:
+		default:
+			h.report(ErrEventsDropped)
+		}
+	}
+	return swallow
+}
+
+func (h *Hook) report(err error) {
+	select {
+	case h.errs <- err:
+	default:
+	}
+}
@@ -0,0 +1,42 @@
+package proc
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/FxStar/winapi/internal/winerr"
+	"github.com/FxStar/winapi/wstr"
+)
+
+// The conversions used by the generated wrappers, they work on every platform
+// so that the wrappers can run against a Fake.
+
+// UTF16PtrFromString returns a NUL terminated copy of s, syscall.EINVAL if s
+// holds a NUL.
+func UTF16PtrFromString(s string) (*uint16, error) {
+	return wstr.PtrFromString(s)
+}
+
+// BytePtrFromString is UTF16PtrFromString for the A functions.
+func BytePtrFromString(s string) (*byte, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 {
+			return nil, syscall.EINVAL
+		}
+	}
+	b := make([]byte, len(s)+1)
+	copy(b, s)
+	return &b[0], nil
+}
+
+// LastError returns the *winerr.Error of a failed call to api.
+func LastError(api string, lastErr error) error {
+	return winerr.New(api, lastErr)
+}
+
+// Pointer returns an address Windows hands over as an integer, the lParam of a
+// message or of a hook, as a pointer. The caller keeps its target valid. It is
+// unsafe.Pointer(addr), which vet would report at every call site.
+func Pointer(addr uintptr) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&addr))
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package proc
+
+// DLL has no procedures on other platforms, they return 0 and ErrNotSupported
+// until swapped.
+type DLL struct {
+	Name string
+}
+
+func NewDLL(name string) *DLL {
+	return &DLL{Name: name}
+}
+
+func (d *DLL) NewProc(name string) *Proc {
+	return New(name, unsupported{})
+}
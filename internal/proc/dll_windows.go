@@ -0,0 +1,16 @@
+package proc
+
+import "syscall"
+
+// DLL loads on first use, as syscall.LazyDLL.
+type DLL struct {
+	*syscall.LazyDLL
+}
+
+func NewDLL(name string) *DLL {
+	return &DLL{syscall.NewLazyDLL(name)}
+}
+
+func (d *DLL) NewProc(name string) *Proc {
+	return New(name, d.LazyDLL.NewProc(name))
+}
@@ -0,0 +1,72 @@
+package proc
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Step is the scripted outcome of one call. Do runs first, to fill the
+// buffers the arguments point to.
+type Step struct {
+	R1, R2 uintptr
+	Errno  syscall.Errno // returned as lastErr, 0 included, like LazyProc
+	Do     func(args []uintptr)
+}
+
+// Fake is a Caller that records its calls and plays Steps in order. Calls past
+// the last step fail with an error naming the call.
+//
+//	fake := &proc.Fake{Steps: []proc.Step{
+//		{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) { proc.PutUint32(a[5], 200) }},
+//		{R1: 1},
+//	}}
+type Fake struct {
+	Steps []Step
+
+	mu    sync.Mutex
+	calls [][]uintptr
+}
+
+func (f *Fake) Call(a ...uintptr) (r1, r2 uintptr, lastErr error) {
+	f.mu.Lock()
+	n := len(f.calls)
+	f.calls = append(f.calls, append([]uintptr(nil), a...))
+	f.mu.Unlock()
+	if n >= len(f.Steps) {
+		return 0, 0, fmt.Errorf("proc: unexpected call %d with %d arguments", n+1, len(a))
+	}
+	s := f.Steps[n]
+	if s.Do != nil {
+		s.Do(a)
+	}
+	return s.R1, s.R2, s.Errno
+}
+
+// Calls returns the arguments of every call so far.
+func (f *Fake) Calls() [][]uintptr {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]uintptr(nil), f.calls...)
+}
+
+// The Put functions write through an argument that holds a pointer, for
+// Step.Do.
+
+func PutUint32(addr uintptr, v uint32) {
+	*(*uint32)(Pointer(addr)) = v
+}
+
+func PutUint16s(addr uintptr, v []uint16) {
+	copy(unsafe.Slice((*uint16)(Pointer(addr)), len(v)), v)
+}
+
+func PutBytes(addr uintptr, v []byte) {
+	copy(unsafe.Slice((*byte)(Pointer(addr)), len(v)), v)
+}
+
+// Uint32 reads through an argument, to check what the wrapper passed in.
+func Uint32(addr uintptr) uint32 {
+	return *(*uint32)(Pointer(addr))
+}
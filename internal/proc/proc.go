@@ -0,0 +1,46 @@
+// Package proc is the seam between the bindings and the DLLs. A Proc calls
+// its DLL procedure on Windows, fails with ErrNotSupported elsewhere, and can
+// be swapped for a scripted Fake to exercise the wrapper logic anywhere.
+package proc
+
+import "errors"
+
+var ErrNotSupported = errors.New("not supported on this platform")
+
+// Caller is implemented by *syscall.LazyProc and Fake.
+type Caller interface {
+	Call(a ...uintptr) (r1, r2 uintptr, lastErr error)
+}
+
+type Proc struct {
+	Name   string
+	caller Caller
+}
+
+func New(name string, c Caller) *Proc {
+	return &Proc{Name: name, caller: c}
+}
+
+// Call calls the procedure. Like syscall.LazyProc.Call, Go pointers converted
+// to uintptr in the argument list stay valid during the call.
+//
+//go:uintptrescapes
+func (p *Proc) Call(a ...uintptr) (r1, r2 uintptr, lastErr error) {
+	return p.caller.Call(a...)
+}
+
+// Swap installs c and returns the previous Caller, to restore it:
+//
+//	prev := getJobProc.Swap(fake)
+//	defer getJobProc.Swap(prev)
+func (p *Proc) Swap(c Caller) Caller {
+	prev := p.caller
+	p.caller = c
+	return prev
+}
+
+type unsupported struct{}
+
+func (unsupported) Call(a ...uintptr) (r1, r2 uintptr, lastErr error) {
+	return 0, 0, ErrNotSupported
+}
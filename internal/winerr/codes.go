@@ -0,0 +1,72 @@
+package winerr
+
+import "syscall"
+
+// System error codes, the ones with a message in messages.txt.
+const (
+	ERROR_SUCCESS                   = syscall.Errno(0)
+	ERROR_INVALID_FUNCTION          = syscall.Errno(1)
+	ERROR_FILE_NOT_FOUND            = syscall.Errno(2)
+	ERROR_PATH_NOT_FOUND            = syscall.Errno(3)
+	ERROR_ACCESS_DENIED             = syscall.Errno(5)
+	ERROR_INVALID_HANDLE            = syscall.Errno(6)
+	ERROR_NOT_ENOUGH_MEMORY         = syscall.Errno(8)
+	ERROR_INVALID_ACCESS            = syscall.Errno(12)
+	ERROR_INVALID_DATA              = syscall.Errno(13)
+	ERROR_OUTOFMEMORY               = syscall.Errno(14)
+	ERROR_NOT_READY                 = syscall.Errno(21)
+	ERROR_OUT_OF_PAPER              = syscall.Errno(28)
+	ERROR_GEN_FAILURE               = syscall.Errno(31)
+	ERROR_SHARING_VIOLATION         = syscall.Errno(32)
+	ERROR_HANDLE_EOF                = syscall.Errno(38)
+	ERROR_NOT_SUPPORTED             = syscall.Errno(50)
+	ERROR_DEV_NOT_EXIST             = syscall.Errno(55)
+	ERROR_PRINTQ_FULL               = syscall.Errno(61)
+	ERROR_PRINT_CANCELLED           = syscall.Errno(63)
+	ERROR_INVALID_PARAMETER         = syscall.Errno(87)
+	ERROR_BROKEN_PIPE               = syscall.Errno(109)
+	ERROR_CALL_NOT_IMPLEMENTED      = syscall.Errno(120)
+	ERROR_INSUFFICIENT_BUFFER       = syscall.Errno(122)
+	ERROR_INVALID_NAME              = syscall.Errno(123)
+	ERROR_MOD_NOT_FOUND             = syscall.Errno(126)
+	ERROR_PROC_NOT_FOUND            = syscall.Errno(127)
+	ERROR_BUSY                      = syscall.Errno(170)
+	ERROR_ALREADY_EXISTS            = syscall.Errno(183)
+	ERROR_MORE_DATA                 = syscall.Errno(234)
+	WAIT_TIMEOUT                    = syscall.Errno(258)
+	ERROR_NO_MORE_ITEMS             = syscall.Errno(259)
+	ERROR_OPERATION_ABORTED         = syscall.Errno(995)
+	ERROR_IO_INCOMPLETE             = syscall.Errno(996)
+	ERROR_IO_PENDING                = syscall.Errno(997)
+	ERROR_NOACCESS                  = syscall.Errno(998)
+	ERROR_INVALID_FLAGS             = syscall.Errno(1004)
+	ERROR_NO_UNICODE_TRANSLATION    = syscall.Errno(1113)
+	ERROR_DEVICE_NOT_CONNECTED      = syscall.Errno(1167)
+	ERROR_NOT_FOUND                 = syscall.Errno(1168)
+	ERROR_CANCELLED                 = syscall.Errno(1223)
+	ERROR_INVALID_WINDOW_HANDLE     = syscall.Errno(1400)
+	ERROR_INVALID_MENU_HANDLE       = syscall.Errno(1401)
+	ERROR_INVALID_CURSOR_HANDLE     = syscall.Errno(1402)
+	ERROR_INVALID_HOOK_HANDLE       = syscall.Errno(1404)
+	ERROR_HOTKEY_ALREADY_REGISTERED = syscall.Errno(1409)
+	ERROR_CLASS_ALREADY_EXISTS      = syscall.Errno(1410)
+	ERROR_CLASS_DOES_NOT_EXIST      = syscall.Errno(1411)
+	ERROR_CLASS_HAS_WINDOWS         = syscall.Errno(1412)
+	ERROR_INVALID_INDEX             = syscall.Errno(1413)
+	ERROR_HOTKEY_NOT_REGISTERED     = syscall.Errno(1419)
+	ERROR_HOOK_NEEDS_HMOD           = syscall.Errno(1428)
+	ERROR_INVALID_THREAD_ID         = syscall.Errno(1444)
+	ERROR_NO_SYSTEM_RESOURCES       = syscall.Errno(1450)
+	ERROR_TIMEOUT                   = syscall.Errno(1460)
+	ERROR_UNKNOWN_PRINTER_DRIVER    = syscall.Errno(1797)
+	ERROR_UNKNOWN_PRINTPROCESSOR    = syscall.Errno(1798)
+	ERROR_INVALID_PRINTER_NAME      = syscall.Errno(1801)
+	ERROR_PRINTER_ALREADY_EXISTS    = syscall.Errno(1802)
+	ERROR_INVALID_PRINTER_COMMAND   = syscall.Errno(1803)
+	ERROR_INVALID_DATATYPE          = syscall.Errno(1804)
+	ERROR_INVALID_ENVIRONMENT       = syscall.Errno(1805)
+	ERROR_PRINTER_DELETED           = syscall.Errno(1905)
+	ERROR_INVALID_PRINTER_STATE     = syscall.Errno(1906)
+	ERROR_PRINTER_DRIVER_IN_USE     = syscall.Errno(3001)
+	ERROR_PRINTER_NOT_FOUND         = syscall.Errno(3012)
+)
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package winerr
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// unknownText can't use errno.Error(), it would give the text of the local
+// errno with that number.
+func unknownText(errno syscall.Errno) string {
+	return "Win32 error " + strconv.FormatUint(uint64(errno), 10)
+}
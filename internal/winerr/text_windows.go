@@ -0,0 +1,8 @@
+package winerr
+
+import "syscall"
+
+// unknownText asks FormatMessage.
+func unknownText(errno syscall.Errno) string {
+	return errno.Error()
+}
@@ -0,0 +1,83 @@
+// Package winerr holds the Win32 error codes and their messages, re-exported
+// by winapi. The messages are embedded so that the text of an error is the
+// same on every platform.
+package winerr
+
+import (
+	"bufio"
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Error is a failed call, errors.Is matches its Errno:
+//
+//	if errors.Is(err, winapi.ERROR_PRINTER_NOT_FOUND) {
+//	}
+type Error struct {
+	API   string // the DLL procedure, OpenPrinterW
+	Errno syscall.Errno
+}
+
+func (e *Error) Error() string {
+	if e.API == "" {
+		return Text(e.Errno)
+	}
+	return e.API + ": " + Text(e.Errno)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Errno
+}
+
+// New returns the *Error of a failed call from its lastErr, with
+// ERROR_INVALID_PARAMETER when the procedure failed without setting one.
+// Errors that are not an Errno, such as ErrNotSupported, are returned as
+// they are.
+func New(api string, lastErr error) error {
+	if lastErr == nil {
+		return &Error{API: api, Errno: ERROR_INVALID_PARAMETER}
+	}
+	errno, ok := lastErr.(syscall.Errno)
+	if !ok {
+		return lastErr
+	}
+	if errno == 0 {
+		errno = ERROR_INVALID_PARAMETER
+	}
+	return &Error{API: api, Errno: errno}
+}
+
+//go:embed messages.txt
+var messagesTxt string
+
+var (
+	messagesOnce sync.Once
+	messages     map[syscall.Errno]string
+)
+
+// Text returns the system message of errno, without the trailing period
+// FormatMessage adds.
+func Text(errno syscall.Errno) string {
+	messagesOnce.Do(func() {
+		messages = make(map[syscall.Errno]string)
+		s := bufio.NewScanner(strings.NewReader(messagesTxt))
+		for s.Scan() {
+			code, text, ok := strings.Cut(s.Text(), "\t")
+			if !ok || strings.HasPrefix(code, "#") {
+				continue
+			}
+			n, err := strconv.ParseUint(code, 10, 32)
+			if err != nil {
+				panic("winerr: bad messages.txt line " + strconv.Quote(s.Text()))
+			}
+			messages[syscall.Errno(n)] = text
+		}
+	})
+	if text, ok := messages[errno]; ok {
+		return text
+	}
+	return unknownText(errno)
+}
@@ -0,0 +1,100 @@
+package winerr
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	errOther := errors.New("not supported")
+	tests := []struct {
+		name    string
+		lastErr error
+		want    error
+	}{
+		{"nil", nil, &Error{API: "OpenPrinterW", Errno: ERROR_INVALID_PARAMETER}},
+		{"errno 0", syscall.Errno(0), &Error{API: "OpenPrinterW", Errno: ERROR_INVALID_PARAMETER}},
+		{"errno", ERROR_ACCESS_DENIED, &Error{API: "OpenPrinterW", Errno: ERROR_ACCESS_DENIED}},
+		{"not an errno", errOther, errOther},
+	}
+	for _, tt := range tests {
+		got := New("OpenPrinterW", tt.lastErr)
+		if e, ok := got.(*Error); ok {
+			if w := tt.want.(*Error); *e != *w {
+				t.Errorf("%s: New() = %+v, want %+v", tt.name, e, w)
+			}
+		} else if got != tt.want {
+			t.Errorf("%s: New() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{API: "OpenPrinterW", Errno: ERROR_INVALID_PRINTER_NAME}, "OpenPrinterW: The printer name is invalid"},
+		{&Error{Errno: ERROR_ACCESS_DENIED}, "Access is denied"},
+		{&Error{API: "EnumPrintersW", Errno: ERROR_PRINTER_NOT_FOUND}, "EnumPrintersW: No printers were found"},
+		{&Error{API: "GetJobW", Errno: ERROR_INSUFFICIENT_BUFFER}, "GetJobW: The data area passed to a system call is too small"},
+	}
+	for _, tt := range tests {
+		if s := tt.err.Error(); s != tt.want {
+			t.Errorf("Error() of %d = %q, want %q", tt.err.Errno, s, tt.want)
+		}
+	}
+
+	// unknown codes go to FormatMessage on Windows
+	unknown := syscall.Errno(0x2000_0042)
+	want := "Win32 error 536870978"
+	if runtime.GOOS == "windows" {
+		want = unknown.Error()
+	}
+	if s := Text(unknown); s != want {
+		t.Errorf("Text(%d) = %q, want %q", unknown, s, want)
+	}
+}
+
+// TestMessages checks a few texts of messages.txt, and that none kept the
+// period of FormatMessage.
+func TestMessages(t *testing.T) {
+	for errno, text := range map[syscall.Errno]string{
+		ERROR_SUCCESS:           "The operation completed successfully",
+		ERROR_MORE_DATA:         "More data is available",
+		ERROR_PRINTER_DELETED:   "The printer has been deleted",
+		ERROR_PRINTER_NOT_FOUND: "No printers were found",
+	} {
+		if s := Text(errno); s != text {
+			t.Errorf("Text(%d) = %q, want %q", errno, s, text)
+		}
+	}
+	for errno, text := range messages {
+		if text == "" || text[len(text)-1] == '.' {
+			t.Errorf("message of %d = %q", errno, text)
+		}
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	for _, errno := range []syscall.Errno{ERROR_ACCESS_DENIED, ERROR_INSUFFICIENT_BUFFER, ERROR_INVALID_PRINTER_NAME, ERROR_PRINTER_NOT_FOUND} {
+		err := fmt.Errorf("print failed: %w", New("OpenPrinterW", errno))
+		if !errors.Is(err, errno) {
+			t.Errorf("errors.Is(%v, %d) = false", err, errno)
+		}
+		if errors.Is(err, ERROR_INVALID_PARAMETER) {
+			t.Errorf("errors.Is(%v, ERROR_INVALID_PARAMETER) = true", err)
+		}
+		var e *Error
+		if !errors.As(err, &e) || e.API != "OpenPrinterW" || e.Errno != errno {
+			t.Errorf("errors.As(%v) = %+v", err, e)
+		}
+		var en syscall.Errno
+		if !errors.As(err, &en) || en != errno {
+			t.Errorf("errors.As(%v) to an Errno = %d", err, en)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package kbcap
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type Keystroke struct {
+	Rune   rune
+	Time   time.Time
+	Device string // source device when known, for Classifier.Devices
+}
+
+// Scan is a complete line, terminator excluded and prefix/suffix stripped.
+type Scan struct {
+	Text       string
+	Start, End time.Time // first key and terminator
+	Keys       []Keystroke
+	Source     KeySource // SourceUnknown without Classifier
+}
+
+func (s *Scan) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Assembler buffers characters into scans. A terminator completes the pending
+// line, a gap longer than Timeout between two keys drops it. It has no system
+// dependency, MonitorKeyboard feeds it from the keyboard hook.
+type Assembler struct {
+	Terminators string        // any of these completes a scan, "\r\n" by default
+	Prefix      string        // stripped from the start of a scan when present
+	Suffix      string        // stripped from the end of a scan when present
+	MinLength   int           // shorter scans (in runes, after stripping) are dropped
+	Timeout     time.Duration // max gap between two keys, 0 means no limit
+
+	// Classifier, when set, restarts the line on gaps over MaxKeyInterval
+	// and drops scans not classified as SourceScanner.
+	Classifier *Classifier
+
+	keys []Keystroke
+	last time.Time
+}
+
+// DefaultTimeout is the Timeout of NewAssembler.
+const DefaultTimeout = 3 * time.Second
+
+func NewAssembler() *Assembler {
+	return &Assembler{
+		Terminators: "\r\n",
+		MinLength:   1,
+		Timeout:     DefaultTimeout,
+	}
+}
+
+// Feed adds a key pressed at t, it returns the scan the key completes, if any.
+func (a *Assembler) Feed(r rune, t time.Time) *Scan {
+	return a.FeedKey(Keystroke{Rune: r, Time: t})
+}
+
+func (a *Assembler) FeedKey(k Keystroke) *Scan {
+	t := k.Time
+	if len(a.keys) > 0 {
+		gap := t.Sub(a.last)
+		if a.Timeout > 0 && gap > a.Timeout ||
+			a.Classifier != nil && a.Classifier.MaxKeyInterval > 0 && gap > a.Classifier.MaxKeyInterval {
+			a.Reset()
+		}
+	}
+	a.last = t
+	if !strings.ContainsRune(a.Terminators, k.Rune) {
+		a.keys = append(a.keys, k)
+		return nil
+	}
+	if len(a.keys) == 0 {
+		return nil
+	}
+	scan := &Scan{
+		Text:  a.Pending(),
+		Start: a.keys[0].Time,
+		End:   t,
+		Keys:  a.keys,
+	}
+	a.keys = nil
+	if a.Classifier != nil {
+		if scan.Source = a.Classifier.ClassifyScan(scan); scan.Source != SourceScanner {
+			return nil
+		}
+	}
+	scan.Text = strings.TrimPrefix(scan.Text, a.Prefix)
+	scan.Text = strings.TrimSuffix(scan.Text, a.Suffix)
+	if scan.Text == "" || utf8.RuneCountInString(scan.Text) < a.MinLength {
+		return nil
+	}
+	return scan
+}
+
+// Pending returns the characters buffered since the last scan.
+func (a *Assembler) Pending() string {
+	rs := make([]rune, len(a.keys))
+	for i, k := range a.keys {
+		rs[i] = k.Rune
+	}
+	return string(rs)
+}
+
+func (a *Assembler) Reset() {
+	a.keys = nil
+}
@@ -0,0 +1,116 @@
+package kbcap
+
+import (
+	"testing"
+	"time"
+)
+
+// timeline feeds keys to an assembler, each step after the previous one.
+type timeline struct {
+	a   *Assembler
+	now time.Time
+}
+
+func newTimeline(a *Assembler) *timeline {
+	return &timeline{a: a, now: time.Unix(1700000000, 0)}
+}
+
+func (tl *timeline) wait(d time.Duration) {
+	tl.now = tl.now.Add(d)
+}
+
+// typeText returns the scans s completes.
+func (tl *timeline) typeText(s string, step time.Duration) []*Scan {
+	var scans []*Scan
+	for _, r := range s {
+		tl.wait(step)
+		if scan := tl.a.Feed(r, tl.now); scan != nil {
+			scans = append(scans, scan)
+		}
+	}
+	return scans
+}
+
+func texts(scans []*Scan) []string {
+	var s []string
+	for _, scan := range scans {
+		s = append(s, scan.Text)
+	}
+	return s
+}
+
+func TestAssembler(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(a *Assembler)
+		input string
+		want  []string
+	}{
+		{"lines", nil, "4006381333931\r123\n\r\n", []string{"4006381333931", "123"}},
+		{"no terminator", nil, "4006381333931", nil},
+		{"terminators", func(a *Assembler) { a.Terminators = "\t" }, "ab\tcd\r\t", []string{"ab", "cd\r"}},
+		{"prefix", func(a *Assembler) { a.Prefix = "]C1" }, "]C1ABC\rXYZ\r]C1\r", []string{"ABC", "XYZ"}},
+		{"suffix", func(a *Assembler) { a.Suffix = "#" }, "ABC#\rABC\r", []string{"ABC", "ABC"}},
+		{"min length", func(a *Assembler) { a.MinLength = 3 }, "AB\rABC\räöü\r", []string{"ABC", "äöü"}},
+	}
+	for _, tt := range tests {
+		a := NewAssembler()
+		if tt.setup != nil {
+			tt.setup(a)
+		}
+		got := texts(newTimeline(a).typeText(tt.input, 10*time.Millisecond))
+		if !equalStrings(got, tt.want) {
+			t.Errorf("%s: scans = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssemblerTimeout(t *testing.T) {
+	a := NewAssembler()
+	a.Timeout = time.Second
+	tl := newTimeline(a)
+	tl.typeText("XY", 10*time.Millisecond)
+	tl.wait(2 * time.Second)
+	scans := tl.typeText("123\r", 10*time.Millisecond)
+	if len(scans) != 1 || scans[0].Text != "123" {
+		t.Fatalf("scans = %q, want [123]", texts(scans))
+	}
+	if d := scans[0].Duration(); d != 30*time.Millisecond {
+		t.Errorf("Duration() = %v", d)
+	}
+	if n := len(scans[0].Keys); n != 3 {
+		t.Errorf("got %d keys", n)
+	}
+
+	a.Timeout = 0
+	tl.typeText("AB", 10*time.Millisecond)
+	tl.wait(time.Hour)
+	if got := texts(tl.typeText("C\r", 10*time.Millisecond)); !equalStrings(got, []string{"ABC"}) {
+		t.Errorf("scans = %q, want [ABC]", got)
+	}
+}
+
+func TestAssemblerPending(t *testing.T) {
+	a := NewAssembler()
+	tl := newTimeline(a)
+	tl.typeText("ABC", time.Millisecond)
+	if p := a.Pending(); p != "ABC" {
+		t.Errorf("Pending() = %q", p)
+	}
+	a.Reset()
+	if got := texts(tl.typeText("D\r", time.Millisecond)); !equalStrings(got, []string{"D"}) {
+		t.Errorf("scans = %q, want [D]", got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package kbcap
+
+import "time"
+
+type KeySource int
+
+const (
+	SourceUnknown KeySource = iota
+	SourceScanner
+	SourceHuman
+)
+
+func (s KeySource) String() string {
+	switch s {
+	case SourceScanner:
+		return "scanner"
+	case SourceHuman:
+		return "human"
+	}
+	return "unknown"
+}
+
+// Classifier tells barcode scanner bursts from human typing. A known device
+// decides on its own, otherwise the burst is a scan when it has at least
+// MinKeys keys, their mean interval is at most MaxMeanInterval and no single
+// interval exceeds MaxKeyInterval.
+type Classifier struct {
+	MaxMeanInterval time.Duration
+	MaxKeyInterval  time.Duration
+	MinKeys         int
+	Devices         map[string]KeySource // by Keystroke.Device
+}
+
+func NewClassifier() *Classifier {
+	return &Classifier{
+		MaxMeanInterval: 30 * time.Millisecond,
+		MaxKeyInterval:  100 * time.Millisecond,
+		MinKeys:         4,
+		Devices:         map[string]KeySource{},
+	}
+}
+
+// Classify classifies keys followed by a terminator pressed at end, end may be zero.
+func (c *Classifier) Classify(keys []Keystroke, end time.Time) KeySource {
+	for _, k := range keys {
+		if k.Device == "" {
+			continue
+		}
+		if src := c.Devices[k.Device]; src != SourceUnknown {
+			return src
+		}
+	}
+	if len(keys) < c.MinKeys {
+		return SourceHuman
+	}
+	times := make([]time.Time, 0, len(keys)+1)
+	for _, k := range keys {
+		times = append(times, k.Time)
+	}
+	if !end.IsZero() {
+		times = append(times, end)
+	}
+	var total time.Duration
+	for i := 1; i < len(times); i++ {
+		gap := times[i].Sub(times[i-1])
+		if c.MaxKeyInterval > 0 && gap > c.MaxKeyInterval {
+			return SourceHuman
+		}
+		total += gap
+	}
+	if len(times) > 1 && total/time.Duration(len(times)-1) > c.MaxMeanInterval {
+		return SourceHuman
+	}
+	return SourceScanner
+}
+
+func (c *Classifier) ClassifyScan(s *Scan) KeySource {
+	return c.Classify(s.Keys, s.End)
+}
@@ -0,0 +1,110 @@
+package kbcap
+
+import (
+	"testing"
+	"time"
+)
+
+// keys builds a burst of len(gaps)+1 keys, gaps[i] before key i+1.
+func keys(device string, gaps ...time.Duration) ([]Keystroke, time.Time) {
+	t := time.Unix(1700000000, 0)
+	ks := []Keystroke{{Rune: '0', Time: t, Device: device}}
+	for i, gap := range gaps {
+		t = t.Add(gap)
+		ks = append(ks, Keystroke{Rune: rune('1' + i%9), Time: t, Device: device})
+	}
+	return ks, t
+}
+
+func repeat(d time.Duration, n int) []time.Duration {
+	gaps := make([]time.Duration, n)
+	for i := range gaps {
+		gaps[i] = d
+	}
+	return gaps
+}
+
+func TestClassifier(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		gaps []time.Duration
+		want KeySource
+	}{
+		{"scanner", repeat(8*ms, 12), SourceScanner},
+		{"scanner at the mean limit", repeat(30*ms, 5), SourceScanner},
+		{"human", repeat(150*ms, 12), SourceHuman},
+		{"fast typist", repeat(45*ms, 6), SourceHuman},
+		{"too few keys", repeat(5*ms, 2), SourceHuman},
+		{"one slow key", append(repeat(5*ms, 10), 120*ms), SourceHuman},
+		{"jitter under the limits", []time.Duration{5 * ms, 60 * ms, 5 * ms, 40 * ms, 10 * ms}, SourceScanner},
+	}
+	c := NewClassifier()
+	for _, tt := range tests {
+		ks, _ := keys("", tt.gaps...)
+		if got := c.Classify(ks, time.Time{}); got != tt.want {
+			t.Errorf("%s: Classify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClassifierTerminator(t *testing.T) {
+	c := NewClassifier()
+	ks, last := keys("", repeat(10*time.Millisecond, 6)...)
+	if got := c.Classify(ks, last.Add(10*time.Millisecond)); got != SourceScanner {
+		t.Errorf("Classify() = %v, want scanner", got)
+	}
+	if got := c.Classify(ks, last.Add(time.Second)); got != SourceHuman {
+		t.Errorf("Classify() with a late terminator = %v, want human", got)
+	}
+	c.MaxKeyInterval = 0 // no limit, the mean still decides
+	if got := c.Classify(ks, last.Add(50*time.Millisecond)); got != SourceScanner {
+		t.Errorf("Classify() without MaxKeyInterval = %v, want scanner", got)
+	}
+}
+
+func TestClassifierDevices(t *testing.T) {
+	c := NewClassifier()
+	c.Devices["scanner"] = SourceScanner
+	c.Devices["keyboard"] = SourceHuman
+
+	slow, _ := keys("scanner", repeat(200*time.Millisecond, 5)...)
+	if got := c.Classify(slow, time.Time{}); got != SourceScanner {
+		t.Errorf("known scanner: Classify() = %v", got)
+	}
+	fast, _ := keys("keyboard", repeat(time.Millisecond, 10)...)
+	if got := c.Classify(fast, time.Time{}); got != SourceHuman {
+		t.Errorf("known keyboard: Classify() = %v", got)
+	}
+	fast, _ = keys("other", repeat(time.Millisecond, 10)...)
+	if got := c.Classify(fast, time.Time{}); got != SourceScanner {
+		t.Errorf("unknown device: Classify() = %v", got)
+	}
+}
+
+func TestAssemblerClassifier(t *testing.T) {
+	a := NewAssembler()
+	a.Classifier = NewClassifier()
+	tl := newTimeline(a)
+	if scans := tl.typeText("hello\r", 150*time.Millisecond); len(scans) != 0 {
+		t.Errorf("typing: scans = %q", texts(scans))
+	}
+	tl.typeText("hel", 150*time.Millisecond)
+	tl.wait(time.Second)
+	scans := tl.typeText("0123456789\r", 10*time.Millisecond)
+	if len(scans) != 1 || scans[0].Text != "0123456789" || scans[0].Source != SourceScanner {
+		t.Fatalf("scan after typing: scans = %q", texts(scans))
+	}
+	if scans := tl.typeText("abcd\r", 60*time.Millisecond); len(scans) != 0 {
+		t.Errorf("fast typing: scans = %q", texts(scans))
+	}
+
+	a.Classifier.Devices["kbd"] = SourceHuman
+	tl.wait(time.Second)
+	for _, r := range "12345\r" {
+		tl.wait(time.Millisecond)
+		if scan := a.FeedKey(Keystroke{Rune: r, Time: tl.now, Device: "kbd"}); scan != nil {
+			t.Errorf("known keyboard: scan %q", scan.Text)
+		}
+	}
+}
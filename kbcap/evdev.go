@@ -0,0 +1,179 @@
+package kbcap
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// Linux input event types and key values, see linux/input-event-codes.h
+const (
+	EV_SYN = 0x00
+	EV_KEY = 0x01
+	EV_MSC = 0x04
+
+	KeyReleased = 0
+	KeyPressed  = 1
+	KeyRepeated = 2
+)
+
+// Sizes of struct input_event, a timeval followed by type, code and value.
+const (
+	InputEventSize64 = 24
+	InputEventSize32 = 16
+)
+
+// InputEvent is a struct input_event read from /dev/input/event*.
+type InputEvent struct {
+	Time  time.Time
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// ReadInputEvent reads a little endian input_event of size bytes.
+func ReadInputEvent(r io.Reader, size int) (InputEvent, error) {
+	var buf [InputEventSize64]byte
+	if size != InputEventSize64 && size != InputEventSize32 {
+		return InputEvent{}, errors.Errorf("invalid input event size %d", size)
+	}
+	b := buf[:size]
+	if _, err := io.ReadFull(r, b); err != nil {
+		return InputEvent{}, err
+	}
+	var ev InputEvent
+	var sec, usec int64
+	if size == InputEventSize64 {
+		sec = int64(binary.LittleEndian.Uint64(b))
+		usec = int64(binary.LittleEndian.Uint64(b[8:]))
+	} else {
+		sec = int64(int32(binary.LittleEndian.Uint32(b)))
+		usec = int64(int32(binary.LittleEndian.Uint32(b[4:])))
+	}
+	b = b[size-8:]
+	ev.Time = time.Unix(sec, usec*1000)
+	ev.Type = binary.LittleEndian.Uint16(b)
+	ev.Code = binary.LittleEndian.Uint16(b[2:])
+	ev.Value = int32(binary.LittleEndian.Uint32(b[4:]))
+	return ev, nil
+}
+
+// linuxExtendedKeys maps the Linux key codes past the set 1 range to set 1
+// scan codes, below KEY_RO (89) they are the same.
+var linuxExtendedKeys = map[uint16]uint32{
+	89:  0x73,   // KEY_RO
+	96:  0xe01c, // KEY_KPENTER
+	97:  0xe01d, // KEY_RIGHTCTRL
+	98:  0xe035, // KEY_KPSLASH
+	100: 0xe038, // KEY_RIGHTALT
+	102: 0xe047, // KEY_HOME
+	103: 0xe048, // KEY_UP
+	104: 0xe049, // KEY_PAGEUP
+	105: 0xe04b, // KEY_LEFT
+	106: 0xe04d, // KEY_RIGHT
+	107: 0xe04f, // KEY_END
+	108: 0xe050, // KEY_DOWN
+	109: 0xe051, // KEY_PAGEDOWN
+	110: 0xe052, // KEY_INSERT
+	111: 0xe053, // KEY_DELETE
+	121: 0x7e,   // KEY_KPCOMMA
+	124: 0x7d,   // KEY_YEN
+	125: 0xe05b, // KEY_LEFTMETA
+	126: 0xe05c, // KEY_RIGHTMETA
+	127: 0xe05d, // KEY_COMPOSE
+}
+
+// linuxScanCode returns the set 1 scan code of a Linux key code, 0 if unknown.
+func linuxScanCode(code uint16) (scanCode uint32, extended bool) {
+	if code > 0 && code < 89 {
+		return uint32(code), false
+	}
+	sc := linuxExtendedKeys[code]
+	return sc &^ 0xe000, sc&0xe000 != 0
+}
+
+// EvdevReader turns evdev EV_KEY events into the KeyEvents Monitor delivers,
+// and runs them through the same Assembler, so an io.Reader of recorded
+// events behaves like a device:
+//
+//	r := NewEvdevReader(f)
+//	for {
+//		ev, err := r.Next()
+//		if err != nil {
+//		}
+//		if ev.Scan != nil {
+//		}
+//	}
+type EvdevReader struct {
+	Assembler *Assembler
+	Layout    *Layout // LayoutUS when nil
+	Device    string  // set as KeyEvent.Device
+	EventSize int     // InputEventSize64 by default
+
+	r     io.Reader
+	pipe  *pipeline
+	trans *Translator
+}
+
+func NewEvdevReader(r io.Reader) *EvdevReader {
+	return &EvdevReader{
+		Assembler: NewAssembler(),
+		EventSize: InputEventSize64,
+		r:         r,
+	}
+}
+
+// Next returns the next key down, key ups only update the modifier state.
+func (e *EvdevReader) Next() (KeyEvent, error) {
+	if e.pipe == nil {
+		if e.Assembler == nil {
+			e.Assembler = NewAssembler()
+		}
+		if e.Layout == nil {
+			e.Layout = LayoutUS
+		}
+		e.pipe = &pipeline{asm: e.Assembler}
+		e.trans = NewTranslator(e.Layout)
+	}
+	for {
+		in, err := ReadInputEvent(e.r, e.EventSize)
+		if err != nil {
+			return KeyEvent{}, err
+		}
+		if in.Type != EV_KEY {
+			continue
+		}
+		ev, ok := e.keyEvent(in)
+		if !ok {
+			continue
+		}
+		e.pipe.key(&ev)
+		return ev, nil
+	}
+}
+
+func (e *EvdevReader) keyEvent(in InputEvent) (KeyEvent, bool) {
+	scanCode, extended := linuxScanCode(in.Code)
+	vk := e.Layout.VirtualKey(scanCode, extended)
+	if vk == 0 {
+		return KeyEvent{}, false
+	}
+	if in.Value == KeyReleased {
+		e.trans.Key(vk, false)
+		return KeyEvent{}, false
+	}
+	ev := KeyEvent{
+		VkCode:   vk,
+		ScanCode: scanCode,
+		Time:     in.Time,
+		Device:   e.Device,
+	}
+	if extended {
+		ev.Flags = winapi.LLKHF_EXTENDED
+	}
+	ev.Rune, _ = e.trans.Key(vk, true)
+	return ev, true
+}
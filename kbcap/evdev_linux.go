@@ -0,0 +1,54 @@
+package kbcap
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const EVIOCGRAB = 0x40044590 // _IOW('E', 0x90, int)
+
+// EvdevDevice is an open /dev/input/event* device.
+type EvdevDevice struct {
+	*EvdevReader
+	f       *os.File
+	grabbed bool
+}
+
+// OpenEvdev opens an input device, with grab its events no longer reach
+// other readers such as the console or X, until Close.
+func OpenEvdev(path string, grab bool) (*EvdevDevice, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	d := &EvdevDevice{EvdevReader: NewEvdevReader(f), f: f}
+	d.Device = path
+	d.EventSize = int(2*unsafe.Sizeof(uintptr(0))) + 8 // struct timeval is two longs
+	if grab {
+		if err := d.grab(1); err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "grab input device failed")
+		}
+		d.grabbed = true
+	}
+	return d, nil
+}
+
+func (d *EvdevDevice) grab(on uintptr) error {
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, d.f.Fd(), EVIOCGRAB, on)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+func (d *EvdevDevice) Close() error {
+	if d.grabbed {
+		d.grab(0)
+		d.grabbed = false
+	}
+	return d.f.Close()
+}
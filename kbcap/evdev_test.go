@@ -0,0 +1,146 @@
+package kbcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/FxStar/winapi"
+)
+
+// Linux key codes, see linux/input-event-codes.h
+const (
+	keyEsc       = 1
+	key1         = 2
+	key2         = 3
+	keyY         = 21
+	keyEnter     = 28
+	keyA         = 30
+	keyLeftShift = 42
+	keyKPEnter   = 96
+	keyUp        = 103
+)
+
+// evdevStream writes input_event records as a keyboard reports them, each
+// followed by EV_SYN.
+type evdevStream struct {
+	buf  bytes.Buffer
+	size int
+	usec int64
+}
+
+func (s *evdevStream) event(typ, code uint16, value int32) {
+	b := make([]byte, s.size)
+	sec, usec := s.usec/1e6, s.usec%1e6
+	if s.size == InputEventSize64 {
+		binary.LittleEndian.PutUint64(b, uint64(sec))
+		binary.LittleEndian.PutUint64(b[8:], uint64(usec))
+	} else {
+		binary.LittleEndian.PutUint32(b, uint32(sec))
+		binary.LittleEndian.PutUint32(b[4:], uint32(usec))
+	}
+	binary.LittleEndian.PutUint16(b[s.size-8:], typ)
+	binary.LittleEndian.PutUint16(b[s.size-6:], code)
+	binary.LittleEndian.PutUint32(b[s.size-4:], uint32(value))
+	s.buf.Write(b)
+}
+
+func (s *evdevStream) key(code uint16, value int32) {
+	s.usec += 1000
+	s.event(EV_MSC, 4, int32(code)) // MSC_SCAN
+	s.event(EV_KEY, code, value)
+	s.event(EV_SYN, 0, 0)
+}
+
+func (s *evdevStream) press(codes ...uint16) {
+	for _, c := range codes {
+		s.key(c, KeyPressed)
+		s.key(c, KeyReleased)
+	}
+}
+
+func readAll(t *testing.T, r *EvdevReader) (evs []KeyEvent, scans []string) {
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return evs, scans
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		evs = append(evs, ev)
+		if ev.Scan != nil {
+			scans = append(scans, ev.Scan.Text)
+		}
+	}
+}
+
+func TestReadInputEvent(t *testing.T) {
+	for _, size := range []int{InputEventSize64, InputEventSize32} {
+		s := evdevStream{size: size, usec: 1700000000*1e6 + 250000}
+		s.event(EV_KEY, keyA, KeyRepeated)
+		ev, err := ReadInputEvent(&s.buf, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := InputEvent{Time: time.Unix(1700000000, 250000000), Type: EV_KEY, Code: keyA, Value: KeyRepeated}
+		if !ev.Time.Equal(want.Time) || ev.Type != want.Type || ev.Code != want.Code || ev.Value != want.Value {
+			t.Errorf("size %d: ReadInputEvent() = %+v, want %+v", size, ev, want)
+		}
+		if _, err := ReadInputEvent(&s.buf, size); err != io.EOF {
+			t.Errorf("size %d: error at the end = %v", size, err)
+		}
+	}
+	if _, err := ReadInputEvent(bytes.NewReader(make([]byte, 20)), 20); err == nil {
+		t.Error("ReadInputEvent() accepted size 20")
+	}
+	if _, err := ReadInputEvent(bytes.NewReader(make([]byte, 10)), InputEventSize64); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadInputEvent() of a short record = %v", err)
+	}
+}
+
+func TestEvdevReader(t *testing.T) {
+	s := evdevStream{size: InputEventSize64}
+	s.key(keyLeftShift, KeyPressed)
+	s.press(keyA)
+	s.key(keyLeftShift, KeyReleased)
+	s.press(key1, key2, keyY, keyUp, keyEnter)
+
+	tests := []struct {
+		layout *Layout
+		want   string
+	}{
+		{LayoutUS, "A12y"},
+		{LayoutDE, "A12z"},
+	}
+	for _, tt := range tests {
+		r := NewEvdevReader(bytes.NewReader(s.buf.Bytes()))
+		r.Layout = tt.layout
+		r.Device = "/dev/input/event3"
+		evs, scans := readAll(t, r)
+		if len(scans) != 1 || scans[0] != tt.want {
+			t.Errorf("%s: scans = %q, want [%q]", tt.layout.Name, scans, tt.want)
+		}
+		if len(evs) != 7 { // key downs only
+			t.Fatalf("%s: got %d events", tt.layout.Name, len(evs))
+		}
+		if evs[0].VkCode != winapi.VK_LSHIFT || evs[0].Rune != 0 || evs[0].Device != "/dev/input/event3" {
+			t.Errorf("%s: shift event = %+v", tt.layout.Name, evs[0])
+		}
+		if up := evs[5]; up.VkCode != winapi.VK_UP || up.ScanCode != 0x48 || up.Flags != winapi.LLKHF_EXTENDED {
+			t.Errorf("%s: up event = %+v", tt.layout.Name, up)
+		}
+	}
+}
+
+func TestEvdevReader32(t *testing.T) {
+	s := evdevStream{size: InputEventSize32}
+	s.press(key1, key2, keyKPEnter, keyEsc)
+	r := NewEvdevReader(&s.buf)
+	r.EventSize = InputEventSize32
+	if _, scans := readAll(t, r); len(scans) != 1 || scans[0] != "12" {
+		t.Errorf("scans = %q, want [\"12\"]", scans)
+	}
+}
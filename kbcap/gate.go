@@ -0,0 +1,94 @@
+package kbcap
+
+import (
+	"strings"
+	"time"
+)
+
+// GatedKey is a character key down held by a Gate, with what is needed to replay it.
+type GatedKey struct {
+	Keystroke
+	VkCode   uint32
+	ScanCode uint32
+	Flags    uint32
+}
+
+// Gate decides which character keys to swallow so scans don't leak into the
+// focused window. Every key is held until its burst is classified: a scan is
+// dropped, anything else is handed back for replay in the original order.
+// A burst ends on a terminator, on a gap over Classifier.MaxKeyInterval, or
+// when Tick sees no key for that long; without a Classifier or that interval
+// only the terminator ends it, and without a Classifier nothing is a scan.
+// Keys that produce no character and key ups should bypass the gate.
+//
+// Gate is not safe for concurrent use.
+type Gate struct {
+	Classifier  *Classifier
+	Terminators string
+
+	held []GatedKey
+	last time.Time
+}
+
+func NewGate(c *Classifier) *Gate {
+	return &Gate{Classifier: c, Terminators: "\r\n"}
+}
+
+// Feed reports whether k must be swallowed, and the keys to replay now.
+// When k is swallowed and replay is not empty, k is part of replay if it
+// does not start a new burst.
+func (g *Gate) Feed(k GatedKey) (swallow bool, replay []GatedKey) {
+	if len(g.held) > 0 && g.expired(k.Time) {
+		replay = g.take()
+	}
+	g.last = k.Time
+	if !strings.ContainsRune(g.Terminators, k.Rune) {
+		g.held = append(g.held, k)
+		return true, replay
+	}
+	if len(g.held) == 0 {
+		if len(replay) > 0 { // keep the order, k must follow the replay
+			return true, append(replay, k)
+		}
+		return false, nil
+	}
+	burst := g.take()
+	if g.Classifier != nil && g.Classifier.Classify(keystrokes(burst), k.Time) == SourceScanner {
+		return true, replay
+	}
+	return true, append(append(replay, burst...), k)
+}
+
+// Tick releases the held burst once no key came for MaxKeyInterval.
+func (g *Gate) Tick(now time.Time) []GatedKey {
+	if len(g.held) == 0 || !g.expired(now) {
+		return nil
+	}
+	return g.take()
+}
+
+// Held returns the number of keys waiting for a decision.
+func (g *Gate) Held() int {
+	return len(g.held)
+}
+
+func (g *Gate) expired(t time.Time) bool {
+	if g.Classifier == nil || g.Classifier.MaxKeyInterval <= 0 {
+		return false
+	}
+	return t.Sub(g.last) > g.Classifier.MaxKeyInterval
+}
+
+func (g *Gate) take() []GatedKey {
+	held := g.held
+	g.held = nil
+	return held
+}
+
+func keystrokes(keys []GatedKey) []Keystroke {
+	ks := make([]Keystroke, len(keys))
+	for i, k := range keys {
+		ks[i] = k.Keystroke
+	}
+	return ks
+}
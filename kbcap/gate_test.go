@@ -0,0 +1,117 @@
+package kbcap
+
+import (
+	"testing"
+	"time"
+)
+
+type gateTimeline struct {
+	g        *Gate
+	now      time.Time
+	replayed []rune
+	passed   []rune // keys not swallowed
+}
+
+func newGateTimeline(g *Gate) *gateTimeline {
+	return &gateTimeline{g: g, now: time.Unix(1700000000, 0)}
+}
+
+func (tl *gateTimeline) typeText(s string, step time.Duration) {
+	for _, r := range s {
+		tl.now = tl.now.Add(step)
+		swallow, replay := tl.g.Feed(GatedKey{Keystroke: Keystroke{Rune: r, Time: tl.now}})
+		if !swallow {
+			tl.passed = append(tl.passed, r)
+		}
+		tl.replay(replay)
+	}
+}
+
+func (tl *gateTimeline) tick(d time.Duration) {
+	tl.now = tl.now.Add(d)
+	tl.replay(tl.g.Tick(tl.now))
+}
+
+func (tl *gateTimeline) replay(keys []GatedKey) {
+	for _, k := range keys {
+		tl.replayed = append(tl.replayed, k.Rune)
+	}
+}
+
+func TestGate(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name           string
+		input          string
+		step           time.Duration
+		replay, passed string
+	}{
+		{"scan", "0123456789\r", 5 * ms, "", ""},
+		{"typing", "hi\r", 150 * ms, "hi\r", ""},
+		{"short burst", "ab\r", 5 * ms, "ab\r", ""},
+		{"slow burst", "0123456789\r", 50 * ms, "0123456789\r", ""},
+		{"terminator alone", "\r\n", 150 * ms, "", "\r\n"},
+	}
+	for _, tt := range tests {
+		tl := newGateTimeline(NewGate(NewClassifier()))
+		tl.typeText(tt.input, tt.step)
+		if string(tl.replayed) != tt.replay || string(tl.passed) != tt.passed {
+			t.Errorf("%s: replayed %q, passed %q, want %q, %q", tt.name, string(tl.replayed), string(tl.passed), tt.replay, tt.passed)
+		}
+		if n := tl.g.Held(); n != 0 {
+			t.Errorf("%s: %d keys held", tt.name, n)
+		}
+	}
+}
+
+func TestGateOrder(t *testing.T) {
+	tl := newGateTimeline(NewGate(NewClassifier()))
+	tl.typeText("ab", 150*time.Millisecond)
+	tl.typeText("0", 150*time.Millisecond)
+	tl.typeText("123456789\r", 5*time.Millisecond)
+	if string(tl.replayed) != "ab" {
+		t.Errorf("replayed %q, want \"ab\"", string(tl.replayed))
+	}
+}
+
+func TestGateTick(t *testing.T) {
+	tl := newGateTimeline(NewGate(NewClassifier()))
+	tl.typeText("ab", 5*time.Millisecond)
+	tl.tick(50 * time.Millisecond)
+	if len(tl.replayed) != 0 || tl.g.Held() != 2 {
+		t.Fatalf("replayed %q before the deadline", string(tl.replayed))
+	}
+	tl.tick(100 * time.Millisecond)
+	if string(tl.replayed) != "ab" || tl.g.Held() != 0 {
+		t.Errorf("replayed %q, want \"ab\"", string(tl.replayed))
+	}
+}
+
+func TestGateNoLimit(t *testing.T) {
+	// no Classifier: nothing expires and nothing is a scan
+	tl := newGateTimeline(&Gate{Terminators: "\r"})
+	tl.typeText("0123", 5*time.Millisecond)
+	tl.tick(time.Hour)
+	if len(tl.replayed) != 0 || tl.g.Held() != 4 {
+		t.Fatalf("replayed %q without a Classifier", string(tl.replayed))
+	}
+	tl.typeText("\r", time.Millisecond)
+	if string(tl.replayed) != "0123\r" {
+		t.Errorf("replayed %q, want \"0123\\r\"", string(tl.replayed))
+	}
+
+	// MaxKeyInterval 0 is no limit, the mean still decides
+	c := NewClassifier()
+	c.MaxKeyInterval = 0
+	tl = newGateTimeline(NewGate(c))
+	tl.typeText("01234", 5*time.Millisecond)
+	tl.tick(time.Hour)
+	if len(tl.replayed) != 0 {
+		t.Fatalf("replayed %q with MaxKeyInterval 0", string(tl.replayed))
+	}
+	tl.typeText("56789", 5*time.Millisecond)
+	tl.typeText("\r", 5*time.Millisecond)
+	if string(tl.replayed) != "0123456789\r" {
+		t.Errorf("replayed %q after an hour long gap", string(tl.replayed))
+	}
+}
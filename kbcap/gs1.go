@@ -0,0 +1,382 @@
+package kbcap
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GS is the FNC1 separator of variable length elements, scanners in keyboard
+// mode send it as Ctrl+].
+const GS = '\x1d'
+
+var (
+	ErrNotGS1        = errors.New("not a GS1 payload")
+	ErrGS1Syntax     = errors.New("invalid GS1 element")
+	ErrGS1CheckDigit = errors.New("invalid GS1 check digit")
+)
+
+// GS1 symbology identifiers, prefixed by scanners configured to send them.
+var gs1Symbologies = map[string]string{
+	"]C1": "GS1-128",
+	"]e0": "GS1 DataBar",
+	"]d2": "GS1 DataMatrix",
+	"]Q3": "GS1 QR Code",
+	"]J1": "GS1 DotCode",
+}
+
+type gs1Format int
+
+const (
+	gs1Numeric gs1Format = iota
+	gs1Alnum
+	gs1Date    // YYMMDD, DD may be 00
+	gs1Decimal // numeric, the last AI digit is the number of decimals
+	gs1Check   // numeric ending with a mod 10 check digit
+)
+
+// gs1AI describes an application identifier, max equals min for fixed lengths.
+type gs1AI struct {
+	title    string
+	min, max int
+	format   gs1Format
+}
+
+// gs1AIs holds the common application identifiers, decimal AIs like 310n are
+// keyed by their first three digits.
+var gs1AIs = map[string]gs1AI{
+	"00":   {"SSCC", 18, 18, gs1Check},
+	"01":   {"GTIN", 14, 14, gs1Check},
+	"02":   {"CONTENT", 14, 14, gs1Check},
+	"10":   {"BATCH/LOT", 1, 20, gs1Alnum},
+	"11":   {"PROD DATE", 6, 6, gs1Date},
+	"12":   {"DUE DATE", 6, 6, gs1Date},
+	"13":   {"PACK DATE", 6, 6, gs1Date},
+	"15":   {"BEST BEFORE", 6, 6, gs1Date},
+	"16":   {"SELL BY", 6, 6, gs1Date},
+	"17":   {"USE BY", 6, 6, gs1Date},
+	"20":   {"VARIANT", 2, 2, gs1Numeric},
+	"21":   {"SERIAL", 1, 20, gs1Alnum},
+	"22":   {"CPV", 1, 20, gs1Alnum},
+	"240":  {"ADDITIONAL ID", 1, 30, gs1Alnum},
+	"241":  {"CUST. PART No.", 1, 30, gs1Alnum},
+	"250":  {"SECONDARY SERIAL", 1, 30, gs1Alnum},
+	"30":   {"VAR. COUNT", 1, 8, gs1Numeric},
+	"310":  {"NET WEIGHT (kg)", 6, 6, gs1Decimal},
+	"311":  {"LENGTH (m)", 6, 6, gs1Decimal},
+	"312":  {"WIDTH (m)", 6, 6, gs1Decimal},
+	"313":  {"HEIGHT (m)", 6, 6, gs1Decimal},
+	"314":  {"AREA (m2)", 6, 6, gs1Decimal},
+	"315":  {"NET VOLUME (l)", 6, 6, gs1Decimal},
+	"316":  {"NET VOLUME (m3)", 6, 6, gs1Decimal},
+	"320":  {"NET WEIGHT (lb)", 6, 6, gs1Decimal},
+	"321":  {"LENGTH (in)", 6, 6, gs1Decimal},
+	"322":  {"LENGTH (ft)", 6, 6, gs1Decimal},
+	"323":  {"LENGTH (yd)", 6, 6, gs1Decimal},
+	"324":  {"WIDTH (in)", 6, 6, gs1Decimal},
+	"325":  {"WIDTH (ft)", 6, 6, gs1Decimal},
+	"326":  {"WIDTH (yd)", 6, 6, gs1Decimal},
+	"327":  {"HEIGHT (in)", 6, 6, gs1Decimal},
+	"328":  {"HEIGHT (ft)", 6, 6, gs1Decimal},
+	"329":  {"HEIGHT (yd)", 6, 6, gs1Decimal},
+	"330":  {"GROSS WEIGHT (kg)", 6, 6, gs1Decimal},
+	"331":  {"LENGTH (m), log", 6, 6, gs1Decimal},
+	"332":  {"WIDTH (m), log", 6, 6, gs1Decimal},
+	"333":  {"HEIGHT (m), log", 6, 6, gs1Decimal},
+	"334":  {"AREA (m2), log", 6, 6, gs1Decimal},
+	"335":  {"VOLUME (l), log", 6, 6, gs1Decimal},
+	"336":  {"VOLUME (m3), log", 6, 6, gs1Decimal},
+	"337":  {"KG PER m2", 6, 6, gs1Decimal},
+	"340":  {"GROSS WEIGHT (lb)", 6, 6, gs1Decimal},
+	"341":  {"LENGTH (in), log", 6, 6, gs1Decimal},
+	"342":  {"LENGTH (ft), log", 6, 6, gs1Decimal},
+	"343":  {"LENGTH (yd), log", 6, 6, gs1Decimal},
+	"344":  {"WIDTH (in), log", 6, 6, gs1Decimal},
+	"345":  {"WIDTH (ft), log", 6, 6, gs1Decimal},
+	"346":  {"WIDTH (yd), log", 6, 6, gs1Decimal},
+	"347":  {"HEIGHT (in), log", 6, 6, gs1Decimal},
+	"348":  {"HEIGHT (ft), log", 6, 6, gs1Decimal},
+	"349":  {"HEIGHT (yd), log", 6, 6, gs1Decimal},
+	"350":  {"AREA (in2)", 6, 6, gs1Decimal},
+	"351":  {"AREA (ft2)", 6, 6, gs1Decimal},
+	"352":  {"AREA (yd2)", 6, 6, gs1Decimal},
+	"353":  {"AREA (in2), log", 6, 6, gs1Decimal},
+	"354":  {"AREA (ft2), log", 6, 6, gs1Decimal},
+	"355":  {"AREA (yd2), log", 6, 6, gs1Decimal},
+	"356":  {"NET WEIGHT (t oz)", 6, 6, gs1Decimal},
+	"357":  {"NET VOLUME (oz)", 6, 6, gs1Decimal},
+	"360":  {"NET VOLUME (qt)", 6, 6, gs1Decimal},
+	"361":  {"NET VOLUME (g)", 6, 6, gs1Decimal},
+	"362":  {"VOLUME (qt), log", 6, 6, gs1Decimal},
+	"363":  {"VOLUME (g), log", 6, 6, gs1Decimal},
+	"364":  {"VOLUME (in3)", 6, 6, gs1Decimal},
+	"365":  {"VOLUME (ft3)", 6, 6, gs1Decimal},
+	"366":  {"VOLUME (yd3)", 6, 6, gs1Decimal},
+	"367":  {"VOLUME (in3), log", 6, 6, gs1Decimal},
+	"368":  {"VOLUME (ft3), log", 6, 6, gs1Decimal},
+	"369":  {"VOLUME (yd3), log", 6, 6, gs1Decimal},
+	"37":   {"COUNT", 1, 8, gs1Numeric},
+	"392":  {"PRICE", 1, 15, gs1Decimal},
+	"400":  {"ORDER NUMBER", 1, 30, gs1Alnum},
+	"410":  {"SHIP TO LOC", 13, 13, gs1Check},
+	"411":  {"BILL TO", 13, 13, gs1Check},
+	"412":  {"PURCHASE FROM", 13, 13, gs1Check},
+	"413":  {"SHIP FOR LOC", 13, 13, gs1Check},
+	"414":  {"LOC No.", 13, 13, gs1Check},
+	"415":  {"PAY TO", 13, 13, gs1Check},
+	"416":  {"PROD/SERV LOC", 13, 13, gs1Check},
+	"417":  {"PARTY", 13, 13, gs1Check},
+	"420":  {"SHIP TO POST", 1, 20, gs1Alnum},
+	"422":  {"ORIGIN", 3, 3, gs1Numeric},
+	"710":  {"NHRN PZN", 1, 20, gs1Alnum},
+	"711":  {"NHRN CIP", 1, 20, gs1Alnum},
+	"712":  {"NHRN CN", 1, 20, gs1Alnum},
+	"713":  {"NHRN DRN", 1, 20, gs1Alnum},
+	"714":  {"NHRN AIM", 1, 20, gs1Alnum},
+	"7003": {"EXPIRY TIME", 10, 10, gs1Numeric},
+	"8004": {"GIAI", 1, 30, gs1Alnum},
+	"8008": {"PROD TIME", 8, 12, gs1Numeric},
+	"90":   {"INTERNAL", 1, 30, gs1Alnum},
+	"91":   {"INTERNAL", 1, 90, gs1Alnum},
+	"92":   {"INTERNAL", 1, 90, gs1Alnum},
+	"93":   {"INTERNAL", 1, 90, gs1Alnum},
+	"94":   {"INTERNAL", 1, 90, gs1Alnum},
+	"95":   {"INTERNAL", 1, 90, gs1Alnum},
+	"96":   {"INTERNAL", 1, 90, gs1Alnum},
+	"97":   {"INTERNAL", 1, 90, gs1Alnum},
+	"98":   {"INTERNAL", 1, 90, gs1Alnum},
+	"99":   {"INTERNAL", 1, 90, gs1Alnum},
+}
+
+// gs1Predefined is the GS1 table of AI prefixes with a predefined length
+// (AI included), these elements are never followed by a separator. The
+// prefixes reserved without any AI (03, 04, 14, 18, 19) are left out.
+var gs1Predefined = map[string]int{
+	"00": 20, "01": 16, "02": 16,
+	"11": 8, "12": 8, "13": 8, "15": 8, "16": 8, "17": 8,
+	"20": 4, "31": 10, "32": 10, "33": 10, "34": 10, "35": 10, "36": 10, "41": 16,
+}
+
+// GS1Element is a decoded element, Value is a string, an int64 for plain
+// numbers, a float64 for decimal AIs or a time.Time for dates.
+type GS1Element struct {
+	AI    string
+	Title string
+	Raw   string
+	Value interface{}
+}
+
+type GS1 struct {
+	Symbology string // "GS1-128" etc, empty without symbology identifier
+	Elements  map[string]GS1Element
+	Order     []string // AIs in scan order
+}
+
+func (g *GS1) String(ai string) string {
+	return g.Elements[ai].Raw
+}
+
+func (g *GS1) GTIN() string {
+	return g.String("01")
+}
+
+func (g *GS1) Batch() string {
+	return g.String("10")
+}
+
+func (g *GS1) Serial() string {
+	return g.String("21")
+}
+
+func (g *GS1) Expiry() (time.Time, bool) {
+	t, ok := g.Elements["17"].Value.(time.Time)
+	return t, ok
+}
+
+// ParseGS1 parses a scanned GS1 element string, with or without symbology
+// identifier, elements separated by GS. The human readable form with AIs in
+// parentheses is accepted too. Dates use the GS1 century rule from now.
+func ParseGS1(s string) (*GS1, error) {
+	return parseGS1(s, time.Now())
+}
+
+func (s *Scan) GS1() (*GS1, error) {
+	return ParseGS1(s.Text)
+}
+
+func parseGS1(s string, now time.Time) (*GS1, error) {
+	g := &GS1{Elements: map[string]GS1Element{}}
+	if strings.HasPrefix(s, "]") {
+		if len(s) < 3 {
+			return nil, ErrNotGS1
+		}
+		name, ok := gs1Symbologies[s[:3]]
+		if !ok {
+			return nil, errors.Wrapf(ErrNotGS1, "symbology %q", s[:3])
+		}
+		g.Symbology = name
+		s = s[3:]
+	}
+	if strings.HasPrefix(s, "(") {
+		s = bracketedToGS(s)
+	}
+	s = strings.TrimLeft(s, string(GS))
+	if s == "" {
+		return nil, ErrNotGS1
+	}
+	for s != "" {
+		ai, def, err := lookupGS1AI(s)
+		if err != nil {
+			return nil, err
+		}
+		s = s[len(ai):]
+		var raw string
+		if n, ok := gs1Predefined[ai[:2]]; ok {
+			n -= len(ai)
+			if len(s) < n {
+				return nil, errors.Wrapf(ErrGS1Syntax, "(%s) too short", ai)
+			}
+			raw, s = s[:n], s[n:]
+			s = strings.TrimPrefix(s, string(GS))
+		} else if i := strings.IndexRune(s, GS); i >= 0 {
+			raw, s = s[:i], s[i+1:]
+		} else {
+			raw, s = s, ""
+		}
+		el, err := decodeGS1Element(ai, def, raw, now)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := g.Elements[ai]; dup {
+			return nil, errors.Wrapf(ErrGS1Syntax, "(%s) repeated", ai)
+		}
+		g.Elements[ai] = el
+		g.Order = append(g.Order, ai)
+	}
+	return g, nil
+}
+
+// bracketedToGS turns "(01)123(10)AB" into "01123<GS>10AB".
+func bracketedToGS(s string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(s[1:], "(") {
+		if i > 0 {
+			b.WriteRune(GS)
+		}
+		b.WriteString(strings.Replace(part, ")", "", 1))
+	}
+	return b.String()
+}
+
+func lookupGS1AI(s string) (string, gs1AI, error) {
+	for n := 2; n <= 4 && n <= len(s); n++ {
+		def, ok := gs1AIs[s[:n]]
+		if !ok {
+			continue
+		}
+		if def.format == gs1Decimal {
+			n++
+			if n > len(s) || !isDigits(s[n-1:n]) {
+				break
+			}
+		}
+		return s[:n], def, nil
+	}
+	end := 4
+	if len(s) < end {
+		end = len(s)
+	}
+	return "", gs1AI{}, errors.Wrapf(ErrGS1Syntax, "unknown AI at %q", s[:end])
+}
+
+func decodeGS1Element(ai string, def gs1AI, raw string, now time.Time) (GS1Element, error) {
+	el := GS1Element{AI: ai, Title: def.title, Raw: raw, Value: raw}
+	if len(raw) < def.min || len(raw) > def.max {
+		return el, errors.Wrapf(ErrGS1Syntax, "(%s) length %d", ai, len(raw))
+	}
+	if def.format == gs1Alnum {
+		for _, c := range raw {
+			if c < 0x21 || c > 0x7e {
+				return el, errors.Wrapf(ErrGS1Syntax, "(%s) character %q", ai, c)
+			}
+		}
+		return el, nil
+	}
+	if !isDigits(raw) {
+		return el, errors.Wrapf(ErrGS1Syntax, "(%s) not numeric", ai)
+	}
+	switch def.format {
+	case gs1Check:
+		if !ValidGS1CheckDigit(raw) {
+			return el, errors.Wrapf(ErrGS1CheckDigit, "(%s) %s", ai, raw)
+		}
+	case gs1Date:
+		t, err := parseGS1Date(raw, now)
+		if err != nil {
+			return el, errors.Wrapf(err, "(%s)", ai)
+		}
+		el.Value = t
+	case gs1Decimal:
+		n, _ := strconv.ParseInt(raw, 10, 64)
+		el.Value = float64(n) / math.Pow10(int(ai[len(ai)-1]-'0'))
+	default:
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			el.Value = n
+		}
+	}
+	return el, nil
+}
+
+// parseGS1Date parses YYMMDD, a 00 day is the last day of the month. The
+// century puts the year within 49 years before and 50 years after now.
+func parseGS1Date(s string, now time.Time) (time.Time, error) {
+	yy, _ := strconv.Atoi(s[:2])
+	mm, _ := strconv.Atoi(s[2:4])
+	dd, _ := strconv.Atoi(s[4:6])
+	year := now.Year() - now.Year()%100 + yy
+	switch diff := yy - now.Year()%100; {
+	case diff >= 51:
+		year -= 100
+	case diff <= -50:
+		year += 100
+	}
+	if mm < 1 || mm > 12 {
+		return time.Time{}, errors.Wrapf(ErrGS1Syntax, "date %s", s)
+	}
+	last := time.Date(year, time.Month(mm)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if dd == 0 {
+		dd = last
+	} else if dd > last {
+		return time.Time{}, errors.Wrapf(ErrGS1Syntax, "date %s", s)
+	}
+	return time.Date(year, time.Month(mm), dd, 0, 0, 0, 0, time.UTC), nil
+}
+
+// ValidGS1CheckDigit checks the mod 10 check digit ending s, as used by
+// GTIN, SSCC and GLN.
+func ValidGS1CheckDigit(s string) bool {
+	if len(s) < 2 || !isDigits(s) {
+		return false
+	}
+	sum := 0
+	for i := len(s) - 2; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if (len(s)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(s[len(s)-1]-'0')
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
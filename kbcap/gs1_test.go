@@ -0,0 +1,170 @@
+package kbcap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var gs1Now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseGS1(t *testing.T) {
+	g, err := parseGS1("]d201095060001343521720122510ABC123\x1d21XYZ", gs1Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Symbology != "GS1 DataMatrix" || g.GTIN() != "09506000134352" || g.Batch() != "ABC123" || g.Serial() != "XYZ" {
+		t.Errorf("parseGS1() = %+v", g)
+	}
+	if e, ok := g.Expiry(); !ok || !e.Equal(date(2020, 12, 25)) {
+		t.Errorf("Expiry() = %v, %v", e, ok)
+	}
+	if want := []string{"01", "17", "10", "21"}; !reflect.DeepEqual(g.Order, want) {
+		t.Errorf("Order = %q, want %q", g.Order, want)
+	}
+
+	g, err = parseGS1("(01)09506000134352(17)291200(3103)001250(10)L1", gs1Now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := g.Expiry(); !e.Equal(date(2029, 12, 31)) {
+		t.Errorf("Expiry() = %v", e)
+	}
+	if v := g.Elements["3103"].Value; v != 1.25 {
+		t.Errorf("(3103) = %v", v)
+	}
+
+	for _, s := range []string{"]C1\x1d0109506000134352", "]Q30109506000134352\x1d", "]e00109506000134352"} {
+		if _, err := parseGS1(s, gs1Now); err != nil {
+			t.Errorf("parseGS1(%q): %v", s, err)
+		}
+	}
+}
+
+func TestParseGS1Formats(t *testing.T) {
+	tests := []struct {
+		in    string
+		ai    string
+		value interface{}
+	}{
+		// check digit
+		{"00106141411234567897", "00", "106141411234567897"},
+		{"0109506000134352", "01", "09506000134352"},
+		{"0209506000134352", "02", "09506000134352"},
+		{"4104006381333931", "410", "4006381333931"},
+		{"4174006381333931", "417", "4006381333931"},
+		// date, 00 is the last day of the month
+		{"11250228", "11", date(2025, 2, 28)},
+		{"15240200", "15", date(2024, 2, 29)},
+		{"17751231", "17", date(2075, 12, 31)},
+		{"17770101", "17", date(1977, 1, 1)},
+		// numeric
+		{"2001", "20", int64(1)},
+		{"3012345678", "30", int64(12345678)},
+		{"375", "37", int64(5)},
+		{"422276", "422", int64(276)},
+		{"70031912312359", "7003", int64(1912312359)},
+		// decimal, the last AI digit is the number of decimals
+		{"3100001234", "3100", 1234.0},
+		{"3112001234", "3112", 12.34},
+		{"3405012345", "3405", 0.12345},
+		{"3571000100", "3571", 10.0},
+		{"3691123456", "3691", 12345.6},
+		{"39221099", "3922", 10.99},
+		// alphanumeric
+		{"10ABC-12/3", "10", "ABC-12/3"},
+		{"21!\"%&'()*+,-./:;<=>?_", "21", "!\"%&'()*+,-./:;<=>?_"},
+		{"8004GIAI-0001", "8004", "GIAI-0001"},
+		{"91internal", "91", "internal"},
+	}
+	for _, tt := range tests {
+		g, err := parseGS1(tt.in, gs1Now)
+		if err != nil {
+			t.Errorf("parseGS1(%q): %v", tt.in, err)
+			continue
+		}
+		el, ok := g.Elements[tt.ai]
+		if !ok || len(g.Order) != 1 {
+			t.Errorf("parseGS1(%q) = %v, want only (%s)", tt.in, g.Order, tt.ai)
+			continue
+		}
+		if !reflect.DeepEqual(el.Value, tt.value) {
+			t.Errorf("parseGS1(%q) (%s) = %#v, want %#v", tt.in, tt.ai, el.Value, tt.value)
+		}
+	}
+}
+
+func TestParseGS1Errors(t *testing.T) {
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{"", ErrNotGS1},
+		{"]C0123", ErrNotGS1},
+		{"]C", ErrNotGS1},
+		{"]C1\x1d", ErrNotGS1},
+		{"0109506000134353", ErrGS1CheckDigit},
+		{"00106141411234567890", ErrGS1CheckDigit},
+		{"01095060001343", ErrGS1Syntax},                // too short
+		{"0309506000134352", ErrGS1Syntax},              // reserved AI
+		{"1712133110ABC", ErrGS1Syntax},                 // month 13
+		{"17250230", ErrGS1Syntax},                      // 30 February
+		{"20AB", ErrGS1Syntax},                          // not numeric
+		{"310A001234", ErrGS1Syntax},                    // decimal digit
+		{"31000012", ErrGS1Syntax},                      // short decimal
+		{"10ABC\x1d10DEF", ErrGS1Syntax},                // repeated
+		{"10" + string(make([]byte, 21)), ErrGS1Syntax}, // control characters
+		{"10ABCDEFGHIJKLMNOPQRSTU", ErrGS1Syntax},       // 21 characters
+		{"240PART 1", ErrGS1Syntax},                     // space
+		{"4104006381333932", ErrGS1CheckDigit},
+	}
+	for _, tt := range tests {
+		if _, err := parseGS1(tt.in, gs1Now); !errors.Is(err, tt.err) {
+			t.Errorf("parseGS1(%q) error = %v, want %v", tt.in, err, tt.err)
+		}
+	}
+}
+
+// TestGS1Tables checks that the AIs agree with the predefined lengths.
+func TestGS1Tables(t *testing.T) {
+	used := map[string]bool{}
+	for ai, def := range gs1AIs {
+		n, ok := gs1Predefined[ai[:2]]
+		if !ok {
+			continue
+		}
+		used[ai[:2]] = true
+		length := len(ai)
+		if def.format == gs1Decimal {
+			length++
+		}
+		if def.min != def.max || length+def.max != n {
+			t.Errorf("(%s) length %d-%d, predefined %d", ai, def.min, def.max, n-length)
+		}
+	}
+	for prefix := range gs1Predefined {
+		if !used[prefix] {
+			t.Errorf("no AI for predefined prefix %s", prefix)
+		}
+	}
+}
+
+func TestValidGS1CheckDigit(t *testing.T) {
+	for s, want := range map[string]bool{
+		"4006381333931":      true,
+		"09506000134352":     true,
+		"106141411234567897": true,
+		"4006381333932":      false,
+		"0":                  false,
+		"40063813339x1":      false,
+	} {
+		if got := ValidGS1CheckDigit(s); got != want {
+			t.Errorf("ValidGS1CheckDigit(%q) = %v", s, got)
+		}
+	}
+}
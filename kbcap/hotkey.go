@@ -0,0 +1,143 @@
+package kbcap
+
+import (
+	"strings"
+
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// vkAliases are names ParseHotkey accepts besides those of winapi.VKName,
+// lower case.
+var vkAliases = map[string]uint32{
+	"enter":       winapi.VK_RETURN,
+	"esc":         winapi.VK_ESCAPE,
+	"backspace":   winapi.VK_BACK,
+	"bksp":        winapi.VK_BACK,
+	"del":         winapi.VK_DELETE,
+	"ins":         winapi.VK_INSERT,
+	"pageup":      winapi.VK_PRIOR,
+	"pgup":        winapi.VK_PRIOR,
+	"pagedown":    winapi.VK_NEXT,
+	"pgdn":        winapi.VK_NEXT,
+	"printscreen": winapi.VK_SNAPSHOT,
+	"prtsc":       winapi.VK_SNAPSHOT,
+	"capslock":    winapi.VK_CAPITAL,
+	"ctrl":        winapi.VK_CONTROL,
+	"alt":         winapi.VK_MENU,
+	"plus":        winapi.VK_OEM_PLUS,
+	"+":           winapi.VK_OEM_PLUS,
+	"=":           winapi.VK_OEM_PLUS,
+	"minus":       winapi.VK_OEM_MINUS,
+	"-":           winapi.VK_OEM_MINUS,
+	"comma":       winapi.VK_OEM_COMMA,
+	",":           winapi.VK_OEM_COMMA,
+	"period":      winapi.VK_OEM_PERIOD,
+	".":           winapi.VK_OEM_PERIOD,
+}
+
+// hotkeyKeyName is the winapi.VKName of vk without its VK_ prefix, such as
+// "O", "F5", "NUMPAD3" or "OEM_PLUS".
+func hotkeyKeyName(vk uint32) string {
+	return strings.TrimPrefix(winapi.VKName(vk), "VK_")
+}
+
+// parseHotkeyKey accepts the names of hotkeyKeyName and winapi.VKName, case
+// insensitive, "Num3" for the numpad digits and the aliases.
+func parseHotkeyKey(name string) (uint32, bool) {
+	if vk, ok := winapi.ParseVK(name); ok {
+		return vk, true
+	}
+	if vk, ok := winapi.ParseVK("VK_" + name); ok {
+		return vk, true
+	}
+	lower := strings.ToLower(name)
+	if len(lower) == 4 && strings.HasPrefix(lower, "num") && lower[3] >= '0' && lower[3] <= '9' {
+		return winapi.VK_NUMPAD0 + uint32(lower[3]-'0'), true
+	}
+	vk, ok := vkAliases[lower]
+	return vk, ok
+}
+
+var hotkeyModifiers = []struct {
+	mod   uint32
+	names []string // first is the display name
+}{
+	{winapi.MOD_CONTROL, []string{"Ctrl", "Control"}},
+	{winapi.MOD_ALT, []string{"Alt"}},
+	{winapi.MOD_SHIFT, []string{"Shift"}},
+	{winapi.MOD_WIN, []string{"Win", "Super", "Meta"}},
+}
+
+// Hotkey is a RegisterHotKey combination. It reads and writes as text such
+// as "Ctrl+Alt+O", so it can be used in settings files.
+type Hotkey struct {
+	Modifiers uint32 // MOD_*
+	VK        uint32
+}
+
+var (
+	ErrInvalidHotkey        = errors.New("invalid hotkey")
+	ErrHotkeyManagerStopped = errors.New("hotkey manager not running")
+)
+
+// ParseHotkey parses modifiers and a key name joined by '+', in any order
+// and case, such as "ctrl+alt+o", "Shift+F5", "Ctrl+VK_NEXT" or "Ctrl++"
+// (Ctrl+OEM_PLUS).
+func ParseHotkey(s string) (Hotkey, error) {
+	var hk Hotkey
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, "+")
+	if strings.HasSuffix(s, "++") { // the + key itself
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if mod, ok := parseHotkeyModifier(part); ok && i < len(parts)-1 {
+			hk.Modifiers |= mod
+			continue
+		}
+		if i != len(parts)-1 {
+			return Hotkey{}, errors.Wrapf(ErrInvalidHotkey, "%q: unknown modifier %q", s, part)
+		}
+		vk, ok := parseHotkeyKey(part)
+		if !ok {
+			return Hotkey{}, errors.Wrapf(ErrInvalidHotkey, "%q: unknown key %q", s, part)
+		}
+		hk.VK = vk
+	}
+	return hk, nil
+}
+
+func parseHotkeyModifier(name string) (uint32, bool) {
+	for _, m := range hotkeyModifiers {
+		for _, n := range m.names {
+			if strings.EqualFold(name, n) {
+				return m.mod, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// String returns the display name, such as "Ctrl+Alt+O" or "Shift+NEXT".
+func (hk Hotkey) String() string {
+	var b strings.Builder
+	for _, m := range hotkeyModifiers {
+		if hk.Modifiers&m.mod != 0 {
+			b.WriteString(m.names[0])
+			b.WriteByte('+')
+		}
+	}
+	b.WriteString(hotkeyKeyName(hk.VK))
+	return b.String()
+}
+
+func (hk Hotkey) MarshalText() ([]byte, error) {
+	return []byte(hk.String()), nil
+}
+
+func (hk *Hotkey) UnmarshalText(text []byte) (err error) {
+	*hk, err = ParseHotkey(string(text))
+	return
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package kbcap
+
+import "github.com/FxStar/winapi"
+
+// HotkeyManager needs RegisterHotKey, on other platforms Start fails with
+// winapi.ErrNotSupported.
+type HotkeyManager struct {
+	NoRepeat bool
+}
+
+func NewHotkeyManager() *HotkeyManager {
+	return &HotkeyManager{}
+}
+
+func (m *HotkeyManager) Start() error {
+	return winapi.ErrNotSupported
+}
+
+func (m *HotkeyManager) Register(hk Hotkey, fn func(Hotkey)) (int, error) {
+	return 0, ErrHotkeyManagerStopped
+}
+
+func (m *HotkeyManager) Unregister(id int) error {
+	return ErrHotkeyManagerStopped
+}
+
+func (m *HotkeyManager) Close() error {
+	return nil
+}
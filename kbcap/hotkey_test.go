@@ -0,0 +1,78 @@
+package kbcap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+func TestParseHotkey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Hotkey
+		name string
+	}{
+		{"ctrl+alt+o", Hotkey{winapi.MOD_CONTROL | winapi.MOD_ALT, 'O'}, "Ctrl+Alt+O"},
+		{"Alt + Ctrl + O", Hotkey{winapi.MOD_CONTROL | winapi.MOD_ALT, 'O'}, "Ctrl+Alt+O"},
+		{"Shift+F5", Hotkey{winapi.MOD_SHIFT, winapi.VK_F5}, "Shift+F5"},
+		{"super+shift+1", Hotkey{winapi.MOD_WIN | winapi.MOD_SHIFT, '1'}, "Shift+Win+1"},
+		{"Ctrl++", Hotkey{winapi.MOD_CONTROL, winapi.VK_OEM_PLUS}, "Ctrl+OEM_PLUS"},
+		{"Ctrl+oem_plus", Hotkey{winapi.MOD_CONTROL, winapi.VK_OEM_PLUS}, "Ctrl+OEM_PLUS"},
+		{"win+num3", Hotkey{winapi.MOD_WIN, winapi.VK_NUMPAD3}, "Win+NUMPAD3"},
+		{"Ctrl+0x97", Hotkey{winapi.MOD_CONTROL, 0x97}, "Ctrl+0x97"},
+		{"PgUp", Hotkey{0, winapi.VK_PRIOR}, "PRIOR"},
+		{"Ctrl+VK_NEXT", Hotkey{winapi.MOD_CONTROL, winapi.VK_NEXT}, "Ctrl+NEXT"},
+		{"Control+Enter", Hotkey{winapi.MOD_CONTROL, winapi.VK_RETURN}, "Ctrl+RETURN"},
+	}
+	for _, tt := range tests {
+		hk, err := ParseHotkey(tt.in)
+		if err != nil {
+			t.Errorf("ParseHotkey(%q): %v", tt.in, err)
+			continue
+		}
+		if hk != tt.want {
+			t.Errorf("ParseHotkey(%q) = %+v, want %+v", tt.in, hk, tt.want)
+		}
+		if s := hk.String(); s != tt.name {
+			t.Errorf("ParseHotkey(%q).String() = %q, want %q", tt.in, s, tt.name)
+		}
+	}
+}
+
+func TestParseHotkeyInvalid(t *testing.T) {
+	for _, s := range []string{"", "Hyper+O", "Ctrl+", "Ctrl+NoSuchKey", "O+Ctrl", "Ctrl+0x100", "Ctrl+0x0"} {
+		if _, err := ParseHotkey(s); !errors.Is(err, ErrInvalidHotkey) {
+			t.Errorf("ParseHotkey(%q) error = %v", s, err)
+		}
+	}
+}
+
+// TestHotkeyNames checks that every key name parses back.
+func TestHotkeyNames(t *testing.T) {
+	for vk := uint32(1); vk <= 0xFF; vk++ {
+		hk := Hotkey{winapi.MOD_ALT, vk}
+		got, err := ParseHotkey(hk.String())
+		if err != nil || got != hk {
+			t.Errorf("ParseHotkey(%q) = %+v, %v, want VK 0x%02X", hk.String(), got, err, vk)
+		}
+	}
+}
+
+func TestHotkeyJSON(t *testing.T) {
+	var cfg map[string]Hotkey
+	if err := json.Unmarshal([]byte(`{"open":"Ctrl+Alt+O","help":"F1"}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg["open"] != (Hotkey{winapi.MOD_CONTROL | winapi.MOD_ALT, 'O'}) || cfg["help"] != (Hotkey{0, winapi.VK_F1}) {
+		t.Errorf("Unmarshal() = %+v", cfg)
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil || string(b) != `{"help":"F1","open":"Ctrl+Alt+O"}` {
+		t.Errorf("Marshal() = %s, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`{"open":"Hyper+O"}`), &cfg); !errors.Is(err, ErrInvalidHotkey) {
+		t.Errorf("Unmarshal() error = %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package kbcap
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/ui"
+	"github.com/pkg/errors"
+)
+
+// HotkeyManager registers hotkeys from a ui.UIThread of its own and calls their
+// handlers on WM_HOTKEY. Handlers run in their own goroutine and may register
+// or unregister hotkeys.
+//
+//	m := NewHotkeyManager()
+//	if err := m.Start(); err != nil {
+//	}
+//	defer m.Close()
+//	hk, _ := ParseHotkey("Ctrl+Alt+O")
+//	id, err := m.Register(hk, func(Hotkey) { ... })
+type HotkeyManager struct {
+	NoRepeat bool // register with MOD_NOREPEAT
+
+	thread *ui.UIThread
+
+	mu       sync.Mutex
+	handlers map[int]hotkeyHandler
+	nextID   int
+}
+
+type hotkeyHandler struct {
+	hotkey Hotkey
+	fn     func(Hotkey)
+}
+
+func NewHotkeyManager() *HotkeyManager {
+	m := &HotkeyManager{
+		thread:   ui.NewUIThread(nil),
+		handlers: map[int]hotkeyHandler{},
+		nextID:   1,
+	}
+	m.thread.ThreadMessage = m.threadMessage
+	return m
+}
+
+func (m *HotkeyManager) Start() error {
+	err := m.thread.Start(context.Background())
+	if err == ui.ErrRunning {
+		return nil
+	}
+	return err
+}
+
+// Register registers hk system wide, it returns the id given to the handler.
+func (m *HotkeyManager) Register(hk Hotkey, fn func(Hotkey)) (id int, err error) {
+	mods := hk.Modifiers
+	if m.NoRepeat {
+		mods |= winapi.MOD_NOREPEAT
+	}
+	err = m.call(func() error {
+		m.mu.Lock()
+		id = m.nextID
+		m.nextID++
+		m.mu.Unlock()
+		if err := winapi.RegisterHotKey(0, id, winapi.UINT(mods), winapi.UINT(hk.VK)); err != nil {
+			return errors.Wrapf(err, "register hotkey %s failed", hk)
+		}
+		m.mu.Lock()
+		m.handlers[id] = hotkeyHandler{hotkey: hk, fn: fn}
+		m.mu.Unlock()
+		return nil
+	})
+	return
+}
+
+func (m *HotkeyManager) Unregister(id int) error {
+	return m.call(func() error {
+		m.mu.Lock()
+		delete(m.handlers, id)
+		m.mu.Unlock()
+		return winapi.UnregisterHotKey(0, id)
+	})
+}
+
+// Close unregisters every hotkey and stops the thread.
+func (m *HotkeyManager) Close() error {
+	m.call(func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for id := range m.handlers {
+			winapi.UnregisterHotKey(0, id)
+			delete(m.handlers, id)
+		}
+		return nil
+	})
+	return m.thread.Stop()
+}
+
+// call runs fn on the manager thread, RegisterHotKey binds the hotkey to the
+// message queue of the calling thread.
+func (m *HotkeyManager) call(fn func() error) error {
+	err := m.thread.Invoke(fn)
+	if err == ui.ErrStopped {
+		return ErrHotkeyManagerStopped
+	}
+	return err
+}
+
+// threadMessage runs on the manager thread, the hotkeys registered without a
+// window are posted to it.
+func (m *HotkeyManager) threadMessage(msg *winapi.Msg) {
+	if msg.Message != winapi.WM_HOTKEY {
+		return
+	}
+	m.mu.Lock()
+	h, ok := m.handlers[int(msg.Wparam)]
+	m.mu.Unlock()
+	if ok && h.fn != nil {
+		go h.fn(h.hotkey)
+	}
+}
@@ -4,153 +4,100 @@ package kbcap
 //           https://gist.github.com/sbarratt/3077d5f51288b39665350dc2b9e19694
 
 import (
-	"bytes"
+	"context"
 	"log"
-	"syscall"
 	"time"
-	"unsafe"
 
 	"github.com/FxStar/winapi"
-	"github.com/pkg/errors"
-)
-
-// String returns a human-friendly display name of the hotkey
-// such as "Hotkey[Id: 1, Alt+Ctrl+O]"
-var (
-	user32 = syscall.NewLazyDLL("user32.dll")
-
-	procSetWindowsHookEx    = user32.NewProc("SetWindowsHookExW")
-	procCallNextHookEx      = user32.NewProc("CallNextHookEx")
-	procUnhookWindowsHookEx = user32.NewProc("UnhookWindowsHookEx")
 )
 
+// The hook types and constants are those of the winapi package, kept here
+// for existing callers.
 const (
-	WH_KEYBOARD_LL = 13
-	WH_KEYBOARD    = 2
-	WM_KEYDOWN     = 256
-	WM_SYSKEYDOWN  = 260
-	WM_KEYUP       = 257
-	WM_SYSKEYUP    = 261
-	WM_KEYFIRST    = 256
-	WM_KEYLAST     = 264
-	PM_NOREMOVE    = 0x000
-	PM_REMOVE      = 0x001
-	PM_NOYIELD     = 0x002
-	WM_LBUTTONDOWN = 513
-	WM_RBUTTONDOWN = 516
+	WH_KEYBOARD_LL = winapi.WH_KEYBOARD_LL
+	WH_KEYBOARD    = winapi.WH_KEYBOARD
+	WM_KEYDOWN     = winapi.WM_KEYDOWN
+	WM_SYSKEYDOWN  = winapi.WM_SYSKEYDOWN
+	WM_KEYUP       = winapi.WM_KEYUP
+	WM_SYSKEYUP    = winapi.WM_SYSKEYUP
+	WM_KEYFIRST    = winapi.WM_KEYFIRST
+	WM_KEYLAST     = winapi.WM_KEYLAST
+	PM_NOREMOVE    = winapi.PM_NOREMOVE
+	PM_REMOVE      = winapi.PM_REMOVE
+	PM_NOYIELD     = winapi.PM_NOYIELD
+	WM_LBUTTONDOWN = winapi.WM_LBUTTONDOWN
+	WM_RBUTTONDOWN = winapi.WM_RBUTTONDOWN
 	NULL           = 0
 )
 
 type (
 	DWORD     = uint32
-	WPARAM    uintptr
-	LPARAM    uintptr
-	LRESULT   uintptr
-	HANDLE    uintptr
-	HINSTANCE HANDLE
-	HHOOK     HANDLE
-	HWND      HANDLE
+	WPARAM    = winapi.WPARAM
+	LPARAM    = winapi.LPARAM
+	LRESULT   = winapi.LRESULT
+	HANDLE    = winapi.HANDLE
+	HINSTANCE = winapi.HINSTANCE
+	HHOOK     = winapi.HHOOK
+	HWND      = winapi.HWND
 )
 
-type HOOKPROC func(int, WPARAM, LPARAM) LRESULT
-
-type KBDLLHOOKSTRUCT struct {
-	VkCode      DWORD
-	ScanCode    DWORD
-	Flags       DWORD
-	Time        DWORD
-	DwExtraInfo uintptr
-}
-
-func SetWindowsHookEx(idHook int, lpfn HOOKPROC, hMod HINSTANCE, dwThreadId DWORD) (HHOOK, error) {
-	ret, _, err := procSetWindowsHookEx.Call(uintptr(idHook), uintptr(syscall.NewCallback(lpfn)), uintptr(hMod), uintptr(dwThreadId))
-	if ret == 0 {
-		return 0, err
-	}
-	return HHOOK(ret), nil
-}
-
-func (hhk HHOOK) CallNextHookEx(nCode int, wParam WPARAM, lParam LPARAM) LRESULT {
-	ret, _, _ := procCallNextHookEx.Call(uintptr(hhk), uintptr(nCode), uintptr(wParam), uintptr(lParam))
-	return LRESULT(ret)
-}
-
-func (hhk *HHOOK) UnhookWindowsHookEx() bool {
-	ret, _, _ := procUnhookWindowsHookEx.Call(uintptr(*hhk))
-	*hhk = 0
-	return ret != 0
-}
+type HOOKPROC = winapi.HOOKPROC
 
-func GetAnyMessage() { // block
-	winapi.GetMessage(nil, 0, 0, 0)
-}
+type KBDLLHOOKSTRUCT = winapi.KBDLLHOOKSTRUCT
 
-func CodeToChar(hookStruct *KBDLLHOOKSTRUCT) (byte, bool) {
-	keyStates, _ := winapi.GetKeyboardState()
-	keyStates[winapi.VK_SHIFT] = byte(winapi.GetKeyState(winapi.VK_SHIFT))
-	keyStates[winapi.VK_CAPITAL] = byte(winapi.GetKeyState(winapi.VK_CAPITAL))
-	var char uint16
-	n := winapi.ToAscii(hookStruct.VkCode, hookStruct.ScanCode, keyStates, &char, 0)
-	return byte(char), n == 1
-}
+// replayMarker tags the events replayKeys injects, in dwExtraInfo.
+const replayMarker = 0x4b424350 // "KBCP"
 
-var MaxUpdateInterval time.Duration = 3 * time.Second
+// MaxUpdateInterval is the Assembler Timeout of MonitorKeyboard, and Debug
+// makes MonitorKeyboard and MonitorScans log the keys and lines they get.
+// Monitor and Assembler take their settings from their own fields.
+var MaxUpdateInterval time.Duration = DefaultTimeout
 var Debug = false
 
 func MonitorKeyboard(callback func(string), codeCallback func(byte)) error {
-	var buf bytes.Buffer
-	buf.Grow(128)
-	lastUpdate := time.Now()
+	var lineCallback func(*Scan)
+	if callback != nil {
+		lineCallback = func(scan *Scan) { callback(scan.Text) }
+	}
+	asm := NewAssembler()
+	asm.Timeout = MaxUpdateInterval
+	return MonitorScans(asm, nil, lineCallback, codeCallback)
+}
 
-	mod, err := winapi.GetModuleHandle("")
-	if err != nil {
-		return errors.Wrap(err, "get module handle failed")
+// MonitorScans is MonitorKeyboard with a caller configured Assembler, set
+// asm.Classifier to only get scanner bursts. With a gate, character keys are
+// withheld from the focused window until the gate releases them, those of
+// scans are never delivered. It blocks until the hook fails.
+func MonitorScans(asm *Assembler, gate *Gate, callback func(*Scan), codeCallback func(byte)) error {
+	m := NewMonitor()
+	m.Assembler, m.Gate = asm, gate
+	if err := m.Start(context.Background()); err != nil {
+		return err
 	}
-	var keyboardHook HHOOK
-	keyboardHook, err = SetWindowsHookEx(WH_KEYBOARD_LL,
-		(HOOKPROC)(func(nCode int, wparam WPARAM, lparam LPARAM) LRESULT {
-			if nCode == 0 && wparam == WM_KEYDOWN {
-				kbdstruct := (*KBDLLHOOKSTRUCT)(unsafe.Pointer(lparam))
-				if Debug {
-					log.Printf("get code %d", kbdstruct.VkCode)
-				}
-				if codeCallback != nil {
-					codeCallback(byte(kbdstruct.VkCode))
-				}
-				if b, ok := CodeToChar(kbdstruct); ok {
-					if time.Since(lastUpdate) > MaxUpdateInterval && buf.Len() > 0 {
-						buf.Reset()
-						if Debug {
-							log.Printf("exceed max update interval of %v, reset", MaxUpdateInterval)
-						}
-					}
-					lastUpdate = time.Now()
-					if b != '\n' && b != '\r' {
-						buf.WriteByte(b)
-						if Debug {
-							log.Printf("key pressed: '%c' l=%d", b, buf.Len())
-						}
-					} else if buf.Len() > 0 {
-						if callback != nil {
-							callback(buf.String())
-						}
-						if Debug {
-							log.Printf("get line: '%s'", buf.String())
-						}
-						buf.Reset()
-					}
+	if Debug {
+		log.Printf("keyboard monitoring...")
+	}
+	for {
+		select {
+		case ev := <-m.Events():
+			if Debug {
+				log.Printf("get code %d", ev.VkCode)
+				if ev.Scan != nil {
+					log.Printf("get line: '%s'", ev.Scan.Text)
 				}
 			}
-			return keyboardHook.CallNextHookEx(nCode, wparam, lparam)
-		}), HINSTANCE(mod), 0)
-	if err != nil {
-		return errors.Wrap(err, "set window hook failed")
-	}
-
-	log.Printf("keyboard monitoring...")
-	defer keyboardHook.UnhookWindowsHookEx()
-	for { // should in the same routine as SetWindowsHookEx
-		GetAnyMessage() // may block infinitely, while PeekMessage will consume more CPU or less responsive.
+			if codeCallback != nil {
+				codeCallback(byte(ev.VkCode))
+			}
+			if ev.Scan != nil && callback != nil {
+				callback(ev.Scan)
+			}
+		case err := <-m.Errors():
+			if Debug {
+				log.Printf("keyboard monitor: %v", err)
+			}
+		case <-m.Done():
+			return m.Err()
+		}
 	}
-	return nil
 }
@@ -0,0 +1,54 @@
+package kbcap
+
+import (
+	"syscall"
+
+	"github.com/FxStar/winapi"
+)
+
+func SetWindowsHookEx(idHook int, lpfn HOOKPROC, hMod HINSTANCE, dwThreadId DWORD) (HHOOK, error) {
+	return winapi.SetWindowsHookEx(idHook, syscall.NewCallback(lpfn), hMod, dwThreadId)
+}
+
+// GetAnyMessage blocks until a message arrives for the calling thread, which
+// must be the one that installed the hooks. Use a ui.UIThread for a real loop.
+func GetAnyMessage() {
+	var msg winapi.Msg
+	winapi.GetMessage(&msg, 0, 0, 0)
+}
+
+func CodeToChar(hookStruct *KBDLLHOOKSTRUCT) (byte, bool) {
+	keyStates, _ := winapi.GetKeyboardState()
+	keyStates[winapi.VK_SHIFT] = byte(winapi.GetKeyState(winapi.VK_SHIFT))
+	keyStates[winapi.VK_CAPITAL] = byte(winapi.GetKeyState(winapi.VK_CAPITAL))
+	var char uint16
+	n := winapi.ToAscii(hookStruct.VkCode, hookStruct.ScanCode, keyStates, &char, 0)
+	return byte(char), n == 1
+}
+
+// replayKeys injects keys released by a Gate. Characters are sent as unicode
+// input so they don't depend on the modifier state, which already went through.
+func replayKeys(keys []GatedKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	inputs := make([]winapi.KeyboardInput, 0, len(keys)*2)
+	for _, k := range keys {
+		ki := winapi.KEYBDINPUT{ExtraInfo: replayMarker}
+		if k.Rune == '\r' || k.Rune == '\n' {
+			ki.Vk = winapi.WORD(k.VkCode)
+			ki.Scan = winapi.WORD(k.ScanCode)
+			if k.Flags&winapi.LLKHF_EXTENDED != 0 {
+				ki.Flags = winapi.KEYEVENTF_EXTENDEDKEY
+			}
+		} else {
+			ki.Scan = winapi.WORD(k.Rune)
+			ki.Flags = winapi.KEYEVENTF_UNICODE
+		}
+		inputs = append(inputs, winapi.KeyboardInput{Type: winapi.INPUT_KEYBOARD, Ki: ki})
+		ki.Flags |= winapi.KEYEVENTF_KEYUP
+		inputs = append(inputs, winapi.KeyboardInput{Type: winapi.INPUT_KEYBOARD, Ki: ki})
+	}
+	_, err := winapi.SendInput(inputs)
+	return err
+}
@@ -0,0 +1,296 @@
+package kbcap
+
+import (
+	"unicode"
+
+	"github.com/FxStar/winapi"
+)
+
+// KeyChars are the characters of a key by shift level, 0 when there is none.
+// Dead keys are left out.
+type KeyChars struct {
+	Normal   rune
+	Shift    rune
+	AltGr    rune
+	CapsLock bool // CapsLock acts as Shift
+}
+
+// Layout maps virtual keys to characters, like a Windows keyboard layout DLL
+// without dead keys and ligatures.
+type Layout struct {
+	Name string
+	Keys map[uint32]KeyChars
+	// ScanCodes overrides the US positions of scan codes, see VirtualKey.
+	ScanCodes map[uint32]uint32
+}
+
+func (l *Layout) String() string {
+	return l.Name
+}
+
+// newLayout fills the letters and the keys common to all layouts, then
+// applies keys and the AltGr characters.
+func newLayout(name string, keys map[uint32]KeyChars, altGr map[uint32]rune, scanCodes map[uint32]uint32) *Layout {
+	l := &Layout{Name: name, ScanCodes: scanCodes, Keys: map[uint32]KeyChars{
+		winapi.VK_SPACE:     {Normal: ' ', Shift: ' '},
+		winapi.VK_RETURN:    {Normal: '\r', Shift: '\r'},
+		winapi.VK_TAB:       {Normal: '\t', Shift: '\t'},
+		winapi.VK_BACK:      {Normal: '\b', Shift: '\b'},
+		winapi.VK_ESCAPE:    {Normal: 0x1b, Shift: 0x1b},
+		winapi.VK_MULTIPLY:  {Normal: '*', Shift: '*'},
+		winapi.VK_ADD:       {Normal: '+', Shift: '+'},
+		winapi.VK_SUBTRACT:  {Normal: '-', Shift: '-'},
+		winapi.VK_DECIMAL:   {Normal: '.', Shift: '.'},
+		winapi.VK_DIVIDE:    {Normal: '/', Shift: '/'},
+		winapi.VK_SEPARATOR: {Normal: ',', Shift: ','},
+	}}
+	for vk := uint32('A'); vk <= 'Z'; vk++ {
+		l.Keys[vk] = KeyChars{Normal: unicode.ToLower(rune(vk)), Shift: rune(vk), CapsLock: true}
+	}
+	for i := uint32(0); i <= 9; i++ {
+		l.Keys[winapi.VK_NUMPAD0+i] = KeyChars{Normal: '0' + rune(i)}
+	}
+	for vk, kc := range keys {
+		l.Keys[vk] = kc
+	}
+	for vk, r := range altGr {
+		kc := l.Keys[vk]
+		kc.AltGr = r
+		l.Keys[vk] = kc
+	}
+	return l
+}
+
+func shifted(normal, shift rune) KeyChars {
+	return KeyChars{Normal: normal, Shift: shift}
+}
+
+var LayoutUS = newLayout("us", map[uint32]KeyChars{
+	'1': shifted('1', '!'), '2': shifted('2', '@'), '3': shifted('3', '#'),
+	'4': shifted('4', '$'), '5': shifted('5', '%'), '6': shifted('6', '^'),
+	'7': shifted('7', '&'), '8': shifted('8', '*'), '9': shifted('9', '('), '0': shifted('0', ')'),
+	winapi.VK_OEM_MINUS:  shifted('-', '_'),
+	winapi.VK_OEM_PLUS:   shifted('=', '+'),
+	winapi.VK_OEM_4:      shifted('[', '{'),
+	winapi.VK_OEM_6:      shifted(']', '}'),
+	winapi.VK_OEM_5:      shifted('\\', '|'),
+	winapi.VK_OEM_1:      shifted(';', ':'),
+	winapi.VK_OEM_7:      shifted('\'', '"'),
+	winapi.VK_OEM_3:      shifted('`', '~'),
+	winapi.VK_OEM_COMMA:  shifted(',', '<'),
+	winapi.VK_OEM_PERIOD: shifted('.', '>'),
+	winapi.VK_OEM_2:      shifted('/', '?'),
+	winapi.VK_OEM_102:    shifted('\\', '|'),
+}, nil, nil)
+
+var LayoutUK = newLayout("uk", map[uint32]KeyChars{
+	'1': shifted('1', '!'), '2': shifted('2', '"'), '3': shifted('3', '£'),
+	'4': shifted('4', '$'), '5': shifted('5', '%'), '6': shifted('6', '^'),
+	'7': shifted('7', '&'), '8': shifted('8', '*'), '9': shifted('9', '('), '0': shifted('0', ')'),
+	winapi.VK_OEM_MINUS:  shifted('-', '_'),
+	winapi.VK_OEM_PLUS:   shifted('=', '+'),
+	winapi.VK_OEM_4:      shifted('[', '{'),
+	winapi.VK_OEM_6:      shifted(']', '}'),
+	winapi.VK_OEM_1:      shifted(';', ':'),
+	winapi.VK_OEM_3:      shifted('\'', '@'),
+	winapi.VK_OEM_7:      shifted('#', '~'),
+	winapi.VK_OEM_8:      shifted('`', '¬'),
+	winapi.VK_OEM_5:      shifted('\\', '|'),
+	winapi.VK_OEM_COMMA:  shifted(',', '<'),
+	winapi.VK_OEM_PERIOD: shifted('.', '>'),
+	winapi.VK_OEM_2:      shifted('/', '?'),
+}, map[uint32]rune{
+	'4': '€', winapi.VK_OEM_8: '¦',
+	'A': 'á', 'E': 'é', 'I': 'í', 'O': 'ó', 'U': 'ú',
+}, map[uint32]uint32{
+	0x28: winapi.VK_OEM_3, 0x29: winapi.VK_OEM_8, 0x2b: winapi.VK_OEM_7, 0x56: winapi.VK_OEM_5,
+})
+
+var LayoutDE = newLayout("de", map[uint32]KeyChars{
+	'1': shifted('1', '!'), '2': shifted('2', '"'), '3': shifted('3', '§'),
+	'4': shifted('4', '$'), '5': shifted('5', '%'), '6': shifted('6', '&'),
+	'7': shifted('7', '/'), '8': shifted('8', '('), '9': shifted('9', ')'), '0': shifted('0', '='),
+	winapi.VK_OEM_4:      shifted('ß', '?'),
+	winapi.VK_OEM_5:      shifted(0, '°'), // ^ is dead
+	winapi.VK_OEM_1:      {Normal: 'ü', Shift: 'Ü', CapsLock: true},
+	winapi.VK_OEM_3:      {Normal: 'ö', Shift: 'Ö', CapsLock: true},
+	winapi.VK_OEM_7:      {Normal: 'ä', Shift: 'Ä', CapsLock: true},
+	winapi.VK_OEM_PLUS:   shifted('+', '*'),
+	winapi.VK_OEM_2:      shifted('#', '\''),
+	winapi.VK_OEM_MINUS:  shifted('-', '_'),
+	winapi.VK_OEM_COMMA:  shifted(',', ';'),
+	winapi.VK_OEM_PERIOD: shifted('.', ':'),
+	winapi.VK_OEM_102:    shifted('<', '>'),
+	winapi.VK_DECIMAL:    shifted(',', ','),
+}, map[uint32]rune{
+	'2': '²', '3': '³', '7': '{', '8': '[', '9': ']', '0': '}',
+	winapi.VK_OEM_4: '\\', winapi.VK_OEM_PLUS: '~', winapi.VK_OEM_102: '|',
+	'Q': '@', 'E': '€', 'M': 'µ',
+}, map[uint32]uint32{
+	0x15: 'Z', 0x2c: 'Y',
+	0x0c: winapi.VK_OEM_4, 0x0d: winapi.VK_OEM_6, 0x1a: winapi.VK_OEM_1, 0x1b: winapi.VK_OEM_PLUS,
+	0x27: winapi.VK_OEM_3, 0x28: winapi.VK_OEM_7, 0x29: winapi.VK_OEM_5, 0x2b: winapi.VK_OEM_2,
+	0x35: winapi.VK_OEM_MINUS,
+})
+
+// LayoutFR is AZERTY, its digits are shifted and CapsLock shifts them too.
+var LayoutFR = newLayout("fr", map[uint32]KeyChars{
+	'1': {Normal: '&', Shift: '1', CapsLock: true},
+	'2': {Normal: 'é', Shift: '2', CapsLock: true},
+	'3': {Normal: '"', Shift: '3', CapsLock: true},
+	'4': {Normal: '\'', Shift: '4', CapsLock: true},
+	'5': {Normal: '(', Shift: '5', CapsLock: true},
+	'6': {Normal: '-', Shift: '6', CapsLock: true},
+	'7': {Normal: 'è', Shift: '7', CapsLock: true},
+	'8': {Normal: '_', Shift: '8', CapsLock: true},
+	'9': {Normal: 'ç', Shift: '9', CapsLock: true},
+	'0': {Normal: 'à', Shift: '0', CapsLock: true},
+
+	winapi.VK_OEM_4:      shifted(')', '°'),
+	winapi.VK_OEM_PLUS:   shifted('=', '+'),
+	winapi.VK_OEM_1:      shifted('$', '£'),
+	winapi.VK_OEM_3:      shifted('ù', '%'),
+	winapi.VK_OEM_5:      shifted('*', 'µ'),
+	winapi.VK_OEM_COMMA:  shifted(',', '?'),
+	winapi.VK_OEM_PERIOD: shifted(';', '.'),
+	winapi.VK_OEM_2:      shifted(':', '/'),
+	winapi.VK_OEM_8:      shifted('!', '§'),
+	winapi.VK_OEM_7:      shifted('²', 0),
+	winapi.VK_OEM_102:    shifted('<', '>'),
+}, map[uint32]rune{
+	'3': '#', '4': '{', '5': '[', '6': '|', '8': '\\', '9': '^', '0': '@',
+	winapi.VK_OEM_4: ']', winapi.VK_OEM_PLUS: '}', winapi.VK_OEM_1: '¤',
+	'E': '€',
+}, map[uint32]uint32{
+	0x10: 'A', 0x11: 'Z', 0x1e: 'Q', 0x2c: 'W', 0x27: 'M',
+	0x0c: winapi.VK_OEM_4, 0x0d: winapi.VK_OEM_PLUS, 0x1a: winapi.VK_OEM_6, 0x1b: winapi.VK_OEM_1,
+	0x28: winapi.VK_OEM_3, 0x29: winapi.VK_OEM_7, 0x2b: winapi.VK_OEM_5, 0x32: winapi.VK_OEM_COMMA,
+	0x33: winapi.VK_OEM_PERIOD, 0x34: winapi.VK_OEM_2, 0x35: winapi.VK_OEM_8,
+})
+
+// LayoutJP is the 106 key layout, VK_OEM_5 and VK_OEM_102 give the yen sign
+// of JIS fonts, which is a backslash.
+var LayoutJP = newLayout("jp", map[uint32]KeyChars{
+	'1': shifted('1', '!'), '2': shifted('2', '"'), '3': shifted('3', '#'),
+	'4': shifted('4', '$'), '5': shifted('5', '%'), '6': shifted('6', '&'),
+	'7': shifted('7', '\''), '8': shifted('8', '('), '9': shifted('9', ')'), '0': shifted('0', 0),
+	winapi.VK_OEM_MINUS:  shifted('-', '='),
+	winapi.VK_OEM_7:      shifted('^', '~'),
+	winapi.VK_OEM_5:      shifted('\\', '|'),
+	winapi.VK_OEM_3:      shifted('@', '`'),
+	winapi.VK_OEM_4:      shifted('[', '{'),
+	winapi.VK_OEM_PLUS:   shifted(';', '+'),
+	winapi.VK_OEM_1:      shifted(':', '*'),
+	winapi.VK_OEM_6:      shifted(']', '}'),
+	winapi.VK_OEM_COMMA:  shifted(',', '<'),
+	winapi.VK_OEM_PERIOD: shifted('.', '>'),
+	winapi.VK_OEM_2:      shifted('/', '?'),
+	winapi.VK_OEM_102:    shifted('\\', '_'),
+}, nil, map[uint32]uint32{
+	0x0d: winapi.VK_OEM_7, 0x1a: winapi.VK_OEM_3, 0x1b: winapi.VK_OEM_4, 0x27: winapi.VK_OEM_PLUS,
+	0x28: winapi.VK_OEM_1, 0x29: winapi.VK_KANJI, 0x2b: winapi.VK_OEM_6, 0x73: winapi.VK_OEM_102,
+	0x7d: winapi.VK_OEM_5,
+})
+
+// LayoutForLangID returns the layout of the language in the low word of a
+// HKL, as returned by GetKeyboardLayout, or nil.
+func LayoutForLangID(langID uint16) *Layout {
+	switch langID {
+	case 0x0409:
+		return LayoutUS
+	case 0x0809:
+		return LayoutUK
+	case 0x0407, 0x0c07: // Germany, Austria
+		return LayoutDE
+	case 0x040c:
+		return LayoutFR
+	case 0x0411:
+		return LayoutJP
+	}
+	return nil
+}
+
+// LayoutByName returns the layout named name, such as "de", or nil.
+func LayoutByName(name string) *Layout {
+	for _, l := range []*Layout{LayoutUS, LayoutUK, LayoutDE, LayoutFR, LayoutJP} {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Translator tracks Shift, Ctrl, Alt and CapsLock from a key event stream and
+// translates key downs with its Layout. Right Alt, or Ctrl and Alt, is AltGr.
+// Ctrl gives the control characters of letters and of VK_OEM_4, 5, 6.
+type Translator struct {
+	Layout   *Layout
+	CapsLock bool // toggled by VK_CAPITAL downs, seed it from GetKeyState
+
+	lshift, rshift bool
+	lctrl, rctrl   bool
+	lalt, ralt     bool
+	capsDown       bool
+}
+
+func NewTranslator(l *Layout) *Translator {
+	return &Translator{Layout: l}
+}
+
+// Key records a key down or up and returns the character a down produces.
+// Plain VK_SHIFT, VK_CONTROL and VK_MENU count as the left keys.
+func (t *Translator) Key(vk uint32, down bool) (rune, bool) {
+	switch vk {
+	case winapi.VK_SHIFT, winapi.VK_LSHIFT:
+		t.lshift = down
+	case winapi.VK_RSHIFT:
+		t.rshift = down
+	case winapi.VK_CONTROL, winapi.VK_LCONTROL:
+		t.lctrl = down
+	case winapi.VK_RCONTROL:
+		t.rctrl = down
+	case winapi.VK_MENU, winapi.VK_LMENU:
+		t.lalt = down
+	case winapi.VK_RMENU:
+		t.ralt = down
+	case winapi.VK_CAPITAL:
+		if down && !t.capsDown {
+			t.CapsLock = !t.CapsLock
+		}
+		t.capsDown = down
+	default:
+		if down {
+			return t.Rune(vk)
+		}
+	}
+	return 0, false
+}
+
+// Rune translates vk with the current modifier state.
+func (t *Translator) Rune(vk uint32) (rune, bool) {
+	ctrl, alt := t.lctrl || t.rctrl, t.lalt || t.ralt
+	kc, ok := t.Layout.Keys[vk]
+	switch {
+	case t.ralt || ctrl && alt:
+		return kc.AltGr, ok && kc.AltGr != 0
+	case alt:
+		return 0, false
+	case ctrl:
+		switch {
+		case vk >= 'A' && vk <= 'Z':
+			return rune(vk) - '@', true
+		case vk == winapi.VK_OEM_4, vk == winapi.VK_OEM_5, vk == winapi.VK_OEM_6:
+			return rune(vk-winapi.VK_OEM_4) + 0x1b, true // ESC, FS, GS
+		}
+		return 0, false
+	}
+	shift := t.lshift || t.rshift
+	if kc.CapsLock && t.CapsLock {
+		shift = !shift
+	}
+	r := kc.Normal
+	if shift {
+		r = kc.Shift
+	}
+	return r, ok && r != 0
+}
@@ -0,0 +1,240 @@
+package kbcap
+
+import (
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+// keyTest is a key pressed with the modifiers held, and the character it
+// should give, 0 for none.
+type keyTest struct {
+	mods []uint32
+	caps bool
+	vk   uint32
+	want rune
+}
+
+var (
+	shift = []uint32{winapi.VK_LSHIFT}
+	ctrl  = []uint32{winapi.VK_LCONTROL}
+	ralt  = []uint32{winapi.VK_RMENU}
+	// Ctrl+Alt, as Windows also reports AltGr on some keyboards
+	ctrlAlt = []uint32{winapi.VK_LCONTROL, winapi.VK_LMENU}
+)
+
+// press holds mods down around vk, then releases them all.
+func press(tr *Translator, mods []uint32, vk uint32) (rune, bool) {
+	for _, m := range mods {
+		tr.Key(m, true)
+	}
+	r, ok := tr.Key(vk, true)
+	tr.Key(vk, false)
+	for _, m := range mods {
+		tr.Key(m, false)
+	}
+	return r, ok
+}
+
+func testLayout(t *testing.T, l *Layout, tests []keyTest) {
+	t.Helper()
+	tr := NewTranslator(l)
+	for _, tt := range tests {
+		tr.CapsLock = tt.caps
+		r, ok := press(tr, tt.mods, tt.vk)
+		if r != tt.want || ok != (tt.want != 0) {
+			t.Errorf("%s: %s %v caps=%v = %q, %v, want %q", l, winapi.VKName(tt.vk), modNames(tt.mods), tt.caps, r, ok, tt.want)
+		}
+	}
+}
+
+func modNames(mods []uint32) []string {
+	var names []string
+	for _, m := range mods {
+		names = append(names, winapi.VKName(m))
+	}
+	return names
+}
+
+func TestLayoutUS(t *testing.T) {
+	testLayout(t, LayoutUS, []keyTest{
+		{nil, false, 'A', 'a'},
+		{shift, false, 'A', 'A'},
+		{nil, true, 'A', 'A'},
+		{shift, true, 'A', 'a'},
+		{nil, false, '1', '1'},
+		{shift, false, '1', '!'},
+		{nil, true, '1', '1'}, // CapsLock leaves digits alone
+		{shift, false, winapi.VK_OEM_2, '?'},
+		{nil, false, winapi.VK_OEM_3, '`'},
+		{nil, false, winapi.VK_NUMPAD7, '7'},
+		{nil, false, winapi.VK_RETURN, '\r'},
+		{ralt, false, 'E', 0}, // no AltGr level
+		{ctrlAlt, false, 'E', 0},
+		{[]uint32{winapi.VK_LMENU}, false, 'A', 0},
+		{nil, false, winapi.VK_F1, 0},
+	})
+}
+
+func TestLayoutControl(t *testing.T) {
+	testLayout(t, LayoutUS, []keyTest{
+		{ctrl, false, 'A', 0x01},
+		{ctrl, false, 'M', 0x0d},
+		{ctrl, false, 'Z', 0x1a},
+		{[]uint32{winapi.VK_RCONTROL}, false, 'C', 0x03},
+		{[]uint32{winapi.VK_CONTROL, winapi.VK_SHIFT}, false, 'D', 0x04},
+		{ctrl, false, winapi.VK_OEM_4, 0x1b}, // Ctrl+[, ESC
+		{ctrl, false, winapi.VK_OEM_5, 0x1c}, // Ctrl+\, FS
+		{ctrl, false, winapi.VK_OEM_6, 0x1d}, // Ctrl+], GS, the GS1 separator
+		{ctrl, false, '1', 0},
+		{ctrl, false, winapi.VK_OEM_2, 0},
+	})
+}
+
+func TestLayoutUK(t *testing.T) {
+	testLayout(t, LayoutUK, []keyTest{
+		{shift, false, '2', '"'},
+		{shift, false, '3', '£'},
+		{nil, false, winapi.VK_OEM_3, '\''},
+		{shift, false, winapi.VK_OEM_3, '@'},
+		{nil, false, winapi.VK_OEM_7, '#'},
+		{shift, false, winapi.VK_OEM_7, '~'},
+		{nil, false, winapi.VK_OEM_8, '`'},
+		{shift, false, winapi.VK_OEM_8, '¬'},
+		{ralt, false, winapi.VK_OEM_8, '¦'},
+		{nil, false, winapi.VK_OEM_5, '\\'},
+		{ralt, false, '4', '€'},
+		{ctrlAlt, false, 'E', 'é'},
+	})
+}
+
+func TestLayoutDE(t *testing.T) {
+	testLayout(t, LayoutDE, []keyTest{
+		{nil, false, 'Y', 'y'},
+		{nil, false, 'Z', 'z'},
+		{nil, false, winapi.VK_OEM_1, 'ü'},
+		{shift, false, winapi.VK_OEM_3, 'Ö'},
+		{nil, true, winapi.VK_OEM_7, 'Ä'},
+		{shift, true, winapi.VK_OEM_7, 'ä'},
+		{nil, false, winapi.VK_OEM_4, 'ß'},
+		{shift, false, '7', '/'},
+		{shift, false, '0', '='},
+		{nil, true, '7', '7'},
+		{ralt, false, 'Q', '@'},
+		{ctrlAlt, false, 'Q', '@'},
+		{ralt, false, '8', '['},
+		{ralt, false, winapi.VK_OEM_4, '\\'},
+		{ralt, false, winapi.VK_OEM_102, '|'},
+		{ralt, true, 'E', '€'},
+		{nil, false, winapi.VK_OEM_5, 0}, // ^ is dead
+		{shift, false, winapi.VK_OEM_5, '°'},
+		{nil, false, winapi.VK_DECIMAL, ','},
+	})
+}
+
+func TestLayoutFR(t *testing.T) {
+	testLayout(t, LayoutFR, []keyTest{
+		{nil, false, 'A', 'a'},
+		{nil, false, '1', '&'},
+		{shift, false, '1', '1'},
+		{nil, true, '1', '1'}, // CapsLock shifts the digit row
+		{shift, true, '1', '&'},
+		{nil, false, '2', 'é'},
+		{nil, true, '2', '2'},
+		{nil, false, '0', 'à'},
+		{ralt, false, '0', '@'},
+		{ctrlAlt, false, '3', '#'},
+		{ralt, true, '5', '['},
+		{nil, false, winapi.VK_OEM_3, 'ù'},
+		{nil, true, winapi.VK_OEM_3, 'ù'}, // not a CapsLock key
+		{shift, false, winapi.VK_OEM_PERIOD, '.'},
+		{shift, false, winapi.VK_OEM_7, 0},
+	})
+}
+
+func TestLayoutJP(t *testing.T) {
+	testLayout(t, LayoutJP, []keyTest{
+		{shift, false, '2', '"'},
+		{shift, false, '7', '\''},
+		{shift, false, '0', 0},
+		{nil, false, winapi.VK_OEM_3, '@'},
+		{shift, false, winapi.VK_OEM_3, '`'},
+		{nil, false, winapi.VK_OEM_PLUS, ';'},
+		{shift, false, winapi.VK_OEM_PLUS, '+'},
+		{nil, false, winapi.VK_OEM_1, ':'},
+		{shift, false, winapi.VK_OEM_1, '*'},
+		{nil, false, winapi.VK_OEM_7, '^'},
+		{shift, false, winapi.VK_OEM_MINUS, '='},
+		{nil, false, winapi.VK_OEM_5, '\\'}, // yen
+		{shift, false, winapi.VK_OEM_102, '_'},
+		{ralt, false, 'A', 0},
+	})
+}
+
+func TestLayoutScanCodes(t *testing.T) {
+	tests := []struct {
+		l    *Layout
+		scan uint32
+		want uint32
+	}{
+		{LayoutUS, 0x15, 'Y'},
+		{LayoutUS, 0x2c, 'Z'},
+		{LayoutDE, 0x15, 'Z'},
+		{LayoutDE, 0x2c, 'Y'},
+		{LayoutDE, 0x27, winapi.VK_OEM_3},
+		{LayoutFR, 0x10, 'A'},
+		{LayoutFR, 0x27, 'M'},
+		{LayoutUK, 0x2b, winapi.VK_OEM_7},
+		{LayoutUK, 0x56, winapi.VK_OEM_5},
+		{LayoutJP, 0x73, winapi.VK_OEM_102},
+		{LayoutJP, 0x7d, winapi.VK_OEM_5},
+		{LayoutJP, 0x29, winapi.VK_KANJI},
+	}
+	for _, tt := range tests {
+		if vk := tt.l.VirtualKey(tt.scan, false); vk != tt.want {
+			t.Errorf("%s: VirtualKey(%#x) = %#x, want %#x", tt.l, tt.scan, vk, tt.want)
+		}
+	}
+}
+
+// TestTranslatorRelease checks that key ups release the modifiers, and that a
+// held CapsLock toggles once.
+func TestTranslatorRelease(t *testing.T) {
+	tr := NewTranslator(LayoutDE)
+	for _, mods := range [][]uint32{shift, ctrl, ralt, ctrlAlt, {winapi.VK_RSHIFT}, {winapi.VK_MENU}} {
+		press(tr, mods, 'Q')
+		if r, ok := tr.Key('Q', true); r != 'q' || !ok {
+			t.Errorf("after %v: Q = %q, %v, want 'q'", modNames(mods), r, ok)
+		}
+	}
+	// the hook sees WM_SYSKEYUP for Alt, still a key up
+	tr.Key(winapi.VK_RMENU, true)
+	tr.Key(winapi.VK_RMENU, false)
+	if r, _ := tr.Key('Q', true); r != 'q' {
+		t.Errorf("after AltGr: Q = %q", r)
+	}
+
+	for i := 0; i < 3; i++ { // auto-repeat
+		tr.Key(winapi.VK_CAPITAL, true)
+	}
+	tr.Key(winapi.VK_CAPITAL, false)
+	if !tr.CapsLock {
+		t.Fatal("CapsLock not on")
+	}
+	tr.Key(winapi.VK_CAPITAL, true)
+	tr.Key(winapi.VK_CAPITAL, false)
+	if tr.CapsLock {
+		t.Error("CapsLock not off")
+	}
+}
+
+func TestLayoutLookup(t *testing.T) {
+	for _, l := range []*Layout{LayoutUS, LayoutUK, LayoutDE, LayoutFR, LayoutJP} {
+		if LayoutByName(l.Name) != l {
+			t.Errorf("LayoutByName(%q) = %v", l.Name, LayoutByName(l.Name))
+		}
+	}
+	if LayoutForLangID(0x0c07) != LayoutDE || LayoutForLangID(0x0411) != LayoutJP || LayoutForLangID(0x0419) != nil {
+		t.Error("LayoutForLangID")
+	}
+}
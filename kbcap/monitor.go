@@ -0,0 +1,61 @@
+package kbcap
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// KeyEvent is a key down seen by a Monitor.
+type KeyEvent struct {
+	VkCode   uint32
+	ScanCode uint32
+	Flags    uint32 // LLKHF_*
+	Rune     rune   // 0 for keys without a character
+	Time     time.Time
+	Device   string // source device when known
+	Scan     *Scan  // the scan this key completed, if any
+}
+
+var (
+	ErrMonitorRunning = errors.New("keyboard monitor already running")
+	ErrEventsDropped  = errors.New("keyboard events dropped, channel full")
+)
+
+// pipeline runs key downs through an Assembler and an optional Gate. It is
+// the hook independent part of Monitor.
+type pipeline struct {
+	mu   sync.Mutex
+	asm  *Assembler
+	gate *Gate
+}
+
+// key sets ev.Scan, and reports whether the key is swallowed and what to replay.
+func (p *pipeline) key(ev *KeyEvent) (swallow bool, replay []GatedKey) {
+	if ev.Rune == 0 {
+		return false, nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ks := Keystroke{Rune: ev.Rune, Time: ev.Time, Device: ev.Device}
+	ev.Scan = p.asm.FeedKey(ks)
+	if p.gate == nil {
+		return false, nil
+	}
+	return p.gate.Feed(GatedKey{
+		Keystroke: ks,
+		VkCode:    ev.VkCode,
+		ScanCode:  ev.ScanCode,
+		Flags:     ev.Flags,
+	})
+}
+
+func (p *pipeline) tick(now time.Time) []GatedKey {
+	if p.gate == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.gate.Tick(now)
+}
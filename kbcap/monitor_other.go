@@ -0,0 +1,54 @@
+//go:build !windows
+// +build !windows
+
+package kbcap
+
+import (
+	"context"
+
+	"github.com/FxStar/winapi"
+)
+
+// Monitor needs a low level keyboard hook, on other platforms Start fails
+// with winapi.ErrNotSupported. Use EvdevReader on Linux.
+type Monitor struct {
+	Assembler *Assembler
+	Gate      *Gate
+	Layout    *Layout
+	Recorder  *Recorder
+
+	events chan KeyEvent
+	errs   chan error
+}
+
+func NewMonitor() *Monitor {
+	return &Monitor{
+		Assembler: NewAssembler(),
+		events:    make(chan KeyEvent),
+		errs:      make(chan error),
+	}
+}
+
+func (m *Monitor) Events() <-chan KeyEvent {
+	return m.events
+}
+
+func (m *Monitor) Errors() <-chan error {
+	return m.errs
+}
+
+func (m *Monitor) Start(ctx context.Context) error {
+	return winapi.ErrNotSupported
+}
+
+func (m *Monitor) Stop() error {
+	return nil
+}
+
+func (m *Monitor) Done() <-chan struct{} {
+	return nil
+}
+
+func (m *Monitor) Err() error {
+	return nil
+}
@@ -0,0 +1,197 @@
+package kbcap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/hooks"
+	"github.com/pkg/errors"
+)
+
+// Monitor runs a low level keyboard hook on its own locked OS thread:
+//
+//	m := NewMonitor()
+//	if err := m.Start(ctx); err != nil {
+//	}
+//	for {
+//		select {
+//		case ev := <-m.Events():
+//		case err := <-m.Errors():
+//		case <-m.Done():
+//			return m.Err()
+//		}
+//	}
+//
+// A stopped Monitor can be started again, its channels are kept.
+type Monitor struct {
+	Assembler *Assembler // NewAssembler() when nil
+	Gate      *Gate      // swallows scan keys when set
+	Layout    *Layout    // translates keys itself instead of ToAscii when set
+	Recorder  *Recorder  // records every key message when set
+
+	events chan KeyEvent
+	errs   chan error
+
+	mu    sync.Mutex
+	pipe  *pipeline
+	trans *Translator
+	hook  *hooks.Hook
+}
+
+func NewMonitor() *Monitor {
+	return &Monitor{
+		Assembler: NewAssembler(),
+		events:    make(chan KeyEvent, 256),
+		errs:      make(chan error, 16),
+	}
+}
+
+// Events delivers every key down, the hook never blocks on it: events are
+// dropped with ErrEventsDropped when the channel is full.
+func (m *Monitor) Events() <-chan KeyEvent {
+	return m.events
+}
+
+// Errors delivers the errors that don't stop the monitor.
+func (m *Monitor) Errors() <-chan error {
+	return m.errs
+}
+
+// Start installs the hook, it stops when ctx is done or Stop is called.
+func (m *Monitor) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hook == nil {
+		m.hook = &hooks.Hook{Sources: hooks.Keyboard, Filter: m.filter}
+	}
+	if done := m.hook.Done(); done != nil {
+		select {
+		case <-done:
+		default:
+			return ErrMonitorRunning
+		}
+	}
+	if m.Assembler == nil {
+		m.Assembler = NewAssembler()
+	}
+	m.pipe = &pipeline{asm: m.Assembler, gate: m.Gate}
+	m.trans = nil
+	if m.Layout != nil {
+		m.trans = NewTranslator(m.Layout)
+		m.trans.CapsLock = winapi.GetKeyState(winapi.VK_CAPITAL)&1 != 0
+	}
+	if err := m.hook.Start(ctx); err != nil {
+		return err
+	}
+	if m.Gate != nil && m.Gate.Classifier != nil {
+		if interval := m.Gate.Classifier.MaxKeyInterval / 2; interval > 0 {
+			go m.tick(m.hook.Done(), m.pipe, interval)
+		}
+	}
+	return nil
+}
+
+// Stop waits for the hook to be removed.
+func (m *Monitor) Stop() error {
+	m.mu.Lock()
+	hook := m.hook
+	m.mu.Unlock()
+	if hook == nil {
+		return nil
+	}
+	return hook.Stop()
+}
+
+// Done is closed when the monitor stops.
+func (m *Monitor) Done() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hook == nil {
+		return nil
+	}
+	return m.hook.Done()
+}
+
+// Err returns the error that stopped the monitor, nil after Stop.
+func (m *Monitor) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hook == nil {
+		return nil
+	}
+	return m.hook.Err()
+}
+
+// tick releases the keys the gate holds past their deadline. It takes the
+// pipeline of its run, a restart replaces m.pipe.
+func (m *Monitor) tick(done <-chan struct{}, pipe *pipeline, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			m.replay(pipe.tick(now))
+		case <-done:
+			return
+		}
+	}
+}
+
+// filter runs on the hook thread, it reports whether the key must be swallowed.
+func (m *Monitor) filter(ev *hooks.Event) bool {
+	if m.Recorder != nil {
+		k := KBDLLHOOKSTRUCT{VkCode: ev.VkCode, ScanCode: ev.ScanCode, Flags: ev.Flags, Time: ev.Tick, DwExtraInfo: ev.ExtraInfo}
+		if err := m.Recorder.Record(ev.Time, ev.Message, &k); err != nil {
+			m.report(errors.Wrap(err, "record key failed"))
+		}
+	}
+	switch ev.Message {
+	case WM_KEYDOWN:
+		return m.keyDown(ev)
+	case WM_SYSKEYDOWN, WM_KEYUP, WM_SYSKEYUP:
+		if m.trans != nil { // modifier state
+			m.trans.Key(ev.VkCode, ev.Message == WM_SYSKEYDOWN)
+		}
+	}
+	return false
+}
+
+func (m *Monitor) keyDown(hev *hooks.Event) bool {
+	if hev.ExtraInfo == replayMarker {
+		return false
+	}
+	ev := KeyEvent{
+		VkCode:   hev.VkCode,
+		ScanCode: hev.ScanCode,
+		Flags:    hev.Flags,
+		Time:     hev.Time,
+	}
+	if m.trans != nil {
+		ev.Rune, _ = m.trans.Key(hev.VkCode, true)
+	} else if b, ok := CodeToChar(&KBDLLHOOKSTRUCT{VkCode: hev.VkCode, ScanCode: hev.ScanCode}); ok {
+		ev.Rune = rune(b)
+	}
+	swallow, replay := m.pipe.key(&ev)
+	m.replay(replay)
+	select {
+	case m.events <- ev:
+	default:
+		m.report(ErrEventsDropped)
+	}
+	return swallow
+}
+
+func (m *Monitor) replay(keys []GatedKey) {
+	if err := replayKeys(keys); err != nil {
+		m.report(errors.Wrapf(err, "replay %d keys failed", len(keys)))
+	}
+}
+
+func (m *Monitor) report(err error) {
+	select {
+	case m.errs <- err:
+	default:
+	}
+}
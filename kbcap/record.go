@@ -0,0 +1,255 @@
+package kbcap
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// A recording is the magic "KBCR", a version byte and a header of uvarints:
+// the start time in unix microseconds, the flags (bit 0 CapsLock on) and the
+// length of the layout name followed by the name. Each key is then the
+// microseconds since the previous key, the message, VkCode, ScanCode, Flags
+// and DwExtraInfo, all uvarints. A typical key takes 8 or 9 bytes.
+const (
+	recordMagic   = "KBCR"
+	recordVersion = 1
+
+	recordCapsLock = 1 << 0
+)
+
+var ErrInvalidRecording = errors.New("invalid key recording")
+
+// RecordedKey is a low level keyboard hook call.
+type RecordedKey struct {
+	Time    time.Time
+	Message uint32 // WM_KEYDOWN, WM_KEYUP, WM_SYSKEYDOWN or WM_SYSKEYUP
+	Key     winapi.KBDLLHOOKSTRUCT
+}
+
+// Recorder writes keys to a recording, it is safe for concurrent use.
+// Set Monitor.Recorder to record what a monitor sees:
+//
+//	f, _ := os.Create("field.kbcr")
+//	rec, _ := NewRecorder(f, m.Layout, false)
+//	m.Recorder = rec
+//	...
+//	m.Stop()
+//	rec.Flush()
+type Recorder struct {
+	mu   sync.Mutex
+	w    *bufio.Writer
+	last time.Time
+	buf  [6 * binary.MaxVarintLen64]byte
+}
+
+// NewRecorder writes the header, layout is the one Replayer uses when it has
+// none of its own, nil for ToAscii.
+func NewRecorder(w io.Writer, layout *Layout, capsLock bool) (*Recorder, error) {
+	// the recording has microseconds, keep last on them so keys don't drift
+	r := &Recorder{w: bufio.NewWriter(w), last: time.Now().Truncate(time.Microsecond)}
+	var name string
+	if layout != nil {
+		name = layout.Name
+	}
+	var flags uint64
+	if capsLock {
+		flags |= recordCapsLock
+	}
+	b := append([]byte(recordMagic), recordVersion)
+	b = binary.AppendUvarint(b, uint64(r.last.UnixMicro()))
+	b = binary.AppendUvarint(b, flags)
+	b = binary.AppendUvarint(b, uint64(len(name)))
+	b = append(b, name...)
+	if _, err := r.w.Write(b); err != nil {
+		return nil, errors.Wrap(err, "write recording header failed")
+	}
+	return r, nil
+}
+
+// Record appends a key, keys recorded out of order are stored as simultaneous.
+func (r *Recorder) Record(t time.Time, msg uint32, k *winapi.KBDLLHOOKSTRUCT) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var delta uint64
+	if d := t.Sub(r.last); d > 0 {
+		delta = uint64(d / time.Microsecond)
+		r.last = r.last.Add(time.Duration(delta) * time.Microsecond)
+	}
+	b := r.buf[:0]
+	b = binary.AppendUvarint(b, delta)
+	b = binary.AppendUvarint(b, uint64(msg))
+	b = binary.AppendUvarint(b, uint64(k.VkCode))
+	b = binary.AppendUvarint(b, uint64(k.ScanCode))
+	b = binary.AppendUvarint(b, uint64(k.Flags))
+	b = binary.AppendUvarint(b, uint64(k.DwExtraInfo))
+	_, err := r.w.Write(b)
+	return err
+}
+
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Flush()
+}
+
+// RecordReader reads the keys of a recording.
+type RecordReader struct {
+	Start    time.Time
+	Layout   string // layout name, "" when recorded with ToAscii
+	CapsLock bool   // CapsLock state at the start
+
+	r    *bufio.Reader
+	last time.Time
+}
+
+func NewRecordReader(r io.Reader) (*RecordReader, error) {
+	rr := &RecordReader{r: bufio.NewReader(r)}
+	var magic [len(recordMagic) + 1]byte
+	if _, err := io.ReadFull(rr.r, magic[:]); err != nil {
+		return nil, errors.Wrap(ErrInvalidRecording, "missing header")
+	}
+	if string(magic[:len(recordMagic)]) != recordMagic {
+		return nil, errors.Wrap(ErrInvalidRecording, "bad magic")
+	}
+	if v := magic[len(recordMagic)]; v != recordVersion {
+		return nil, errors.Wrapf(ErrInvalidRecording, "unsupported version %d", v)
+	}
+	var h [3]uint64
+	for i := range h {
+		v, err := binary.ReadUvarint(rr.r)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidRecording, "truncated header")
+		}
+		h[i] = v
+	}
+	if h[2] > 64 {
+		return nil, errors.Wrap(ErrInvalidRecording, "layout name too long")
+	}
+	name := make([]byte, h[2])
+	if _, err := io.ReadFull(rr.r, name); err != nil {
+		return nil, errors.Wrap(ErrInvalidRecording, "truncated header")
+	}
+	rr.Start = time.UnixMicro(int64(h[0]))
+	rr.CapsLock = h[1]&recordCapsLock != 0
+	rr.Layout = string(name)
+	rr.last = rr.Start
+	return rr, nil
+}
+
+// Next returns the next key, io.EOF at the end of the recording.
+func (rr *RecordReader) Next() (RecordedKey, error) {
+	var v [6]uint64
+	for i := range v {
+		x, err := binary.ReadUvarint(rr.r)
+		if err == io.EOF && i == 0 {
+			return RecordedKey{}, io.EOF
+		}
+		if err != nil {
+			return RecordedKey{}, errors.Wrap(ErrInvalidRecording, "truncated key")
+		}
+		v[i] = x
+	}
+	rr.last = rr.last.Add(time.Duration(v[0]) * time.Microsecond)
+	return RecordedKey{
+		Time:    rr.last,
+		Message: uint32(v[1]),
+		Key: winapi.KBDLLHOOKSTRUCT{
+			VkCode:      uint32(v[2]),
+			ScanCode:    uint32(v[3]),
+			Flags:       uint32(v[4]),
+			DwExtraInfo: uintptr(v[5]),
+		},
+	}, nil
+}
+
+// Replayer feeds a recording through an Assembler, and its Classifier, the
+// way Monitor does:
+//
+//	p := NewReplayer()
+//	p.Speed = 0
+//	err := p.Replay(ctx, rr, func(ev KeyEvent) {
+//		if ev.Scan != nil {
+//		}
+//	})
+//
+// Events keep their recorded times, so scans come out the same at any speed.
+type Replayer struct {
+	Assembler *Assembler
+	Layout    *Layout // the recording's, or LayoutUS, when nil
+	Speed     float64 // 1 for the original pace, 2 twice as fast, 0 no waiting
+}
+
+func NewReplayer() *Replayer {
+	return &Replayer{Assembler: NewAssembler(), Speed: 1}
+}
+
+// Replay calls fn with every key down until the end of the recording, ctx is
+// checked between keys.
+func (p *Replayer) Replay(ctx context.Context, rr *RecordReader, fn func(KeyEvent)) error {
+	if p.Assembler == nil {
+		p.Assembler = NewAssembler()
+	}
+	layout := p.Layout
+	if layout == nil {
+		layout = LayoutByName(rr.Layout)
+	}
+	if layout == nil {
+		layout = LayoutUS
+	}
+	pipe := &pipeline{asm: p.Assembler}
+	trans := NewTranslator(layout)
+	trans.CapsLock = rr.CapsLock
+
+	begin, prev := time.Now(), time.Time{}
+	for {
+		rk, err := rr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if prev.IsZero() {
+			prev = rk.Time
+		}
+		if p.Speed > 0 {
+			begin = begin.Add(time.Duration(float64(rk.Time.Sub(prev)) / p.Speed))
+			prev = rk.Time
+			timer := time.NewTimer(time.Until(begin))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if rk.Message != winapi.WM_KEYDOWN {
+			trans.Key(rk.Key.VkCode, rk.Message == winapi.WM_SYSKEYDOWN)
+			continue
+		}
+		if rk.Key.DwExtraInfo == replayMarker {
+			continue
+		}
+		ev := KeyEvent{
+			VkCode:   rk.Key.VkCode,
+			ScanCode: rk.Key.ScanCode,
+			Flags:    rk.Key.Flags,
+			Time:     rk.Time,
+		}
+		ev.Rune, _ = trans.Key(rk.Key.VkCode, true)
+		pipe.key(&ev)
+		if fn != nil {
+			fn(ev)
+		}
+	}
+}
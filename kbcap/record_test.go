@@ -0,0 +1,172 @@
+package kbcap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FxStar/winapi"
+)
+
+func replayScans(t *testing.T, p *Replayer, rr *RecordReader) []string {
+	var scans []string
+	err := p.Replay(context.Background(), rr, func(ev KeyEvent) {
+		if ev.Scan != nil {
+			scans = append(scans, ev.Scan.Text)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scans
+}
+
+func openRecording(t *testing.T, name string) *RecordReader {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	rr, err := NewRecordReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr
+}
+
+// testdata/field-de.kbcr was captured on a German layout: "Hallo" typed by
+// hand, an EAN-13 scan, a key replayed by a Gate, then a scan of "LOT/7"
+// where the scanner sends '/' as Shift+7.
+func TestReplayFieldRecording(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(p *Replayer)
+		want  []string
+	}{
+		{"classifier", func(p *Replayer) { p.Assembler.Classifier = NewClassifier() }, []string{"4006381333931", "LOT/7"}},
+		{"no classifier", nil, []string{"Hallo", "4006381333931", "LOT/7"}},
+		{"US layout", func(p *Replayer) { p.Layout = LayoutUS }, []string{"Hallo", "4006381333931", "LOT&7"}},
+	}
+	for _, tt := range tests {
+		rr := openRecording(t, "testdata/field-de.kbcr")
+		if rr.Layout != "de" || rr.CapsLock {
+			t.Fatalf("header: layout %q, CapsLock %v", rr.Layout, rr.CapsLock)
+		}
+		p := NewReplayer()
+		p.Speed = 0
+		if tt.setup != nil {
+			tt.setup(p)
+		}
+		if got := replayScans(t, p, rr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: scans = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf, LayoutFR, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	keys := []RecordedKey{
+		{start.Add(time.Millisecond), winapi.WM_KEYDOWN, winapi.KBDLLHOOKSTRUCT{VkCode: 'A', ScanCode: 0x10}},
+		{start.Add(3 * time.Millisecond), winapi.WM_KEYUP, winapi.KBDLLHOOKSTRUCT{VkCode: 'A', ScanCode: 0x10, Flags: 0x80}},
+		{start.Add(time.Hour), winapi.WM_SYSKEYDOWN, winapi.KBDLLHOOKSTRUCT{VkCode: winapi.VK_RMENU, ScanCode: 0x38, Flags: 0x21, DwExtraInfo: replayMarker}},
+	}
+	for _, k := range keys {
+		k := k
+		if err := rec.Record(k.Time, k.Message, &k.Key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// out of order, stored as simultaneous
+	if err := rec.Record(start, winapi.WM_SYSKEYUP, &winapi.KBDLLHOOKSTRUCT{VkCode: winapi.VK_RMENU}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	rr, err := NewRecordReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.Layout != "fr" || !rr.CapsLock || rr.Start.Sub(start) > time.Second {
+		t.Errorf("header: %q %v %v", rr.Layout, rr.CapsLock, rr.Start)
+	}
+	for _, want := range keys {
+		got, err := rr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Message != want.Message || got.Key != want.Key || got.Time.Sub(want.Time).Abs() >= time.Microsecond {
+			t.Errorf("Next() = %+v, want %+v", got, want)
+		}
+	}
+	if got, err := rr.Next(); err != nil || got.Time.Sub(keys[2].Time).Abs() >= time.Microsecond {
+		t.Errorf("out of order key at %v, %v", got.Time, err)
+	}
+	if _, err := rr.Next(); err != io.EOF {
+		t.Errorf("Next() at the end = %v", err)
+	}
+}
+
+func TestRecordReaderInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf, LayoutUS, false)
+	rec.Record(time.Now(), winapi.WM_KEYDOWN, &winapi.KBDLLHOOKSTRUCT{VkCode: 'A'})
+	rec.Flush()
+	valid := buf.Bytes()
+
+	for name, b := range map[string][]byte{
+		"empty":     nil,
+		"magic":     []byte("KBCX\x01\x00\x00\x00"),
+		"version":   []byte("KBCR\x02\x00\x00\x00"),
+		"truncated": valid[:7],
+		"long name": []byte("KBCR\x01\x00\x00\x7f"),
+	} {
+		if _, err := NewRecordReader(bytes.NewReader(b)); !errors.Is(err, ErrInvalidRecording) {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+
+	rr, err := NewRecordReader(bytes.NewReader(valid[:len(valid)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rr.Next(); !errors.Is(err, ErrInvalidRecording) {
+		t.Errorf("truncated key: error = %v", err)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	f, err := os.ReadFile("testdata/field-de.kbcr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr, _ := NewRecordReader(bytes.NewReader(f))
+	p := NewReplayer()
+	p.Speed = 100 // the recording lasts about 3.5s
+	begin := time.Now()
+	if got := replayScans(t, p, rr); len(got) != 3 {
+		t.Errorf("scans = %q", got)
+	}
+	if d := time.Since(begin); d < 30*time.Millisecond || d > 2*time.Second {
+		t.Errorf("replay took %v", d)
+	}
+
+	rr, _ = NewRecordReader(bytes.NewReader(f))
+	p.Speed = 1
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Replay(ctx, rr, nil); err != context.DeadlineExceeded {
+		t.Errorf("Replay() error = %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package kbcap
+
+import "github.com/FxStar/winapi"
+
+// usScanCodes maps set 1 scan codes to the virtual keys of the US layout,
+// extended keys have 0xe000 added.
+var usScanCodes = map[uint32]uint32{
+	0x01: winapi.VK_ESCAPE,
+	0x02: '1', 0x03: '2', 0x04: '3', 0x05: '4', 0x06: '5',
+	0x07: '6', 0x08: '7', 0x09: '8', 0x0a: '9', 0x0b: '0',
+	0x0c: winapi.VK_OEM_MINUS,
+	0x0d: winapi.VK_OEM_PLUS,
+	0x0e: winapi.VK_BACK,
+	0x0f: winapi.VK_TAB,
+	0x10: 'Q', 0x11: 'W', 0x12: 'E', 0x13: 'R', 0x14: 'T',
+	0x15: 'Y', 0x16: 'U', 0x17: 'I', 0x18: 'O', 0x19: 'P',
+	0x1a: winapi.VK_OEM_4,
+	0x1b: winapi.VK_OEM_6,
+	0x1c: winapi.VK_RETURN,
+	0x1d: winapi.VK_LCONTROL,
+	0x1e: 'A', 0x1f: 'S', 0x20: 'D', 0x21: 'F', 0x22: 'G',
+	0x23: 'H', 0x24: 'J', 0x25: 'K', 0x26: 'L',
+	0x27: winapi.VK_OEM_1,
+	0x28: winapi.VK_OEM_7,
+	0x29: winapi.VK_OEM_3,
+	0x2a: winapi.VK_LSHIFT,
+	0x2b: winapi.VK_OEM_5,
+	0x2c: 'Z', 0x2d: 'X', 0x2e: 'C', 0x2f: 'V', 0x30: 'B',
+	0x31: 'N', 0x32: 'M',
+	0x33: winapi.VK_OEM_COMMA,
+	0x34: winapi.VK_OEM_PERIOD,
+	0x35: winapi.VK_OEM_2,
+	0x36: winapi.VK_RSHIFT,
+	0x37: winapi.VK_MULTIPLY,
+	0x38: winapi.VK_LMENU,
+	0x39: winapi.VK_SPACE,
+	0x3a: winapi.VK_CAPITAL,
+	0x3b: winapi.VK_F1, 0x3c: winapi.VK_F2, 0x3d: winapi.VK_F3, 0x3e: winapi.VK_F4,
+	0x3f: winapi.VK_F5, 0x40: winapi.VK_F6, 0x41: winapi.VK_F7, 0x42: winapi.VK_F8,
+	0x43: winapi.VK_F9, 0x44: winapi.VK_F10, 0x57: winapi.VK_F11, 0x58: winapi.VK_F12,
+	0x45: winapi.VK_NUMLOCK,
+	0x46: winapi.VK_SCROLL,
+	0x47: winapi.VK_NUMPAD7, 0x48: winapi.VK_NUMPAD8, 0x49: winapi.VK_NUMPAD9,
+	0x4b: winapi.VK_NUMPAD4, 0x4c: winapi.VK_NUMPAD5, 0x4d: winapi.VK_NUMPAD6,
+	0x4f: winapi.VK_NUMPAD1, 0x50: winapi.VK_NUMPAD2, 0x51: winapi.VK_NUMPAD3,
+	0x52: winapi.VK_NUMPAD0,
+	0x4a: winapi.VK_SUBTRACT,
+	0x4e: winapi.VK_ADD,
+	0x53: winapi.VK_DECIMAL,
+	0x56: winapi.VK_OEM_102,
+
+	0xe01c: winapi.VK_RETURN,
+	0xe01d: winapi.VK_RCONTROL,
+	0xe035: winapi.VK_DIVIDE,
+	0xe038: winapi.VK_RMENU,
+	0xe047: winapi.VK_HOME,
+	0xe048: winapi.VK_UP,
+	0xe049: winapi.VK_PRIOR,
+	0xe04b: winapi.VK_LEFT,
+	0xe04d: winapi.VK_RIGHT,
+	0xe04f: winapi.VK_END,
+	0xe050: winapi.VK_DOWN,
+	0xe051: winapi.VK_NEXT,
+	0xe052: winapi.VK_INSERT,
+	0xe053: winapi.VK_DELETE,
+	0xe05b: winapi.VK_LWIN,
+	0xe05c: winapi.VK_RWIN,
+	0xe05d: winapi.VK_APPS,
+}
+
+// VirtualKey returns the virtual key of a set 1 scan code in this layout, or
+// 0. The numeric keypad is mapped as with NumLock on.
+func (l *Layout) VirtualKey(scanCode uint32, extended bool) uint32 {
+	if extended {
+		scanCode |= 0xe000
+	}
+	if vk, ok := l.ScanCodes[scanCode]; ok {
+		return vk
+	}
+	return usScanCodes[scanCode]
+}
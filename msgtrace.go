@@ -0,0 +1,164 @@
+package winapi
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// FormatMsg decodes a window message for logging, with the wParam and lParam
+// of the common messages broken down:
+//
+//	0x1A0B2C WM_SIZE type=SIZE_RESTORED width=800 height=600
+//	0x1A0B2C WM_KEYDOWN vk=VK_RETURN repeat=1 scan=0x1C
+//	0x1A0B2C WM_USER+0x4B wParam=0x0 lParam=0x0
+//
+// Pointer lParams are printed, not followed.
+func FormatMsg(hwnd HWND, msg uint32, wParam WPARAM, lParam LPARAM) string {
+	var b strings.Builder
+	b.WriteString(hex(uint32(hwnd)))
+	b.WriteByte(' ')
+	b.WriteString(MessageName(msg))
+	arg := func(key, value string) {
+		b.WriteByte(' ')
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(value)
+	}
+	lo, hi := uint32(wParam)&0xFFFF, uint32(wParam)>>16&0xFFFF
+	x, y := strconv.Itoa(int(int16(lParam))), strconv.Itoa(int(int16(lParam>>16)))
+
+	switch msg {
+	case WM_SIZE:
+		arg("type", sizeNames.name(uint32(wParam)))
+		arg("width", strconv.Itoa(int(lParam&0xFFFF)))
+		arg("height", strconv.Itoa(int(lParam>>16&0xFFFF)))
+	case WM_MOVE, WM_NCHITTEST:
+		arg("x", x)
+		arg("y", y)
+	case WM_ACTIVATE:
+		arg("state", waNames.name(lo))
+		arg("minimized", strconv.FormatBool(hi != 0))
+		arg("other", hex(uint32(lParam)))
+	case WM_SETFOCUS, WM_KILLFOCUS:
+		arg("other", hex(uint32(wParam)))
+	case WM_SHOWWINDOW:
+		arg("show", strconv.FormatBool(wParam != 0))
+		arg("status", strconv.Itoa(int(lParam)))
+	case WM_KEYDOWN, WM_KEYUP, WM_SYSKEYDOWN, WM_SYSKEYUP,
+		WM_CHAR, WM_DEADCHAR, WM_SYSCHAR, WM_SYSDEADCHAR:
+		if msg == WM_KEYDOWN || msg == WM_KEYUP || msg == WM_SYSKEYDOWN || msg == WM_SYSKEYUP {
+			arg("vk", VKName(uint32(wParam)))
+		} else {
+			arg("char", strconv.QuoteRune(rune(wParam)))
+		}
+		arg("repeat", strconv.Itoa(int(lParam&0xFFFF)))
+		arg("scan", hex(uint32(lParam>>16&0xFF)))
+		for _, f := range []struct {
+			bit  uint
+			name string
+		}{{24, "extended"}, {29, "alt"}, {30, "wasdown"}, {31, "up"}} {
+			if lParam>>f.bit&1 != 0 {
+				b.WriteString(" " + f.name)
+			}
+		}
+	case WM_MOUSEMOVE, WM_LBUTTONDOWN, WM_LBUTTONUP, WM_LBUTTONDBLCLK,
+		WM_RBUTTONDOWN, WM_RBUTTONUP, WM_RBUTTONDBLCLK,
+		WM_MBUTTONDOWN, WM_MBUTTONUP, WM_MBUTTONDBLCLK:
+		arg("keys", mkNames.format(uint32(wParam)))
+		arg("x", x)
+		arg("y", y)
+	case WM_XBUTTONDOWN, WM_XBUTTONUP, WM_XBUTTONDBLCLK:
+		arg("keys", mkNames.format(lo))
+		arg("button", strconv.Itoa(int(hi)))
+		arg("x", x)
+		arg("y", y)
+	case WM_MOUSEWHEEL, WM_MOUSEHWHEEL:
+		arg("delta", strconv.Itoa(int(int16(hi))))
+		arg("keys", mkNames.format(lo))
+		arg("x", x)
+		arg("y", y)
+	case WM_SETCURSOR:
+		arg("window", hex(uint32(wParam)))
+		arg("hittest", strconv.Itoa(int(int16(lParam))))
+		arg("trigger", MessageName(uint32(lParam>>16&0xFFFF)))
+	case WM_CONTEXTMENU:
+		arg("window", hex(uint32(wParam)))
+		arg("x", x) // -1, -1 from the keyboard
+		arg("y", y)
+	case WM_COMMAND:
+		arg("id", strconv.Itoa(int(lo)))
+		arg("code", strconv.Itoa(int(hi))) // 0 menu, 1 accelerator, or the notification
+		arg("control", hex(uint32(lParam)))
+	case WM_SYSCOMMAND:
+		arg("cmd", scNames.name(uint32(wParam)&0xFFF0))
+		arg("x", x)
+		arg("y", y)
+	case WM_INITMENUPOPUP:
+		arg("menu", hex(uint32(wParam)))
+		arg("index", strconv.Itoa(int(lParam&0xFFFF)))
+		arg("system", strconv.FormatBool(lParam>>16&0xFFFF != 0))
+	case WM_HOTKEY:
+		arg("id", strconv.Itoa(int(int32(wParam))))
+		arg("mods", modNames.format(uint32(lParam&0xFFFF)))
+		arg("vk", VKName(uint32(lParam>>16&0xFFFF)))
+	case WM_TIMER:
+		arg("id", strconv.Itoa(int(wParam)))
+	case WM_ERASEBKGND:
+		arg("hdc", hex(uint32(wParam)))
+	default:
+		arg("wParam", hex(uint32(wParam)))
+		arg("lParam", hex(uint32(lParam)))
+	}
+	return b.String()
+}
+
+func (m Msg) String() string {
+	return FormatMsg(m.Hwnd, m.Message, WPARAM(m.Wparam), LPARAM(m.Lparam))
+}
+
+// WndProcTracer logs the messages a window procedure receives and what it
+// returns, indented when it is reentered by SendMessage:
+//
+//	t := &winapi.WndProcTracer{Skip: winapi.NoisyMessage}
+//	wc.WndProc = syscall.NewCallback(t.Wrap(wndProc))
+type WndProcTracer struct {
+	Logf func(format string, v ...interface{}) // log.Printf when nil
+	Skip func(msg uint32) bool                 // messages not to log
+
+	depth atomic.Int32
+}
+
+func (t *WndProcTracer) Wrap(wndProc WNDPROC) WNDPROC {
+	return func(hwnd HWND, msg UINT, wParam WPARAM, lParam LPARAM) uintptr {
+		if t.Skip != nil && t.Skip(uint32(msg)) {
+			return wndProc(hwnd, msg, wParam, lParam)
+		}
+		logf := t.Logf
+		if logf == nil {
+			logf = log.Printf
+		}
+		indent := strings.Repeat("  ", int(t.depth.Add(1)-1))
+		defer t.depth.Add(-1)
+		logf("%s> %s", indent, FormatMsg(hwnd, uint32(msg), wParam, lParam))
+		r := wndProc(hwnd, msg, wParam, lParam)
+		logf("%s< %s = %#x", indent, MessageName(uint32(msg)), r)
+		return r
+	}
+}
+
+// TraceWndProc wraps wndProc with a WndProcTracer logging with log.Printf.
+func TraceWndProc(wndProc WNDPROC) WNDPROC {
+	return (&WndProcTracer{}).Wrap(wndProc)
+}
+
+// NoisyMessage reports the messages that come in floods while the mouse moves,
+// for WndProcTracer.Skip.
+func NoisyMessage(msg uint32) bool {
+	switch msg {
+	case WM_MOUSEMOVE, WM_NCMOUSEMOVE, WM_NCHITTEST, WM_SETCURSOR, WM_MOUSEHOVER, WM_ENTERIDLE:
+		return true
+	}
+	return false
+}
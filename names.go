@@ -0,0 +1,153 @@
+package winapi
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The tables are generated by cmd/mknames into znames.go from the constants
+// of this package, rerun go generate after adding some.
+
+//go:generate go run ./cmd/mknames -output znames.go WM_ VK_ SC_ SIZE_ WA_ SWP_:flags WS_:flags WS_EX_:flags MB_:flags:0xF,0xF0,0xF00,0x3000 TPM_:flags:0xC,0x30 MK_:flags MOD_:flags
+
+type constName struct {
+	value uint32
+	name  string
+}
+
+// enumNames is sorted by value, one name per value.
+type enumNames []constName
+
+func (t enumNames) lookup(v uint32) (string, bool) {
+	i := sort.Search(len(t), func(i int) bool { return t[i].value >= v })
+	if i < len(t) && t[i].value == v {
+		return t[i].name, true
+	}
+	return "", false
+}
+
+func (t enumNames) name(v uint32) string {
+	if s, ok := t.lookup(v); ok {
+		return s
+	}
+	return hex(v)
+}
+
+// flagNames decomposes a bitmask. The bits under masks hold enumerated
+// values, the others are flags; names has combined flags first.
+type flagNames struct {
+	zero  string
+	masks []uint32
+	names []constName
+}
+
+func (t *flagNames) format(v uint32) string {
+	if v == 0 {
+		if t.zero != "" {
+			return t.zero
+		}
+		return "0"
+	}
+	var parts []string
+	var masked uint32
+	for _, m := range t.masks {
+		masked |= m
+		field := v & m
+		if field == 0 {
+			continue
+		}
+		for _, c := range t.names {
+			if c.value == field {
+				parts = append(parts, c.name)
+				v &^= field
+				break
+			}
+		}
+	}
+	for _, c := range t.names {
+		if c.value&masked == 0 && v&c.value == c.value {
+			parts = append(parts, c.name)
+			v &^= c.value
+		}
+	}
+	if v != 0 {
+		parts = append(parts, hex(v))
+	}
+	return strings.Join(parts, "|")
+}
+
+func hex(v uint32) string {
+	return "0x" + strings.ToUpper(strconv.FormatUint(uint64(v), 16))
+}
+
+// MessageName returns the name of a window message: WM_SYSCOMMAND for 0x112,
+// WM_USER+0x5, WM_APP+0x10, or the number for registered and unknown ones.
+func MessageName(msg uint32) string {
+	if s, ok := wmNames.lookup(msg); ok {
+		return s
+	}
+	switch {
+	case msg >= WM_USER && msg < WM_APP:
+		return "WM_USER+" + hex(msg-WM_USER)
+	case msg >= WM_APP && msg < 0xC000:
+		return "WM_APP+" + hex(msg-WM_APP)
+	}
+	return hex(msg)
+}
+
+// VKName returns the name of a virtual key, VK_RETURN, or the character of the
+// keys that are their own character, A and 0.
+func VKName(vk uint32) string {
+	if s, ok := vkNames.lookup(vk); ok {
+		return s
+	}
+	if '0' <= vk && vk <= '9' || 'A' <= vk && vk <= 'Z' {
+		return string(rune(vk))
+	}
+	return hex(vk)
+}
+
+// ParseVK is the reverse of VKName, case insensitive: VK_RETURN, A or 0x97.
+func ParseVK(name string) (uint32, bool) {
+	for _, c := range vkNames {
+		if strings.EqualFold(c.name, name) {
+			return c.value, true
+		}
+	}
+	if len(name) == 1 {
+		if vk := uint32(strings.ToUpper(name)[0]); '0' <= vk && vk <= '9' || 'A' <= vk && vk <= 'Z' {
+			return vk, true
+		}
+	}
+	if strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X") {
+		if vk, err := strconv.ParseUint(name[2:], 16, 8); err == nil && vk != 0 {
+			return uint32(vk), true
+		}
+	}
+	return 0, false
+}
+
+// SWPNames decomposes SetWindowPos flags: SWP_NOSIZE|SWP_NOMOVE.
+func SWPNames(flags uint32) string {
+	return swpNames.format(flags)
+}
+
+// WSNames decomposes a window style, combined styles first:
+// WS_OVERLAPPEDWINDOW|WS_VISIBLE.
+func WSNames(style uint32) string {
+	return wsNames.format(style)
+}
+
+func WSEXNames(exStyle uint32) string {
+	return wsExNames.format(exStyle)
+}
+
+// MBNames decomposes MessageBox flags: MB_YESNO|MB_ICONQUESTION|MB_TOPMOST.
+func MBNames(flags BoxType) string {
+	return mbNames.format(uint32(flags))
+}
+
+func TPMNames(flags uint32) string {
+	return tpmNames.format(flags)
+}
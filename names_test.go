@@ -0,0 +1,106 @@
+package winapi
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMessageName(t *testing.T) {
+	tests := []struct {
+		msg  uint32
+		want string
+	}{
+		{WM_SYSCOMMAND, "WM_SYSCOMMAND"},
+		{WM_SIZE, "WM_SIZE"},
+		{WM_USER, "WM_USER"},
+		{WM_USER + 1, "WM_USER+0x1"},
+		{WM_USER + 0x4B, "WM_USER+0x4B"},
+		{WM_APP - 1, "WM_USER+0x7BFF"},
+		{WM_APP, "WM_APP"},
+		{WM_APP + 0x10, "WM_APP+0x10"},
+		{0xBFFF, "WM_APP+0x3FFF"},
+		{0xC000, "0xC000"}, // RegisterWindowMessage
+		{0x3FF, "0x3FF"},
+	}
+	for _, tt := range tests {
+		if s := MessageName(tt.msg); s != tt.want {
+			t.Errorf("MessageName(%#x) = %q, want %q", tt.msg, s, tt.want)
+		}
+	}
+}
+
+func TestFlagNames(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(uint32) string
+		v    uint32
+		want string
+	}{
+		{"WSNames", WSNames, WS_OVERLAPPEDWINDOW | WS_VISIBLE, "WS_OVERLAPPEDWINDOW|WS_VISIBLE"},
+		{"WSNames", WSNames, WS_OVERLAPPED, "WS_OVERLAPPED"},
+		{"WSNames", WSNames, WS_CHILD | WS_VISIBLE | 0x1, "WS_CHILD|WS_VISIBLE|0x1"},
+		{"WSEXNames", WSEXNames, WS_EX_TOOLWINDOW | WS_EX_TOPMOST, "WS_EX_TOPMOST|WS_EX_TOOLWINDOW"},
+		{"SWPNames", SWPNames, SWP_NOSIZE | SWP_NOMOVE | SWP_NOZORDER, "SWP_NOSIZE|SWP_NOMOVE|SWP_NOZORDER"},
+		{"TPMNames", TPMNames, TPM_RIGHTALIGN | TPM_BOTTOMALIGN | TPM_RETURNCMD, "TPM_RIGHTALIGN|TPM_BOTTOMALIGN|TPM_RETURNCMD"},
+		{"TPMNames", TPMNames, 0, "TPM_LEFTBUTTON"},
+		{"MBNames", func(v uint32) string { return MBNames(BoxType(v)) }, uint32(MB_ICONWARNING | MB_YESNO), "MB_YESNO|MB_ICONEXCLAMATION"},
+		{"MBNames", func(v uint32) string { return MBNames(BoxType(v)) }, uint32(MB_OK), "MB_OK"},
+	}
+	for _, tt := range tests {
+		if s := tt.f(tt.v); s != tt.want {
+			t.Errorf("%s(%#x) = %q, want %q", tt.name, tt.v, s, tt.want)
+		}
+	}
+}
+
+func TestFormatMsg(t *testing.T) {
+	tests := []struct {
+		msg    uint32
+		wParam WPARAM
+		lParam LPARAM
+		want   string
+	}{
+		{WM_SIZE, SIZE_MAXIMIZED, 600<<16 | 800, "0x1A0B2C WM_SIZE type=SIZE_MAXIMIZED width=800 height=600"},
+		{WM_SYSCOMMAND, SC_CLOSE | 0x3, 20<<16 | 0xFFF6, "0x1A0B2C WM_SYSCOMMAND cmd=SC_CLOSE x=-10 y=20"},
+		{WM_USER + 0x4B, 1, 2, "0x1A0B2C WM_USER+0x4B wParam=0x1 lParam=0x2"},
+	}
+	for _, tt := range tests {
+		if s := FormatMsg(0x1A0B2C, tt.msg, tt.wParam, tt.lParam); s != tt.want {
+			t.Errorf("FormatMsg(%s) = %q, want %q", MessageName(tt.msg), s, tt.want)
+		}
+	}
+}
+
+func TestWndProcTracer(t *testing.T) {
+	var lines []string
+	tr := &WndProcTracer{
+		Logf: func(format string, v ...interface{}) { lines = append(lines, fmt.Sprintf(format, v...)) },
+		Skip: func(msg uint32) bool { return msg == WM_TIMER },
+	}
+	var traced WNDPROC
+	traced = tr.Wrap(func(hwnd HWND, msg UINT, wParam WPARAM, lParam LPARAM) uintptr {
+		switch msg {
+		case WM_SYSCOMMAND: // as DefWindowProc sends WM_CLOSE
+			traced(hwnd, WM_TIMER, 1, 0)
+			return traced(hwnd, WM_CLOSE, 0, 0)
+		case WM_CLOSE:
+			return traced(hwnd, WM_DESTROY, 0, 0) + 1
+		}
+		return 0
+	})
+	if r := traced(0x10, WM_SYSCOMMAND, SC_CLOSE, 0); r != 1 {
+		t.Errorf("traced WM_SYSCOMMAND = %d, want 1", r)
+	}
+	want := []string{
+		"> 0x10 WM_SYSCOMMAND cmd=SC_CLOSE x=0 y=0",
+		"  > 0x10 WM_CLOSE wParam=0x0 lParam=0x0",
+		"    > 0x10 WM_DESTROY wParam=0x0 lParam=0x0",
+		"    < WM_DESTROY = 0x0",
+		"  < WM_CLOSE = 0x1",
+		"< WM_SYSCOMMAND = 0x1",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("traced lines:\n%q\nwant:\n%q", lines, want)
+	}
+}
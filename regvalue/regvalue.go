@@ -0,0 +1,145 @@
+// Package regvalue converts registry style (type, bytes) values, as returned by
+// RegQueryValueEx, SetupDiGetDeviceRegistryProperty or EnumPrinterData, to Go
+// values and back. It has no system dependency.
+package regvalue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/FxStar/winapi/wstr"
+)
+
+// Registry value types.
+const (
+	REG_NONE                       = 0
+	REG_SZ                         = 1
+	REG_EXPAND_SZ                  = 2
+	REG_BINARY                     = 3
+	REG_DWORD                      = 4
+	REG_DWORD_LITTLE_ENDIAN        = 4
+	REG_DWORD_BIG_ENDIAN           = 5
+	REG_LINK                       = 6
+	REG_MULTI_SZ                   = 7
+	REG_RESOURCE_LIST              = 8
+	REG_FULL_RESOURCE_DESCRIPTOR   = 9
+	REG_RESOURCE_REQUIREMENTS_LIST = 10
+	REG_QWORD                      = 11
+	REG_QWORD_LITTLE_ENDIAN        = 11
+)
+
+// ExpandString is a REG_EXPAND_SZ value, its %VAR% references are left as is.
+type ExpandString string
+
+var (
+	ErrShortData   = errors.New("registry value data too short")
+	ErrUnsupported = errors.New("unsupported registry value")
+)
+
+// Decode converts data of the given type to:
+//
+//	REG_SZ, REG_LINK                string
+//	REG_EXPAND_SZ                   ExpandString
+//	REG_MULTI_SZ                    []string
+//	REG_DWORD, REG_DWORD_BIG_ENDIAN uint32
+//	REG_QWORD                       uint64
+//	anything else                   []byte
+func Decode(typ uint32, data []byte) (interface{}, error) {
+	switch typ {
+	case REG_SZ, REG_LINK:
+		return DecodeString(data), nil
+	case REG_EXPAND_SZ:
+		return ExpandString(DecodeString(data)), nil
+	case REG_MULTI_SZ:
+		return DecodeStrings(data), nil
+	case REG_DWORD:
+		if len(data) < 4 {
+			return nil, ErrShortData
+		}
+		return binary.LittleEndian.Uint32(data), nil
+	case REG_DWORD_BIG_ENDIAN:
+		if len(data) < 4 {
+			return nil, ErrShortData
+		}
+		return binary.BigEndian.Uint32(data), nil
+	case REG_QWORD:
+		if len(data) < 8 {
+			return nil, ErrShortData
+		}
+		return binary.LittleEndian.Uint64(data), nil
+	}
+	return data, nil
+}
+
+// DecodeString decodes UTF-16LE data up to the first NUL, the terminator may be missing.
+func DecodeString(data []byte) string {
+	return wstr.FromArray(toUint16s(data))
+}
+
+// DecodeStrings decodes a REG_MULTI_SZ list, it stops at the empty string that
+// terminates the list and tolerates missing terminators.
+func DecodeStrings(data []byte) []string {
+	return wstr.Split(toUint16s(data))
+}
+
+// Encode is the reverse of Decode, for string, ExpandString, []string,
+// uint32, uint64 and []byte values.
+func Encode(v interface{}) (typ uint32, data []byte, err error) {
+	switch v := v.(type) {
+	case string:
+		return REG_SZ, EncodeString(v), nil
+	case ExpandString:
+		return REG_EXPAND_SZ, EncodeString(string(v)), nil
+	case []string:
+		data, err = EncodeStrings(v)
+		return REG_MULTI_SZ, data, err
+	case uint32:
+		data = make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, v)
+		return REG_DWORD, data, nil
+	case uint64:
+		data = make([]byte, 8)
+		binary.LittleEndian.PutUint64(data, v)
+		return REG_QWORD, data, nil
+	case []byte:
+		return REG_BINARY, v, nil
+	}
+	return REG_NONE, nil, fmt.Errorf("%w: %T", ErrUnsupported, v)
+}
+
+// EncodeString encodes s as NUL terminated UTF-16LE.
+func EncodeString(s string) []byte {
+	return fromUint16s(append(utf16.Encode([]rune(s)), 0))
+}
+
+// EncodeStrings encodes a REG_MULTI_SZ list, which can't hold empty strings.
+func EncodeStrings(list []string) ([]byte, error) {
+	var u []uint16
+	for _, s := range list {
+		if s == "" {
+			return nil, fmt.Errorf("%w: empty string in REG_MULTI_SZ", ErrUnsupported)
+		}
+		u = append(u, utf16.Encode([]rune(s))...)
+		u = append(u, 0)
+	}
+	u = append(u, 0)
+	return fromUint16s(u), nil
+}
+
+func toUint16s(data []byte) []uint16 {
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return u
+}
+
+func fromUint16s(u []uint16) []byte {
+	data := make([]byte, len(u)*2)
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(data[i*2:], c)
+	}
+	return data
+}
@@ -0,0 +1,121 @@
+package regvalue
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// utf16le encodes ASCII s as UTF-16LE, without adding a terminator.
+func utf16le(s string) []byte {
+	b := make([]byte, 0, 2*len(s))
+	for i := 0; i < len(s); i++ {
+		b = append(b, s[i], 0)
+	}
+	return b
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  uint32
+		data []byte
+		want interface{}
+	}{
+		{"REG_SZ", REG_SZ, utf16le("abc\x00"), "abc"},
+		{"REG_SZ without terminator", REG_SZ, utf16le("abc"), "abc"},
+		{"REG_SZ padded", REG_SZ, utf16le("abc\x00\x00x"), "abc"},
+		{"REG_LINK", REG_LINK, utf16le(`\Registry\x` + "\x00"), `\Registry\x`},
+		{"REG_EXPAND_SZ", REG_EXPAND_SZ, utf16le(`%SystemRoot%\inf` + "\x00"), ExpandString(`%SystemRoot%\inf`)},
+		{"REG_MULTI_SZ", REG_MULTI_SZ, utf16le("a\x00bc\x00def\x00\x00"), []string{"a", "bc", "def"}},
+		{"REG_MULTI_SZ without final terminator", REG_MULTI_SZ, utf16le("a\x00bc\x00"), []string{"a", "bc"}},
+		{"REG_MULTI_SZ without terminators", REG_MULTI_SZ, utf16le("a\x00bc"), []string{"a", "bc"}},
+		{"REG_MULTI_SZ odd length", REG_MULTI_SZ, append(utf16le("a\x00bc\x00\x00"), 'x'), []string{"a", "bc"}},
+		{"REG_MULTI_SZ stops at the empty string", REG_MULTI_SZ, utf16le("a\x00\x00b\x00\x00"), []string{"a"}},
+		{"REG_MULTI_SZ empty", REG_MULTI_SZ, utf16le("\x00"), []string(nil)},
+		{"REG_DWORD", REG_DWORD, []byte{1, 2, 3, 4}, uint32(0x04030201)},
+		{"REG_DWORD_BIG_ENDIAN", REG_DWORD_BIG_ENDIAN, []byte{1, 2, 3, 4}, uint32(0x01020304)},
+		{"REG_QWORD", REG_QWORD, []byte{1, 2, 3, 4, 5, 6, 7, 8}, uint64(0x0807060504030201)},
+		{"REG_BINARY", REG_BINARY, []byte{1, 2}, []byte{1, 2}},
+		{"REG_NONE", REG_NONE, []byte{1}, []byte{1}},
+	}
+	for _, tt := range tests {
+		got, err := Decode(tt.typ, tt.data)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Decode(%d, % x) = %#v, %v, want %#v", tt.name, tt.typ, tt.data, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeShortData(t *testing.T) {
+	tests := []struct {
+		typ  uint32
+		data []byte
+	}{
+		{REG_DWORD, nil},
+		{REG_DWORD, []byte{1, 2, 3}},
+		{REG_DWORD_BIG_ENDIAN, []byte{1, 2, 3}},
+		{REG_QWORD, []byte{1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		if v, err := Decode(tt.typ, tt.data); err != ErrShortData {
+			t.Errorf("Decode(%d, % x) = %v, %v, want ErrShortData", tt.typ, tt.data, v, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		typ  uint32
+		data []byte
+	}{
+		{"abc", REG_SZ, utf16le("abc\x00")},
+		{"", REG_SZ, utf16le("\x00")},
+		{ExpandString("%TEMP%"), REG_EXPAND_SZ, utf16le("%TEMP%\x00")},
+		{[]string{"a", "bc"}, REG_MULTI_SZ, utf16le("a\x00bc\x00\x00")},
+		{[]string{}, REG_MULTI_SZ, utf16le("\x00")},
+		{uint32(0x04030201), REG_DWORD, []byte{1, 2, 3, 4}},
+		{uint64(0x0807060504030201), REG_QWORD, []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+		{[]byte{1, 2}, REG_BINARY, []byte{1, 2}},
+	}
+	for _, tt := range tests {
+		typ, data, err := Encode(tt.v)
+		if err != nil || typ != tt.typ || !reflect.DeepEqual(data, tt.data) {
+			t.Errorf("Encode(%#v) = %d, % x, %v, want %d, % x", tt.v, typ, data, err, tt.typ, tt.data)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, v := range []interface{}{
+		"Brother HL-2130",
+		"a😀b",
+		ExpandString(`%ProgramFiles%\app`),
+		[]string{`USB\VID_04F9&PID_2058&REV_0100`, `USB\VID_04F9&PID_2058`, "é"},
+		uint32(0xdeadbeef),
+		uint64(0xdeadbeefcafe),
+		[]byte{0, 1, 2},
+	} {
+		typ, data, err := Encode(v)
+		if err != nil {
+			t.Errorf("Encode(%#v): %v", v, err)
+			continue
+		}
+		if got, err := Decode(typ, data); err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("Decode(Encode(%#v)) = %#v, %v", v, got, err)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := EncodeStrings([]string{"a", "", "b"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("EncodeStrings with an empty string = %v, want ErrUnsupported", err)
+	}
+	if _, _, err := Encode([]string{""}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Encode([]string{\"\"}) = %v, want ErrUnsupported", err)
+	}
+	if _, _, err := Encode(3); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Encode(3) = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,162 @@
+package setupapi
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/regvalue"
+)
+
+// WM_DEVICECHANGE events, see dbt.h
+const (
+	DBT_DEVICEARRIVAL        = 0x8000
+	DBT_DEVICEREMOVECOMPLETE = 0x8004
+
+	DBT_DEVTYP_VOLUME          = 0x00000002
+	DBT_DEVTYP_PORT            = 0x00000003
+	DBT_DEVTYP_DEVICEINTERFACE = 0x00000005
+	DBT_DEVTYP_HANDLE          = 0x00000006
+
+	DEVICE_NOTIFY_WINDOW_HANDLE         = 0x00000000
+	DEVICE_NOTIFY_ALL_INTERFACE_CLASSES = 0x00000004
+)
+
+// DevBroadcast is a decoded DEV_BROADCAST_DEVICEINTERFACE_W or DEV_BROADCAST_PORT_W.
+type DevBroadcast struct {
+	DeviceType uint32
+	ClassGUID  winapi.GUID // DBT_DEVTYP_DEVICEINTERFACE only
+	Name       string      // interface path, or port name such as COM3
+}
+
+var ErrInvalidBroadcast = errors.New("invalid device broadcast")
+
+// DecodeDevBroadcast decodes the structure WM_DEVICECHANGE passes in lParam,
+// b holds at least dbch_size bytes of it.
+//
+//	DEV_BROADCAST_HDR:              dbch_size, dbch_devicetype, dbch_reserved DWORD
+//	DEV_BROADCAST_DEVICEINTERFACE_W: hdr, dbcc_classguid GUID, dbcc_name WCHAR[]
+//	DEV_BROADCAST_PORT_W:            hdr, dbcp_name WCHAR[]
+func DecodeDevBroadcast(b []byte) (*DevBroadcast, error) {
+	if len(b) < 12 {
+		return nil, ErrInvalidBroadcast
+	}
+	size := int(binary.LittleEndian.Uint32(b))
+	if size < 12 || size > len(b) {
+		return nil, ErrInvalidBroadcast
+	}
+	b = b[:size]
+	db := &DevBroadcast{DeviceType: binary.LittleEndian.Uint32(b[4:])}
+	switch db.DeviceType {
+	case DBT_DEVTYP_DEVICEINTERFACE:
+		if len(b) < 28 {
+			return nil, ErrInvalidBroadcast
+		}
+		db.ClassGUID = guidFromBytes(b[12:28])
+		db.Name = regvalue.DecodeString(b[28:])
+	case DBT_DEVTYP_PORT:
+		db.Name = regvalue.DecodeString(b[12:])
+	}
+	return db, nil
+}
+
+func guidFromBytes(b []byte) (g winapi.GUID) {
+	g.Data1 = winapi.ULONG(binary.LittleEndian.Uint32(b))
+	g.Data2 = winapi.WORD(binary.LittleEndian.Uint16(b[4:]))
+	g.Data3 = winapi.WORD(binary.LittleEndian.Uint16(b[6:]))
+	for i := range g.Data4 {
+		g.Data4[i] = winapi.BYTE(b[8+i])
+	}
+	return g
+}
+
+type DeviceEventType int
+
+const (
+	DeviceAdded DeviceEventType = iota + 1
+	DeviceRemoved
+)
+
+func (t DeviceEventType) String() string {
+	switch t {
+	case DeviceAdded:
+		return "added"
+	case DeviceRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+type DeviceEvent struct {
+	Type      DeviceEventType
+	Port      bool // DBT_DEVTYP_PORT, Name is the port such as COM3
+	ClassGUID winapi.GUID
+	Path      *DevicePath // nil for ports and names that don't parse
+	Name      string      // raw interface path or port name
+}
+
+// WatchFilter selects the events a DeviceWatcher delivers. Empty ClassGUIDs
+// means every interface class, VID and PID are ignored when 0. Port events,
+// which have no class, only pass with Ports.
+type WatchFilter struct {
+	ClassGUIDs []winapi.GUID
+	VID, PID   uint16
+	Ports      bool
+}
+
+func (f *WatchFilter) Match(ev *DeviceEvent) bool {
+	if ev.Port {
+		if !f.Ports {
+			return false
+		}
+	} else if len(f.ClassGUIDs) > 0 {
+		found := false
+		for _, g := range f.ClassGUIDs {
+			if g == ev.ClassGUID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.VID == 0 && f.PID == 0 {
+		return true
+	}
+	if ev.Path == nil || !ev.Path.HasVidPid() {
+		return false
+	}
+	return (f.VID == 0 || int(f.VID) == ev.Path.VID) && (f.PID == 0 || int(f.PID) == ev.Path.PID)
+}
+
+// decodeDeviceChange turns a WM_DEVICECHANGE wParam and lParam payload into an event.
+func decodeDeviceChange(wParam uintptr, payload []byte) (*DeviceEvent, bool) {
+	ev := &DeviceEvent{}
+	switch wParam {
+	case DBT_DEVICEARRIVAL:
+		ev.Type = DeviceAdded
+	case DBT_DEVICEREMOVECOMPLETE:
+		ev.Type = DeviceRemoved
+	default:
+		return nil, false
+	}
+	db, err := DecodeDevBroadcast(payload)
+	if err != nil {
+		return nil, false
+	}
+	switch db.DeviceType {
+	case DBT_DEVTYP_DEVICEINTERFACE:
+		ev.ClassGUID = db.ClassGUID
+		ev.Name = db.Name
+		if p, err := ParseDevicePath(db.Name); err == nil {
+			ev.Path = p
+		}
+	case DBT_DEVTYP_PORT:
+		ev.Port = true
+		ev.Name = db.Name
+	default:
+		return nil, false
+	}
+	return ev, true
+}
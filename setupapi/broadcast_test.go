@@ -0,0 +1,131 @@
+package setupapi
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/regvalue"
+)
+
+const usbPrintPath = `\\?\usbprint#vid_04b8&pid_0e15#7&2a7c3f6&0&usb001#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}`
+
+var (
+	usbPrintGUID, _ = winapi.ParseGUID("{28d78fad-5a12-11d1-ae5b-0000f803a8c2}")
+	hidGUID, _      = winapi.ParseGUID("{4d1e55b2-f16f-11cf-88cb-001111000030}")
+)
+
+// broadcast builds a DEV_BROADCAST_DEVICEINTERFACE_W, or a DEV_BROADCAST_PORT_W
+// when class is nil.
+func broadcast(class *winapi.GUID, name string) []byte {
+	b := make([]byte, 12)
+	binary.LittleEndian.PutUint32(b[4:], DBT_DEVTYP_PORT)
+	if class != nil {
+		binary.LittleEndian.PutUint32(b[4:], DBT_DEVTYP_DEVICEINTERFACE)
+		b = binary.LittleEndian.AppendUint32(b, uint32(class.Data1))
+		b = binary.LittleEndian.AppendUint16(b, uint16(class.Data2))
+		b = binary.LittleEndian.AppendUint16(b, uint16(class.Data3))
+		for _, c := range class.Data4 {
+			b = append(b, byte(c))
+		}
+	}
+	b = append(b, regvalue.EncodeString(name)...)
+	binary.LittleEndian.PutUint32(b, uint32(len(b)))
+	return b
+}
+
+func TestDecodeDevBroadcast(t *testing.T) {
+	b := broadcast(&usbPrintGUID, usbPrintPath)
+	db, err := DecodeDevBroadcast(append(b, 0xff, 0xff)) // bytes past dbch_size are ignored
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.DeviceType != DBT_DEVTYP_DEVICEINTERFACE || db.ClassGUID != usbPrintGUID || db.Name != usbPrintPath {
+		t.Errorf("DecodeDevBroadcast() = %+v", db)
+	}
+
+	db, err = DecodeDevBroadcast(broadcast(nil, "COM3"))
+	if err != nil || db.DeviceType != DBT_DEVTYP_PORT || db.Name != "COM3" {
+		t.Errorf("DecodeDevBroadcast() = %+v, %v", db, err)
+	}
+
+	// the name may lack its NUL
+	b = broadcast(nil, "COM4")
+	b = b[:len(b)-2]
+	binary.LittleEndian.PutUint32(b, uint32(len(b)))
+	if db, err = DecodeDevBroadcast(b); err != nil || db.Name != "COM4" {
+		t.Errorf("DecodeDevBroadcast() = %+v, %v", db, err)
+	}
+}
+
+func TestDecodeDevBroadcastInvalid(t *testing.T) {
+	short := broadcast(&usbPrintGUID, "")[:20]
+	binary.LittleEndian.PutUint32(short, 20)
+	oversized := broadcast(nil, "COM3")
+	binary.LittleEndian.PutUint32(oversized, uint32(len(oversized)+1))
+	for name, b := range map[string][]byte{
+		"empty":     nil,
+		"header":    {12, 0, 0, 0, 5, 0, 0},
+		"size < 12": {8, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0},
+		"oversized": oversized,
+		"no guid":   short,
+	} {
+		if _, err := DecodeDevBroadcast(b); err != ErrInvalidBroadcast {
+			t.Errorf("%s: error = %v", name, err)
+		}
+	}
+}
+
+func TestDecodeDeviceChange(t *testing.T) {
+	ev, ok := decodeDeviceChange(DBT_DEVICEARRIVAL, broadcast(&usbPrintGUID, usbPrintPath))
+	if !ok || ev.Type != DeviceAdded || ev.Port || ev.Path == nil || ev.Path.VID != 0x04b8 || ev.Path.PID != 0x0e15 {
+		t.Errorf("decodeDeviceChange() = %+v, %v", ev, ok)
+	}
+	ev, ok = decodeDeviceChange(DBT_DEVICEREMOVECOMPLETE, broadcast(nil, "COM3"))
+	if !ok || ev.Type != DeviceRemoved || !ev.Port || ev.Path != nil || ev.Name != "COM3" {
+		t.Errorf("decodeDeviceChange() = %+v, %v", ev, ok)
+	}
+	if _, ok := decodeDeviceChange(0x0007, broadcast(nil, "COM3")); ok { // DBT_DEVNODES_CHANGED
+		t.Error("decodeDeviceChange() accepted another event")
+	}
+}
+
+func TestWatchFilter(t *testing.T) {
+	event := func(class *winapi.GUID, name string) *DeviceEvent {
+		ev, ok := decodeDeviceChange(DBT_DEVICEARRIVAL, broadcast(class, name))
+		if !ok {
+			t.Fatalf("decodeDeviceChange(%q) failed", name)
+		}
+		return ev
+	}
+	printer := event(&usbPrintGUID, usbPrintPath)
+	hidUnparsed := event(&hidGUID, `\\?\garbage`)
+	port := event(nil, "COM3")
+
+	usbPrint := WatchFilter{ClassGUIDs: []winapi.GUID{usbPrintGUID}}
+	tests := []struct {
+		name   string
+		filter WatchFilter
+		ev     *DeviceEvent
+		want   bool
+	}{
+		{"all", WatchFilter{}, printer, true},
+		{"all, unparsed name", WatchFilter{}, hidUnparsed, true},
+		{"all, port", WatchFilter{}, port, false},
+		{"class", usbPrint, printer, true},
+		{"class, unparsed name of another class", usbPrint, hidUnparsed, false},
+		{"class, port", usbPrint, port, false},
+		{"ports", WatchFilter{Ports: true}, port, true},
+		{"class and ports", WatchFilter{ClassGUIDs: usbPrint.ClassGUIDs, Ports: true}, port, true},
+		{"vid", WatchFilter{VID: 0x04b8}, printer, true},
+		{"vid and pid", WatchFilter{VID: 0x04b8, PID: 0x0e15}, printer, true},
+		{"other pid", WatchFilter{VID: 0x04b8, PID: 0x0e28}, printer, false},
+		{"vid, unparsed name", WatchFilter{VID: 0x04b8}, hidUnparsed, false},
+		{"vid, port", WatchFilter{VID: 0x04b8, Ports: true}, port, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(tt.ev); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
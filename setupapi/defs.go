@@ -0,0 +1,75 @@
+package setupapi
+
+import "github.com/FxStar/winapi"
+
+// Flags controlling what is included in the device information set built by SetupDiGetClassDevs
+const (
+	DIGCF_DEFAULT         = 0x00000001 // only valid with DIGCF_DEVICEINTERFACE
+	DIGCF_PRESENT         = 0x00000002
+	DIGCF_ALLCLASSES      = 0x00000004
+	DIGCF_PROFILE         = 0x00000008
+	DIGCF_DEVICEINTERFACE = 0x00000010
+)
+
+// Device registry property codes
+const (
+	SPDRP_DEVICEDESC                  = 0x00000000 // DeviceDesc (R/W)
+	SPDRP_HARDWAREID                  = 0x00000001 // HardwareID (R/W)
+	SPDRP_COMPATIBLEIDS               = 0x00000002 // CompatibleIDs (R/W)
+	SPDRP_UNUSED0                     = 0x00000003 // unused
+	SPDRP_SERVICE                     = 0x00000004 // Service (R/W)
+	SPDRP_UNUSED1                     = 0x00000005 // unused
+	SPDRP_UNUSED2                     = 0x00000006 // unused
+	SPDRP_CLASS                       = 0x00000007 // Class (R--tied to ClassGUID)
+	SPDRP_CLASSGUID                   = 0x00000008 // ClassGUID (R/W)
+	SPDRP_DRIVER                      = 0x00000009 // Driver (R/W)
+	SPDRP_CONFIGFLAGS                 = 0x0000000A // ConfigFlags (R/W)
+	SPDRP_MFG                         = 0x0000000B // Mfg (R/W)
+	SPDRP_FRIENDLYNAME                = 0x0000000C // FriendlyName (R/W)
+	SPDRP_LOCATION_INFORMATION        = 0x0000000D // LocationInformation (R/W)
+	SPDRP_PHYSICAL_DEVICE_OBJECT_NAME = 0x0000000E // PhysicalDeviceObjectName (R)
+	SPDRP_CAPABILITIES                = 0x0000000F // Capabilities (R)
+	SPDRP_UI_NUMBER                   = 0x00000010 // UiNumber (R)
+	SPDRP_UPPERFILTERS                = 0x00000011 // UpperFilters (R/W)
+	SPDRP_LOWERFILTERS                = 0x00000012 // LowerFilters (R/W)
+	SPDRP_BUSTYPEGUID                 = 0x00000013 // BusTypeGUID (R)
+	SPDRP_LEGACYBUSTYPE               = 0x00000014 // LegacyBusType (R)
+	SPDRP_BUSNUMBER                   = 0x00000015 // BusNumber (R)
+	SPDRP_ENUMERATOR_NAME             = 0x00000016 // Enumerator Name (R)
+	SPDRP_SECURITY                    = 0x00000017 // Security (R/W, binary form)
+	SPDRP_SECURITY_SDS                = 0x00000018 // Security (W, SDS form)
+	SPDRP_DEVTYPE                     = 0x00000019 // Device Type (R/W)
+	SPDRP_EXCLUSIVE                   = 0x0000001A // Device is exclusive-access (R/W)
+	SPDRP_CHARACTERISTICS             = 0x0000001B // Device Characteristics (R/W)
+	SPDRP_ADDRESS                     = 0x0000001C // Device Address (R)
+	SPDRP_UI_NUMBER_DESC_FORMAT       = 0x0000001D // UiNumberDescFormat (R/W)
+	SPDRP_DEVICE_POWER_DATA           = 0x0000001E // Device Power Data (R)
+	SPDRP_REMOVAL_POLICY              = 0x0000001F // Removal Policy (R)
+	SPDRP_REMOVAL_POLICY_HW_DEFAULT   = 0x00000020 // Hardware Removal Policy (R)
+	SPDRP_REMOVAL_POLICY_OVERRIDE     = 0x00000021 // Removal Policy Override (RW)
+	SPDRP_INSTALL_STATE               = 0x00000022 // Device Install State (R)
+	SPDRP_LOCATION_PATHS              = 0x00000023 // Device Location Paths (R)
+	SPDRP_BASE_CONTAINERID            = 0x00000024 // Base ContainerID (R)
+	SPDRP_MAXIMUM_PROPERTY            = 0x00000025 // Upper bound on ordinals
+)
+
+// Device interface classes
+var (
+	GUID_DEVINTERFACE_USBPRINT   = *winapi.NewGUID(0x28d78fad, 0x5a12, 0x11d1, 0xae, 0x5b, 0x00, 0x00, 0xf8, 0x03, 0xa8, 0xc2)
+	GUID_DEVINTERFACE_COMPORT    = *winapi.NewGUID(0x86e0d1e0, 0x8089, 0x11d0, 0x9c, 0xe4, 0x08, 0x00, 0x3e, 0x30, 0x1f, 0x73)
+	GUID_DEVINTERFACE_HID        = *winapi.NewGUID(0x4d1e55b2, 0xf16f, 0x11cf, 0x88, 0xcb, 0x00, 0x11, 0x11, 0x00, 0x00, 0x30)
+	GUID_DEVINTERFACE_KEYBOARD   = *winapi.NewGUID(0x884b96c3, 0x56ef, 0x11d1, 0xbc, 0x8c, 0x00, 0xa0, 0xc9, 0x14, 0x05, 0xdd)
+	GUID_DEVINTERFACE_USB_DEVICE = *winapi.NewGUID(0xa5dcbf10, 0x6530, 0x11d2, 0x90, 0x1f, 0x00, 0xc0, 0x4f, 0xb9, 0x51, 0xed)
+)
+
+// Device setup classes
+var (
+	GUID_DEVCLASS_PORTS    = *winapi.NewGUID(0x4d36e978, 0xe325, 0x11ce, 0xbf, 0xc1, 0x08, 0x00, 0x2b, 0xe1, 0x03, 0x18)
+	GUID_DEVCLASS_PRINTER  = *winapi.NewGUID(0x4d36e979, 0xe325, 0x11ce, 0xbf, 0xc1, 0x08, 0x00, 0x2b, 0xe1, 0x03, 0x18)
+	GUID_DEVCLASS_KEYBOARD = *winapi.NewGUID(0x4d36e96b, 0xe325, 0x11ce, 0xbf, 0xc1, 0x08, 0x00, 0x2b, 0xe1, 0x03, 0x18)
+	GUID_DEVCLASS_HIDCLASS = *winapi.NewGUID(0x745a17a0, 0x74d3, 0x11d0, 0xb6, 0xfe, 0x00, 0xa0, 0xc9, 0x0f, 0x57, 0xda)
+	GUID_DEVCLASS_USB      = *winapi.NewGUID(0x36fc9e60, 0xc465, 0x11cf, 0x80, 0x56, 0x44, 0x45, 0x53, 0x54, 0x00, 0x00)
+)
+
+// CTL_CODE(FILE_DEVICE_UNKNOWN, 13, METHOD_BUFFERED, FILE_ANY_ACCESS), see usbprint.h
+const IOCTL_USBPRINT_GET_1284_ID = 0x220034
@@ -0,0 +1,146 @@
+package setupapi
+
+import (
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/regvalue"
+)
+
+// Device is a device record with its SPDRP_* properties decoded by regvalue.Decode,
+// except ClassGUID, BusTypeGUID and Base ContainerID which are winapi.GUID.
+type Device struct {
+	Path       string // device interface path, empty when enumerated by setup class
+	ClassGUID  winapi.GUID
+	DevInst    uint32
+	Properties map[uint32]interface{}
+}
+
+func (d *Device) String(property uint32) string {
+	switch v := d.Properties[property].(type) {
+	case string:
+		return v
+	case regvalue.ExpandString:
+		return string(v)
+	case []string:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case winapi.GUID:
+		return v.String()
+	}
+	return ""
+}
+
+func (d *Device) Strings(property uint32) []string {
+	switch v := d.Properties[property].(type) {
+	case []string:
+		return v
+	case string:
+		return []string{v}
+	case regvalue.ExpandString:
+		return []string{string(v)}
+	}
+	return nil
+}
+
+func (d *Device) Uint32(property uint32) (uint32, bool) {
+	v, ok := d.Properties[property].(uint32)
+	return v, ok
+}
+
+func (d *Device) GUID(property uint32) (winapi.GUID, bool) {
+	v, ok := d.Properties[property].(winapi.GUID)
+	return v, ok
+}
+
+func (d *Device) FriendlyName() string {
+	if name := d.String(SPDRP_FRIENDLYNAME); name != "" {
+		return name
+	}
+	return d.String(SPDRP_DEVICEDESC)
+}
+
+func (d *Device) HardwareIDs() []string {
+	return d.Strings(SPDRP_HARDWAREID)
+}
+
+// ParseDevicePath parses Path, or the first hardware id for records without interface path.
+func (d *Device) ParseDevicePath() (*DevicePath, error) {
+	if d.Path != "" {
+		return ParseDevicePath(d.Path)
+	}
+	return ParseDevicePath(d.String(SPDRP_HARDWAREID))
+}
+
+func decodeProperty(property, dataType uint32, data []byte) interface{} {
+	v, err := regvalue.Decode(dataType, data)
+	if err != nil {
+		return data
+	}
+	switch property {
+	case SPDRP_CLASSGUID, SPDRP_BASE_CONTAINERID:
+		if s, ok := v.(string); ok {
+			if g, err := winapi.ParseGUID(s); err == nil {
+				return g
+			}
+		}
+	case SPDRP_BUSTYPEGUID:
+		if len(data) == 16 && dataType == regvalue.REG_BINARY {
+			return guidFromBytes(data)
+		}
+	}
+	return v
+}
+
+// DeviceSource yields device records by index, it returns nil, nil past the last one.
+// *HDEVINFO is the system source, FakeSource serves canned records for tests.
+type DeviceSource interface {
+	Device(idx int) (*Device, error)
+}
+
+type FakeSource []*Device
+
+func (fs FakeSource) Device(idx int) (*Device, error) {
+	if idx >= len(fs) {
+		return nil, nil
+	}
+	return fs[idx], nil
+}
+
+// DeviceIterator walks a DeviceSource:
+//
+//	it := Devices(hDevs)
+//	for it.Next() {
+//		dev := it.Device()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type DeviceIterator struct {
+	src DeviceSource
+	idx int
+	dev *Device
+	err error
+}
+
+func Devices(src DeviceSource) *DeviceIterator {
+	return &DeviceIterator{src: src}
+}
+
+func (it *DeviceIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.dev, it.err = it.src.Device(it.idx)
+	if it.dev == nil {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *DeviceIterator) Device() *Device {
+	return it.dev
+}
+
+func (it *DeviceIterator) Err() error {
+	return it.err
+}
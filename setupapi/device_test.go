@@ -0,0 +1,107 @@
+package setupapi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/regvalue"
+)
+
+func TestDecodeProperty(t *testing.T) {
+	printerGUID, _ := winapi.ParseGUID("{4d36e979-e325-11ce-bfc1-08002be10318}")
+	multi, _ := regvalue.EncodeStrings([]string{`USB\VID_04F9&PID_2058&REV_0100`, `USB\VID_04F9&PID_2058`})
+	tests := []struct {
+		property, dataType uint32
+		data               []byte
+		want               interface{}
+	}{
+		{SPDRP_FRIENDLYNAME, regvalue.REG_SZ, regvalue.EncodeString("Brother HL-2130"), "Brother HL-2130"},
+		{SPDRP_HARDWAREID, regvalue.REG_MULTI_SZ, multi, []string{`USB\VID_04F9&PID_2058&REV_0100`, `USB\VID_04F9&PID_2058`}},
+		{SPDRP_ADDRESS, regvalue.REG_DWORD, []byte{3, 0, 0, 0}, uint32(3)},
+		{SPDRP_CLASSGUID, regvalue.REG_SZ, regvalue.EncodeString("{4d36e979-e325-11ce-bfc1-08002be10318}"), printerGUID},
+		{SPDRP_BUSTYPEGUID, regvalue.REG_BINARY, []byte{0x79, 0xe9, 0x36, 0x4d, 0x25, 0xe3, 0xce, 0x11, 0xbf, 0xc1, 0x08, 0x00, 0x2b, 0xe1, 0x03, 0x18}, printerGUID},
+		{SPDRP_CLASSGUID, regvalue.REG_SZ, regvalue.EncodeString("not a guid"), "not a guid"},
+		{SPDRP_ADDRESS, regvalue.REG_DWORD, []byte{1, 2}, []byte{1, 2}},
+	}
+	for _, tt := range tests {
+		if got := decodeProperty(tt.property, tt.dataType, tt.data); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decodeProperty(%d, %d, % x) = %#v, want %#v", tt.property, tt.dataType, tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDevices(t *testing.T) {
+	src := FakeSource{
+		{Properties: map[uint32]interface{}{
+			SPDRP_DEVICEDESC: "USB Printing Support",
+			SPDRP_HARDWAREID: []string{`USB\VID_04F9&PID_2058&REV_0100`, `USB\VID_04F9&PID_2058`},
+		}},
+		{Path: `\\?\usbprint#epsontm-t20#7&2a7c3f6&0&usb001#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}`, Properties: map[uint32]interface{}{
+			SPDRP_FRIENDLYNAME: "EPSON TM-T20",
+			SPDRP_ADDRESS:      uint32(1),
+		}},
+	}
+	var names []string
+	it := Devices(src)
+	for it.Next() {
+		names = append(names, it.Device().FriendlyName())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"USB Printing Support", "EPSON TM-T20"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+
+	p, err := src[0].ParseDevicePath()
+	if err != nil || p.VID != 0x04f9 || p.PID != 0x2058 || p.Rev != 0x0100 {
+		t.Errorf("ParseDevicePath() = %+v, %v", p, err)
+	}
+	p, err = src[1].ParseDevicePath()
+	if err != nil || p.Enumerator != "usbprint" || !p.HasGUID {
+		t.Errorf("ParseDevicePath() = %+v, %v", p, err)
+	}
+	if v, ok := src[1].Uint32(SPDRP_ADDRESS); !ok || v != 1 {
+		t.Errorf("Uint32(SPDRP_ADDRESS) = %d, %v", v, ok)
+	}
+	if s := src[1].Strings(SPDRP_FRIENDLYNAME); !reflect.DeepEqual(s, []string{"EPSON TM-T20"}) {
+		t.Errorf("Strings(SPDRP_FRIENDLYNAME) = %q", s)
+	}
+
+	// REG_EXPAND_SZ is a string, left unexpanded
+	dev := &Device{Properties: map[uint32]interface{}{
+		SPDRP_LOCATION_INFORMATION: decodeProperty(SPDRP_LOCATION_INFORMATION, regvalue.REG_EXPAND_SZ, regvalue.EncodeString("%SystemRoot%\\usb")),
+	}}
+	if s := dev.Strings(SPDRP_LOCATION_INFORMATION); !reflect.DeepEqual(s, []string{`%SystemRoot%\usb`}) {
+		t.Errorf("Strings() of a REG_EXPAND_SZ = %q", s)
+	}
+	if s := dev.String(SPDRP_LOCATION_INFORMATION); s != `%SystemRoot%\usb` {
+		t.Errorf("String() of a REG_EXPAND_SZ = %q", s)
+	}
+}
+
+type errSource struct{ err error }
+
+func (s errSource) Device(idx int) (*Device, error) {
+	if idx > 0 {
+		return nil, s.err
+	}
+	return &Device{}, nil
+}
+
+func TestDevicesError(t *testing.T) {
+	errEnum := errors.New("enum failed")
+	it := Devices(errSource{errEnum})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 1 || it.Err() != errEnum {
+		t.Errorf("got %d devices, error %v", n, it.Err())
+	}
+	if it.Next() {
+		t.Error("Next() after an error")
+	}
+}
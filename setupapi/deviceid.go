@@ -0,0 +1,182 @@
+package setupapi
+
+import (
+	"errors"
+	"strings"
+)
+
+// IEEE 1284 Device ID, as reported by USB printers, e.g.:
+//   MFG:EPSON;CMD:ESC/POS;MDL:TM-T20;CLS:PRINTER;DES:EPSON TM-T20 Receipt;
+
+// CommandLanguage is the printer language deduced from the COMMAND SET field.
+type CommandLanguage int
+
+const (
+	LanguageUnknown CommandLanguage = iota
+	LanguageESCPOS
+	LanguageZPL
+	LanguageTSPL
+)
+
+func (l CommandLanguage) String() string {
+	switch l {
+	case LanguageESCPOS:
+		return "ESC/POS"
+	case LanguageZPL:
+		return "ZPL"
+	case LanguageTSPL:
+		return "TSPL"
+	}
+	return "unknown"
+}
+
+type DeviceID struct {
+	Manufacturer string
+	Model        string
+	CommandSet   []string
+	Class        string
+	Serial       string
+	Description  string
+	Fields       map[string]string // every field keyed by its upper-cased name, as reported
+}
+
+var ErrEmptyDeviceID = errors.New("empty device id")
+
+// long and vendor specific key names mapped to the short form
+var deviceIDKeyAliases = map[string]string{
+	"MANUFACTURER": "MFG",
+	"MODEL":        "MDL",
+	"COMMAND SET":  "CMD",
+	"COMMANDSET":   "CMD",
+	"CLASS":        "CLS",
+	"DESCRIPTION":  "DES",
+	"SERIALNUMBER": "SN",
+	"SERIAL":       "SN",
+	"SERN":         "SN",
+}
+
+// ParseDeviceID parses a 1284 Device ID string. Key names are matched case
+// insensitively in both long and short form, surrounding blanks and NUL padding
+// are dropped, and a missing trailing ';' is accepted.
+func ParseDeviceID(s string) (*DeviceID, error) {
+	s = strings.Trim(s, "\x00 \t\r\n")
+	id := &DeviceID{Fields: make(map[string]string)}
+	for _, field := range strings.Split(s, ";") {
+		idx := strings.IndexByte(field, ':')
+		if idx < 0 {
+			continue
+		}
+		key := strings.ToUpper(strings.TrimSpace(field[:idx]))
+		value := strings.Trim(field[idx+1:], "\x00 \t\r\n")
+		if key == "" {
+			continue
+		}
+		if _, ok := id.Fields[key]; ok { // keep the first one on duplicates
+			continue
+		}
+		id.Fields[key] = value
+
+		if alias, ok := deviceIDKeyAliases[key]; ok {
+			key = alias
+		}
+		switch key {
+		case "MFG":
+			setOnce(&id.Manufacturer, value)
+		case "MDL":
+			setOnce(&id.Model, value)
+		case "CLS":
+			setOnce(&id.Class, value)
+		case "DES":
+			setOnce(&id.Description, value)
+		case "SN":
+			setOnce(&id.Serial, value)
+		case "CMD":
+			if id.CommandSet == nil {
+				id.CommandSet = splitCommandSet(value)
+			}
+		}
+	}
+	if len(id.Fields) == 0 {
+		return nil, ErrEmptyDeviceID
+	}
+	return id, nil
+}
+
+// ParseDeviceIDBytes parses the raw buffer returned by IOCTL_USBPRINT_GET_1284_ID,
+// which is prefixed with a 2-byte big-endian length that includes itself.
+func ParseDeviceIDBytes(b []byte) (*DeviceID, error) {
+	if len(b) >= 2 && b[0] < 0x20 { // a bare id always starts with a printable key
+		n := int(b[0])<<8 | int(b[1])
+		b = b[2:]
+		if n >= 2 && n-2 <= len(b) { // otherwise the reply was truncated, keep what we got
+			b = b[:n-2]
+		}
+	}
+	return ParseDeviceID(string(b))
+}
+
+func setOnce(dst *string, value string) {
+	if *dst == "" {
+		*dst = value
+	}
+}
+
+func splitCommandSet(value string) []string {
+	var cmds []string
+	for _, cmd := range strings.Split(value, ",") {
+		cmd = strings.TrimSpace(cmd)
+		if cmd != "" {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// Language returns the first printer language of the command set known to us.
+func (id *DeviceID) Language() CommandLanguage {
+	for _, cmd := range id.CommandSet {
+		if l := commandLanguage(cmd); l != LanguageUnknown {
+			return l
+		}
+	}
+	return LanguageUnknown
+}
+
+func commandLanguage(cmd string) CommandLanguage {
+	cmd = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '-', '_', ' ', '.':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(cmd))
+	switch {
+	case strings.HasPrefix(cmd, "ESCPOS"), cmd == "EPSON", cmd == "ESC":
+		return LanguageESCPOS
+	case strings.HasPrefix(cmd, "ZPL"):
+		return LanguageZPL
+	case strings.HasPrefix(cmd, "TSPL"):
+		return LanguageTSPL
+	}
+	return LanguageUnknown
+}
+
+// String formats the id back in the 1284 short-key form.
+func (id *DeviceID) String() string {
+	var sb strings.Builder
+	write := func(key, value string) {
+		if value != "" {
+			sb.WriteString(key)
+			sb.WriteByte(':')
+			sb.WriteString(value)
+			sb.WriteByte(';')
+		}
+	}
+	write("MFG", id.Manufacturer)
+	write("CMD", strings.Join(id.CommandSet, ","))
+	write("MDL", id.Model)
+	write("CLS", id.Class)
+	write("DES", id.Description)
+	write("SN", id.Serial)
+	return sb.String()
+}
@@ -0,0 +1,136 @@
+package setupapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDeviceID(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want DeviceID
+	}{
+		{
+			"short keys",
+			"MFG:EPSON;CMD:ESC/POS;MDL:TM-T20;CLS:PRINTER;DES:EPSON TM-T20 Receipt;",
+			DeviceID{Manufacturer: "EPSON", Model: "TM-T20", CommandSet: []string{"ESC/POS"}, Class: "PRINTER", Description: "EPSON TM-T20 Receipt"},
+		},
+		{
+			"long keys",
+			"MANUFACTURER:Zebra;COMMAND SET:ZPL;MODEL:ZD420;CLASS:PRINTER;SERIALNUMBER:D4J1;DESCRIPTION:Zebra ZD420;",
+			DeviceID{Manufacturer: "Zebra", Model: "ZD420", CommandSet: []string{"ZPL"}, Class: "PRINTER", Serial: "D4J1", Description: "Zebra ZD420"},
+		},
+		{
+			"repeated keys keep the first",
+			"MFG:Zebra;MANUFACTURER:Other;MDL:ZD420;MDL:ZD421;CMD:ZPL;COMMAND SET:TSPL",
+			DeviceID{Manufacturer: "Zebra", Model: "ZD420", CommandSet: []string{"ZPL"}},
+		},
+		{
+			"NUL padding, no final ;",
+			"MFG:TSC;MDL:TTP-244\x00\x00\x00",
+			DeviceID{Manufacturer: "TSC", Model: "TTP-244"},
+		},
+		{
+			"spaces and case",
+			" mfg : Brother ;Cmd: PJL, PCL ,, ESC/POS ;mdl:HL-2130 ;",
+			DeviceID{Manufacturer: "Brother", Model: "HL-2130", CommandSet: []string{"PJL", "PCL", "ESC/POS"}},
+		},
+	}
+	for _, tt := range tests {
+		id, err := ParseDeviceID(tt.s)
+		if err != nil {
+			t.Errorf("%s: ParseDeviceID(%q): %v", tt.name, tt.s, err)
+			continue
+		}
+		got := *id
+		got.Fields = nil
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseDeviceID(%q) = %+v, want %+v", tt.name, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseDeviceIDFields(t *testing.T) {
+	id, err := ParseDeviceID("MANUFACTURER:Zebra;cmd:ZPL;MANUFACTURER:Other;X-VENDOR:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"MANUFACTURER": "Zebra", "CMD": "ZPL", "X-VENDOR": "1"}
+	if !reflect.DeepEqual(id.Fields, want) {
+		t.Errorf("Fields = %v, want %v", id.Fields, want)
+	}
+	if s := id.String(); s != "MFG:Zebra;CMD:ZPL;" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestParseDeviceIDEmpty(t *testing.T) {
+	for _, s := range []string{"", "\x00\x00", "no fields", ";;:x;"} {
+		if _, err := ParseDeviceID(s); err != ErrEmptyDeviceID {
+			t.Errorf("ParseDeviceID(%q) error = %v", s, err)
+		}
+	}
+}
+
+func TestParseDeviceIDBytes(t *testing.T) {
+	id := "MFG:EPSON;MDL:TM-T20;"
+	prefixed := func(n int, s string) []byte {
+		return append([]byte{byte(n >> 8), byte(n)}, s...)
+	}
+	tests := []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{"length prefix", prefixed(len(id)+2, id), "TM-T20"},
+		{"garbage after the length", prefixed(len(id)+2, id+"MDL:junk;"), "TM-T20"},
+		{"truncated reply", prefixed(len(id)+100, id), "TM-T20"},
+		{"no prefix", []byte(id), "TM-T20"},
+	}
+	for _, tt := range tests {
+		got, err := ParseDeviceIDBytes(tt.b)
+		if err != nil {
+			t.Errorf("%s: ParseDeviceIDBytes(% x): %v", tt.name, tt.b, err)
+			continue
+		}
+		if got.Manufacturer != "EPSON" || got.Model != tt.want {
+			t.Errorf("%s: ParseDeviceIDBytes(% x) = %+v", tt.name, tt.b, got)
+		}
+	}
+
+	// a length above 255, b[0] isn't 0
+	long := "MFG:Zebra;DES:" + strings.Repeat("x", 300) + ";"
+	got, err := ParseDeviceIDBytes(prefixed(len(long)+2, long))
+	if err != nil || len(got.Description) != 300 {
+		t.Errorf("ParseDeviceIDBytes of %d bytes = %+v, %v", len(long)+2, got, err)
+	}
+}
+
+func TestDeviceIDLanguage(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want CommandLanguage
+	}{
+		{"ESC/POS", LanguageESCPOS},
+		{"PJL,ESCPOS", LanguageESCPOS},
+		{"esc/pos", LanguageESCPOS},
+		{"EPSON", LanguageESCPOS},
+		{"ZPL", LanguageZPL},
+		{"ZPL II,EPL", LanguageZPL},
+		{"TSPL2", LanguageTSPL},
+		{"PCL, TSPL", LanguageTSPL},
+		{"PJL,PCL", LanguageUnknown},
+		{"", LanguageUnknown},
+	}
+	for _, tt := range tests {
+		id, err := ParseDeviceID("MFG:x;CMD:" + tt.cmd + ";")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := id.Language(); got != tt.want {
+			t.Errorf("CMD:%s: Language() = %v, want %v", tt.cmd, got, tt.want)
+		}
+	}
+}
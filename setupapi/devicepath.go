@@ -0,0 +1,164 @@
+package setupapi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FxStar/winapi"
+)
+
+// DevicePath is a parsed device interface path, e.g.:
+//
+//	\\?\usb#vid_6868&pid_0500&mi_00#6&29a28943&0&0000#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}
+//	\\?\hid#vid_046d&pid_c52b&mi_01&col02#8&1f3c2a4e&0&0001#{4d1e55b2-f16f-11cf-88cb-001111000030}
+//	\\?\usbprint#epsontm-t20#7&2a7c3f6&0&usb001#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}
+//	\\?\swd#printenum#{7c2f4d94-5e9e-4c4c-9a4b-7a4b1f7a1c19}#{0ecef634-6ef0-472a-8085-5ad023ecbccd}
+//
+// A device instance id such as USB\VID_04F9&PID_2058\000F9Z132168 is accepted
+// too, it simply has no class GUID.
+//
+// Numeric fields are -1 when the segment is absent.
+type DevicePath struct {
+	Enumerator string   // bus enumerator: USB, HID, USBPRINT, SWD, ROOT, ...
+	VID, PID   int      // VID_xxxx, PID_xxxx
+	Rev        int      // REV_xxxx
+	MI         int      // MI_xx, interface number of a composite device
+	Col        int      // COLxx, HID top level collection
+	Extra      []string // other '&' separated parts of the device segment, e.g. "epsontm-t20"
+	Instance   string   // serial number or instance id
+	ClassGUID  winapi.GUID
+	HasGUID    bool
+	Reference  string // reference string following the class GUID, if any
+
+	segment string // device segment as parsed, formatted as is while the fields match it
+	guid    string // class GUID as parsed, SetupDi reports it in upper case
+}
+
+var ErrInvalidDevicePath = errors.New("invalid device path")
+
+// ParseDevicePath parses a device interface path or a device instance id.
+func ParseDevicePath(s string) (*DevicePath, error) {
+	p := &DevicePath{VID: -1, PID: -1, Rev: -1, MI: -1, Col: -1}
+	var parts []string
+	sep := "#"
+	switch {
+	case strings.HasPrefix(s, `\\?\`), strings.HasPrefix(s, `\\.\`):
+		parts = strings.Split(s[4:], "#")
+		last := parts[len(parts)-1]
+		if strings.HasPrefix(last, "{") {
+			if idx := strings.IndexByte(last, '\\'); idx >= 0 {
+				p.Reference = last[idx+1:]
+				last = last[:idx]
+			}
+			g, err := winapi.ParseGUID(last)
+			if err != nil {
+				return nil, ErrInvalidDevicePath
+			}
+			p.ClassGUID, p.HasGUID = g, true
+			p.guid = last
+			parts = parts[:len(parts)-1]
+		}
+	default:
+		parts = strings.Split(s, `\`)
+		sep = `\`
+	}
+	if len(parts) < 2 || parts[0] == "" {
+		return nil, ErrInvalidDevicePath
+	}
+	p.Enumerator = parts[0]
+	if len(parts) > 2 {
+		p.Instance = strings.Join(parts[2:], sep)
+	}
+	p.segment = parts[1]
+	p.parseSegment(parts[1])
+	return p, nil
+}
+
+func (p *DevicePath) parseSegment(segment string) {
+	for _, part := range strings.Split(segment, "&") {
+		upper := strings.ToUpper(part)
+		var dst *int
+		var digits string
+		switch {
+		case strings.HasPrefix(upper, "VID_"):
+			dst, digits = &p.VID, part[4:]
+		case strings.HasPrefix(upper, "PID_"):
+			dst, digits = &p.PID, part[4:]
+		case strings.HasPrefix(upper, "REV_"):
+			dst, digits = &p.Rev, part[4:]
+		case strings.HasPrefix(upper, "MI_"):
+			dst, digits = &p.MI, part[3:]
+		case strings.HasPrefix(upper, "COL") && len(part) > 3:
+			dst, digits = &p.Col, part[3:]
+		}
+		if dst != nil && *dst == -1 {
+			if v, err := strconv.ParseUint(digits, 16, 16); err == nil {
+				*dst = int(v)
+				continue
+			}
+		}
+		p.Extra = append(p.Extra, part)
+	}
+}
+
+// HasVidPid reports whether the path carries an USB vendor and product id.
+func (p *DevicePath) HasVidPid() bool {
+	return p.VID >= 0 && p.PID >= 0
+}
+
+// DeviceSegment formats the second segment, e.g. vid_6868&pid_0500&mi_00. The
+// segment of a parsed path keeps its spelling unless the fields were changed.
+func (p *DevicePath) DeviceSegment() string {
+	if p.segment != "" {
+		q := DevicePath{VID: -1, PID: -1, Rev: -1, MI: -1, Col: -1}
+		q.parseSegment(p.segment)
+		if q.VID == p.VID && q.PID == p.PID && q.Rev == p.Rev && q.MI == p.MI && q.Col == p.Col &&
+			strings.Join(q.Extra, "&") == strings.Join(p.Extra, "&") {
+			return p.segment
+		}
+	}
+	var parts []string
+	if p.VID >= 0 {
+		parts = append(parts, fmt.Sprintf("vid_%04x", p.VID))
+	}
+	if p.PID >= 0 {
+		parts = append(parts, fmt.Sprintf("pid_%04x", p.PID))
+	}
+	if p.Rev >= 0 {
+		parts = append(parts, fmt.Sprintf("rev_%04x", p.Rev))
+	}
+	if p.MI >= 0 {
+		parts = append(parts, fmt.Sprintf("mi_%02x", p.MI))
+	}
+	if p.Col >= 0 {
+		parts = append(parts, fmt.Sprintf("col%02x", p.Col))
+	}
+	parts = append(parts, p.Extra...)
+	return strings.Join(parts, "&")
+}
+
+// String formats the interface path, or the instance id if there is no class GUID.
+func (p *DevicePath) String() string {
+	if !p.HasGUID {
+		s := p.Enumerator + `\` + p.DeviceSegment()
+		if p.Instance != "" {
+			s += `\` + p.Instance
+		}
+		return s
+	}
+	s := `\\?\` + p.Enumerator + "#" + p.DeviceSegment()
+	if p.Instance != "" {
+		s += "#" + p.Instance
+	}
+	if g, err := winapi.ParseGUID(p.guid); err == nil && g == p.ClassGUID {
+		s += "#" + p.guid
+	} else {
+		s += "#" + p.ClassGUID.String()
+	}
+	if p.Reference != "" {
+		s += `\` + p.Reference
+	}
+	return s
+}
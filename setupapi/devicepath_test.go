@@ -0,0 +1,106 @@
+package setupapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+func TestParseDevicePath(t *testing.T) {
+	usbGUID, _ := winapi.ParseGUID("{28d78fad-5a12-11d1-ae5b-0000f803a8c2}")
+	hidGUID, _ := winapi.ParseGUID("{4d1e55b2-f16f-11cf-88cb-001111000030}")
+	tests := []struct {
+		path string
+		want DevicePath
+	}{
+		{
+			`\\?\usb#vid_6868&pid_0500&mi_00#6&29a28943&0&0000#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}`,
+			DevicePath{Enumerator: "usb", VID: 0x6868, PID: 0x0500, Rev: -1, MI: 0, Col: -1,
+				Instance: "6&29a28943&0&0000", ClassGUID: usbGUID, HasGUID: true},
+		},
+		{
+			`\\?\HID#VID_046D&PID_C52B&MI_01&Col02#8&1f3c2a4e&0&0001#{4d1e55b2-f16f-11cf-88cb-001111000030}`,
+			DevicePath{Enumerator: "HID", VID: 0x046d, PID: 0xc52b, Rev: -1, MI: 1, Col: 2,
+				Instance: "8&1f3c2a4e&0&0001", ClassGUID: hidGUID, HasGUID: true},
+		},
+		{
+			`\\?\usbprint#epsontm-t20#7&2a7c3f6&0&usb001#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}`,
+			DevicePath{Enumerator: "usbprint", VID: -1, PID: -1, Rev: -1, MI: -1, Col: -1, Extra: []string{"epsontm-t20"},
+				Instance: "7&2a7c3f6&0&usb001", ClassGUID: usbGUID, HasGUID: true},
+		},
+		{
+			`\\?\ROOT#SYSTEM#0000#{28d78fad-5a12-11d1-ae5b-0000f803a8c2}\{mme}`,
+			DevicePath{Enumerator: "ROOT", VID: -1, PID: -1, Rev: -1, MI: -1, Col: -1, Extra: []string{"SYSTEM"},
+				Instance: "0000", ClassGUID: usbGUID, HasGUID: true, Reference: "{mme}"},
+		},
+		{
+			`USB\VID_04F9&PID_2058&REV_0100`,
+			DevicePath{Enumerator: "USB", VID: 0x04f9, PID: 0x2058, Rev: 0x0100, MI: -1, Col: -1},
+		},
+		{
+			`USB\VID_04F9&PID_2058\000F9Z132168`,
+			DevicePath{Enumerator: "USB", VID: 0x04f9, PID: 0x2058, Rev: -1, MI: -1, Col: -1, Instance: "000F9Z132168"},
+		},
+		{
+			`HID\VID_1&PID_2\a\b`,
+			DevicePath{Enumerator: "HID", VID: 1, PID: 2, Rev: -1, MI: -1, Col: -1, Instance: `a\b`},
+		},
+	}
+	for _, tt := range tests {
+		p, err := ParseDevicePath(tt.path)
+		if err != nil {
+			t.Errorf("ParseDevicePath(%q): %v", tt.path, err)
+			continue
+		}
+		got := *p
+		got.segment, got.guid = "", ""
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseDevicePath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+		if s := p.String(); s != tt.path {
+			t.Errorf("ParseDevicePath(%q).String() = %q", tt.path, s)
+		}
+	}
+}
+
+func TestParseDevicePathInvalid(t *testing.T) {
+	for _, s := range []string{"", "bad", `\\?\`, `\\?\usb#{not-a-guid}`, `\usb`} {
+		if _, err := ParseDevicePath(s); err != ErrInvalidDevicePath {
+			t.Errorf("ParseDevicePath(%q) error = %v", s, err)
+		}
+	}
+}
+
+func TestDevicePathFormat(t *testing.T) {
+	p, err := ParseDevicePath(`\\?\HID#VID_046D&PID_C52B&MI_01&Col02#8&1f3c2a4e&0&0001#{4d1e55b2-f16f-11cf-88cb-001111000030}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.MI = 2
+	want := `\\?\HID#vid_046d&pid_c52b&mi_02&col02#8&1f3c2a4e&0&0001#{4d1e55b2-f16f-11cf-88cb-001111000030}`
+	if s := p.String(); s != want {
+		t.Errorf("String() = %q, want %q", s, want)
+	}
+
+	// the GUID keeps its case until it changes
+	upper := `\\?\USB#VID_04F9&PID_2058#000F9Z132168#{28D78FAD-5A12-11D1-AE5B-0000F803A8C2}`
+	if p, err = ParseDevicePath(upper); err != nil {
+		t.Fatal(err)
+	}
+	if s := p.String(); s != upper {
+		t.Errorf("String() = %q, want %q", s, upper)
+	}
+	p.ClassGUID, _ = winapi.ParseGUID("{4d1e55b2-f16f-11cf-88cb-001111000030}")
+	if s := p.String(); s != `\\?\USB#VID_04F9&PID_2058#000F9Z132168#{4d1e55b2-f16f-11cf-88cb-001111000030}` {
+		t.Errorf("String() after a change = %q", s)
+	}
+
+	p = &DevicePath{Enumerator: "USB", VID: 0x04f9, PID: 0x2058, Rev: -1, MI: -1, Col: -1, Instance: "000F9Z132168"}
+	if s := p.String(); s != `USB\vid_04f9&pid_2058\000F9Z132168` {
+		t.Errorf("String() = %q", s)
+	}
+	if !p.HasVidPid() {
+		t.Error("HasVidPid() = false")
+	}
+}
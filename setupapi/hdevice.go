@@ -0,0 +1,33 @@
+package setupapi
+
+import "errors"
+
+func (hd HDevice) WriteAll(data []byte) (int, error) {
+	total := 0
+	for total < len(data) {
+		n, err := hd.Write(data[total:])
+		if err != nil {
+			return total, err
+		}
+		if n <= 0 {
+			return total, errors.New("can't write any more")
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (hd HDevice) ReadAll(p []byte) (int, error) {
+	total := 0
+	for total < len(p) {
+		n, err := hd.Read(p[total:])
+		if err != nil {
+			return total, err
+		}
+		if n <= 0 {
+			return total, errors.New("can't read any more")
+		}
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+// +build !windows
+
+package setupapi
+
+import "github.com/FxStar/winapi"
+
+// Stubs for other platforms, so code that opens or watches devices still
+// builds. They fail with winapi.ErrNotSupported, as the setupapi procedures.
+
+type HDevice uintptr
+
+func Open(devicePath string) (HDevice, error) {
+	return 0, winapi.ErrNotSupported
+}
+
+func (hd HDevice) GetDeviceID() (*DeviceID, error) {
+	return nil, winapi.ErrNotSupported
+}
+
+func (hd HDevice) Close() error {
+	return winapi.ErrNotSupported
+}
+
+func (hd HDevice) Write(data []byte) (int, error) {
+	return 0, winapi.ErrNotSupported
+}
+
+func (hd HDevice) Read(p []byte) (int, error) {
+	return 0, winapi.ErrNotSupported
+}
+
+type DeviceWatcher struct {
+	events chan DeviceEvent
+}
+
+func NewDeviceWatcher(filter WatchFilter) *DeviceWatcher {
+	events := make(chan DeviceEvent)
+	close(events)
+	return &DeviceWatcher{events: events}
+}
+
+func (w *DeviceWatcher) Start() error {
+	return winapi.ErrNotSupported
+}
+
+// Events is closed from the start.
+func (w *DeviceWatcher) Events() <-chan DeviceEvent {
+	return w.events
+}
+
+func (w *DeviceWatcher) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package setupapi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/FxStar/winapi/internal/winerr"
+	"github.com/FxStar/winapi/regvalue"
+)
+
+func swapRegistryProperty(t *testing.T, steps ...proc.Step) *proc.Fake {
+	fake := &proc.Fake{Steps: steps}
+	prev := setupDiGetDeviceRegistryPropertyW.Swap(fake)
+	t.Cleanup(func() { setupDiGetDeviceRegistryPropertyW.Swap(prev) })
+	return fake
+}
+
+func TestGetDeviceRegistryProperty(t *testing.T) {
+	ids := []string{`USB\VID_04F9&PID_2058&REV_0100`, `USB\VID_04F9&PID_2058`}
+	data, _ := regvalue.EncodeStrings(ids)
+	fake := swapRegistryProperty(t,
+		proc.Step{Errno: winerr.ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) {
+			if a[4] != 0 || a[5] != 0 {
+				t.Errorf("first call with buffer %#x, size %d", a[4], a[5])
+			}
+			proc.PutUint32(a[3], regvalue.REG_MULTI_SZ)
+			proc.PutUint32(a[6], uint32(len(data)))
+		}},
+		proc.Step{R1: 1, Do: func(a []uintptr) {
+			if int(a[5]) != len(data) {
+				t.Errorf("second call with size %d, want %d", a[5], len(data))
+			}
+			proc.PutUint32(a[3], regvalue.REG_MULTI_SZ)
+			proc.PutBytes(a[4], data)
+		}},
+	)
+	hDevs := &HDEVINFO{h: 0x30, devInfo: spDevInfoData{DevInst: 5}}
+	got, err := hDevs.GetHardwareIds()
+	if err != nil || !reflect.DeepEqual(got, ids) {
+		t.Errorf("GetHardwareIds() = %q, %v, want %q", got, err, ids)
+	}
+	for i, a := range fake.Calls() {
+		if a[0] != 0x30 || a[2] != SPDRP_HARDWAREID {
+			t.Errorf("call %d: set %#x, property %d", i+1, a[0], a[2])
+		}
+	}
+}
+
+func TestGetDeviceRegistryPropertyError(t *testing.T) {
+	if _, err := new(HDEVINFO).GetHardwareId(); err != ErrNoDevice {
+		t.Errorf("GetHardwareId() before Enum = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		steps []proc.Step
+		err   error
+	}{
+		{"missing property", []proc.Step{{Errno: winerr.ERROR_INVALID_DATA}}, winerr.ERROR_INVALID_DATA},
+		{"second call", []proc.Step{
+			{Errno: winerr.ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) { proc.PutUint32(a[6], 8) }},
+			{Errno: winerr.ERROR_INSUFFICIENT_BUFFER},
+		}, winerr.ERROR_INSUFFICIENT_BUFFER},
+		{"no size", []proc.Step{{Errno: winerr.ERROR_INSUFFICIENT_BUFFER}}, winerr.ERROR_INVALID_DATA},
+	}
+	for _, tt := range tests {
+		swapRegistryProperty(t, tt.steps...)
+		hDevs := &HDEVINFO{devInfo: spDevInfoData{DevInst: 5}}
+		_, err := hDevs.GetLocation()
+		var e *winerr.Error
+		if !errors.As(err, &e) || e.API != "SetupDiGetDeviceRegistryPropertyW" || !errors.Is(err, tt.err) {
+			t.Errorf("%s: GetLocation() error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
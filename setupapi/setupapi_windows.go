@@ -0,0 +1,49 @@
+package setupapi
+
+import (
+	"syscall"
+
+	"github.com/FxStar/winapi"
+)
+
+type HDevice syscall.Handle
+
+func Open(devicePath string) (HDevice, error) {
+	name, err := syscall.UTF16PtrFromString(devicePath)
+	if err != nil {
+		return 0, err
+	}
+	// https://docs.microsoft.com/en-us/windows/win32/api/fileapi/nf-fileapi-flushfilebuffers
+	// https://docs.microsoft.com/en-us/windows/win32/fileio/file-buffering
+	var dwFlagsAndAttributes uint32 = syscall.FILE_ATTRIBUTE_NORMAL | winapi.FILE_FLAG_NO_BUFFERING | winapi.FILE_FLAG_WRITE_THROUGH
+	h, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, syscall.FILE_SHARE_READ, nil, syscall.OPEN_ALWAYS, dwFlagsAndAttributes, 0)
+	return HDevice(h), err
+}
+
+// GetDeviceID reads the IEEE 1284 Device ID from an opened usbprint device.
+func (hd HDevice) GetDeviceID() (*DeviceID, error) {
+	buf := make([]byte, 1024)
+	var done uint32
+	err := syscall.DeviceIoControl(syscall.Handle(hd), IOCTL_USBPRINT_GET_1284_ID, nil, 0, &buf[0], uint32(len(buf)), &done, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeviceIDBytes(buf[:done])
+}
+
+func (hd HDevice) Close() error {
+	// err := syscall.FlushFileBuffers(syscall.Handle(hd)) // get "Incorrect function." error
+	return syscall.CloseHandle(syscall.Handle(hd))
+}
+
+func (hd HDevice) Write(data []byte) (int, error) {
+	var written uint32
+	err := syscall.WriteFile(syscall.Handle(hd), data, &written, nil)
+	return int(written), err
+}
+
+func (hd HDevice) Read(p []byte) (int, error) {
+	var done uint32
+	err := syscall.ReadFile(syscall.Handle(hd), p, &done, nil)
+	return int(done), err
+}
@@ -0,0 +1,208 @@
+package setupapi
+
+import (
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/FxStar/winapi/winspool"
+	"github.com/FxStar/winapi/wstr"
+)
+
+const watcherClassName = "winapiDeviceWatcher"
+
+var (
+	watcherClassOnce sync.Once
+	watcherClassErr  error
+	watchers         sync.Map // winapi.HWND -> *DeviceWatcher
+)
+
+type devBroadcastDeviceInterface struct {
+	dbccSize       uint32
+	dbccDeviceType uint32
+	dbccReserved   uint32
+	dbccClassGuid  guid
+	dbccName       uint16
+}
+
+// DeviceWatcher delivers device arrival and removal events. It owns a hidden
+// message-only window whose message loop runs on a locked OS thread.
+//
+//	w := NewDeviceWatcher(WatchFilter{ClassGUIDs: []winapi.GUID{GUID_DEVINTERFACE_USBPRINT}})
+//	if err := w.Start(); err != nil {
+//	}
+//	defer w.Close()
+//	for ev := range w.Events() {
+//	}
+type DeviceWatcher struct {
+	filter        WatchFilter
+	events        chan DeviceEvent
+	hwnd          winapi.HWND
+	notifications []uintptr
+	closing       chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewDeviceWatcher(filter WatchFilter) *DeviceWatcher {
+	return &DeviceWatcher{
+		filter:  filter,
+		events:  make(chan DeviceEvent, 16),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start returns once the window exists and the notifications are registered.
+func (w *DeviceWatcher) Start() error {
+	ready := make(chan error, 1)
+	go w.run(ready)
+	return <-ready
+}
+
+// Events is closed when the watcher stops.
+func (w *DeviceWatcher) Events() <-chan DeviceEvent {
+	return w.events
+}
+
+func (w *DeviceWatcher) Close() (err error) {
+	w.closeOnce.Do(func() {
+		close(w.closing)
+		if w.hwnd == 0 {
+			return
+		}
+		if err = winapi.PostMessage(w.hwnd, winapi.WM_CLOSE, 0, 0); err == nil {
+			<-w.done
+		}
+	})
+	return
+}
+
+func (w *DeviceWatcher) run(ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(w.events)
+
+	hwnd, err := createWatcherWindow(w.filter.Ports)
+	if err != nil {
+		ready <- err
+		return
+	}
+	w.hwnd = hwnd
+	watchers.Store(hwnd, w)
+	defer watchers.Delete(hwnd)
+	defer close(w.done)
+
+	if err := w.register(); err != nil {
+		w.unregister()
+		winapi.DestroyWindow(hwnd)
+		ready <- err
+		return
+	}
+	ready <- nil
+
+	var msg winapi.Msg
+	for {
+		ret, err := winapi.GetMessage(&msg, 0, 0, 0)
+		if ret == 0 || err != nil { // WM_QUIT
+			return
+		}
+		winapi.TranslateMessage(&msg)
+		winapi.DispatchMessageW(&msg)
+	}
+}
+
+func (w *DeviceWatcher) register() error {
+	guids := w.filter.ClassGUIDs
+	flags := uint32(DEVICE_NOTIFY_WINDOW_HANDLE)
+	if len(guids) == 0 {
+		guids = []winapi.GUID{{}}
+		flags |= DEVICE_NOTIFY_ALL_INTERFACE_CLASSES
+	}
+	for _, g := range guids {
+		var filter devBroadcastDeviceInterface
+		filter.dbccSize = uint32(unsafe.Sizeof(filter))
+		filter.dbccDeviceType = DBT_DEVTYP_DEVICEINTERFACE
+		filter.dbccClassGuid = g
+		h, err := winspool.RegisterDeviceNotificationFilter(uintptr(w.hwnd), unsafe.Pointer(&filter), flags)
+		if err != nil {
+			return err
+		}
+		w.notifications = append(w.notifications, h)
+	}
+	return nil
+}
+
+func (w *DeviceWatcher) unregister() {
+	for _, h := range w.notifications {
+		winspool.UnregisterDeviceNotification(h)
+	}
+	w.notifications = nil
+}
+
+func (w *DeviceWatcher) deliver(ev *DeviceEvent) {
+	if !w.filter.Match(ev) {
+		return
+	}
+	select {
+	case w.events <- *ev:
+	case <-w.closing:
+	}
+}
+
+// createWatcherWindow creates a message-only window, or a hidden top-level one
+// for ports: their events are only broadcast to top-level windows.
+func createWatcherWindow(topLevel bool) (winapi.HWND, error) {
+	hInst, err := winapi.GetModuleHandle("")
+	if err != nil {
+		return 0, err
+	}
+	watcherClassOnce.Do(func() {
+		var wc winapi.Wndclassex
+		wc.Size = uint32(unsafe.Sizeof(wc))
+		wc.WndProc = syscall.NewCallback(watcherWndProc)
+		wc.Instance = hInst
+		wc.ClassName, watcherClassErr = wstr.PtrFromString(watcherClassName)
+		if watcherClassErr == nil {
+			_, watcherClassErr = winapi.RegisterClassExW(&wc)
+		}
+	})
+	if watcherClassErr != nil {
+		return 0, watcherClassErr
+	}
+	parent := winapi.HWND_MESSAGE
+	if topLevel {
+		parent = 0
+	}
+	return winapi.CreateWindowExW(0, watcherClassName, "", 0, 0, 0, 0, 0, parent, 0, hInst, 0)
+}
+
+func watcherWndProc(hwnd winapi.HWND, msg winapi.UINT, wParam winapi.WPARAM, lParam winapi.LPARAM) uintptr {
+	v, ok := watchers.Load(hwnd)
+	if !ok {
+		return winapi.DefWindowProcW(hwnd, msg, wParam, lParam)
+	}
+	w := v.(*DeviceWatcher)
+	switch msg {
+	case winapi.WM_DEVICECHANGE:
+		if lParam != 0 && (wParam == DBT_DEVICEARRIVAL || wParam == DBT_DEVICEREMOVECOMPLETE) {
+			hdr := (*uint32)(proc.Pointer(uintptr(lParam))) // dbch_size
+			payload := unsafe.Slice((*byte)(unsafe.Pointer(hdr)), *hdr)
+			if ev, ok := decodeDeviceChange(uintptr(wParam), payload); ok {
+				w.deliver(ev)
+			}
+		}
+		return 1 // TRUE
+	case winapi.WM_CLOSE:
+		w.unregister()
+		winapi.DestroyWindow(hwnd)
+		return 0
+	case winapi.WM_DESTROY:
+		winapi.PostQuitMessage(0)
+		return 0
+	}
+	return winapi.DefWindowProcW(hwnd, msg, wParam, lParam)
+}
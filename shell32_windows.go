@@ -0,0 +1,7 @@
+package winapi
+
+// Shell_NotifyIconW is Shell_NotifyIcon with the result as a bool too.
+func Shell_NotifyIconW(dwMessage uint32, lpData *NOTIFYICONDATA) (ret bool, err error) {
+	err = Shell_NotifyIcon(dwMessage, lpData)
+	return err == nil, err
+}
@@ -0,0 +1,77 @@
+package winapi
+
+const (
+	NIM_ADD        = 0x00000000
+	NIM_MODIFY     = 0x00000001
+	NIM_DELETE     = 0x00000002
+	NIM_SETFOCUS   = 0x00000003
+	NIM_SETVERSION = 0x00000004
+)
+
+const (
+	NIF_MESSAGE = 0x00000001
+	NIF_ICON    = 0x00000002
+	NIF_TIP     = 0x00000004
+	NIF_STATE   = 0x00000008
+	NIF_INFO    = 0x00000010
+	NIF_GUID    = 0x00000020
+
+	NIF_REALTIME = 0x00000040
+	NIF_SHOWTIP  = 0x00000080
+)
+
+const (
+	NIS_HIDDEN           = 0x00000001
+	NIS_SHAREDICON       = 0x00000002
+	NOTIFYICON_VERSION   = 3
+	NOTIFYICON_VERSION_4 = 4
+)
+
+// balloon icons and flags, DwInfoFlags
+const (
+	NIIF_NONE               = 0x00000000
+	NIIF_INFO               = 0x00000001
+	NIIF_WARNING            = 0x00000002
+	NIIF_ERROR              = 0x00000003
+	NIIF_USER               = 0x00000004
+	NIIF_ICON_MASK          = 0x0000000F
+	NIIF_NOSOUND            = 0x00000010
+	NIIF_LARGE_ICON         = 0x00000020
+	NIIF_RESPECT_QUIET_TIME = 0x00000080
+)
+
+// notifications sent as the LOWORD of lParam to UCallbackMessage, with
+// NOTIFYICON_VERSION_4 the mouse messages come along
+const (
+	NIN_SELECT           = WM_USER + 0
+	NINF_KEY             = 0x1
+	NIN_KEYSELECT        = NIN_SELECT | NINF_KEY
+	NIN_BALLOONSHOW      = WM_USER + 2
+	NIN_BALLOONHIDE      = WM_USER + 3
+	NIN_BALLOONTIMEOUT   = WM_USER + 4
+	NIN_BALLOONUSERCLICK = WM_USER + 5
+	NIN_POPUPOPEN        = WM_USER + 6
+	NIN_POPUPCLOSE       = WM_USER + 7
+)
+
+type NOTIFYICONDATA struct {
+	CbSize           DWORD
+	HWnd             HWND
+	UID              UINT
+	UFlags           UINT
+	UCallbackMessage UINT
+	HIcon            HICON
+
+	SzTip       [128]uint16 //WCHAR
+	DwState     DWORD
+	DwStateMask DWORD
+	SzInfo      [256]uint16 //WCHAR
+
+	UVersion UINT
+
+	SzInfoTitle [64]uint16 //WCHAR
+	DwInfoFlags DWORD
+
+	GuidItem     GUID
+	HBalloonIcon HICON
+}
@@ -0,0 +1,75 @@
+package winapi
+
+// Bindings generated by cmd/mkwinapi into zsys.go, see its documentation for
+// the //sys syntax. Add new functions here rather than by hand.
+
+//go:generate go run ./cmd/mkwinapi -output zsys.go sys.go
+
+// user32
+
+//sys	RegisterClassExW(wndclass *Wndclassex) (atom uint16, err error) = user32.RegisterClassExW
+//sys	RegisterClassW(wndclass *Wndclass) (atom uint16, err error) = user32.RegisterClassW
+// CreateWindowExW passes an empty classname as NULL.
+//sys	CreateWindowExW(exstyle uint32, classname optstring, windowname string, style uint32, x int32, y int32, width int32, height int32, wndparent HWND, menu HMENU, instance HINSTANCE, param uintptr) (hwnd HWND, err error) = user32.CreateWindowExW
+// CreateWindowExA passes classname and windowname as bytes, they should be
+// ASCII.
+//sys	CreateWindowExA(exstyle uint32, classname string, windowname string, style uint32, x int32, y int32, width int32, height int32, wndparent HWND, menu HMENU, instance HINSTANCE, param uintptr) (hwnd HWND, err error) = user32.CreateWindowExA
+//sys	DefWindowProcW(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (lresult uintptr) = user32.DefWindowProcW
+//sys	DestroyWindow(hwnd HWND) (err error) = user32.DestroyWindow
+//sys	PostQuitMessage(exitcode int32) = user32.PostQuitMessage
+//sys	ShowWindow(hwnd HWND, cmdshow int32) (wasvisible bool) = user32.ShowWindow
+//sys	UpdateWindow(hwnd HWND) (err error) = user32.UpdateWindow
+// GetMessage returns 0 for WM_QUIT.
+//sys	GetMessage(msg *Msg, hwnd HWND, MsgFilterMin uint32, MsgFilterMax uint32) (ret int32, err error) [failretval==-1] = user32.GetMessageW
+//sys	TranslateMessage(msg *Msg) (done bool) = user32.TranslateMessage
+//sys	DispatchMessageW(msg *Msg) (ret int32) = user32.DispatchMessageW
+//sys	SetCursor(cursor HCURSOR) (precursor HCURSOR, err error) = user32.SetCursor
+//sys	SendMessage(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (lresult uintptr) = user32.SendMessageW
+//sys	PostMessage(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (err error) = user32.PostMessageW
+//sys	PostThreadMessage(threadID uint32, msg UINT, wparam WPARAM, lparam LPARAM) (err error) = user32.PostThreadMessageW
+
+// GetLastInputInfo sets lii.DwTime to the tick count of the last input event
+// of the session, lii.CbSize must be set.
+//sys	GetLastInputInfo(lii *LASTINPUTINFO) (err error) = user32.GetLastInputInfo
+//sys	RegisterWindowMessage(name string) (msg uint32, err error) = user32.RegisterWindowMessageW
+//sys	IsWindow(hwnd HWND) (ok bool) = user32.IsWindow
+//sys	IsWindowVisible(hwnd HWND) (visible bool) = user32.IsWindowVisible
+// ShowCursor returns the new display counter, the cursor shows when it is >= 0.
+//sys	ShowCursor(show bool) (count int32) = user32.ShowCursor
+//sys	GetSystemMetrics(index int32) (value int32) = user32.GetSystemMetrics
+//sys	MapVirtualKey(code uint32, mapType uint32) (ret uint32) = user32.MapVirtualKeyW
+//sys	DestroyIcon(icon HICON) (err error) = user32.DestroyIcon
+//sys	CreateMenu() (menu HMENU, err error) = user32.CreateMenu
+// CreatePopupMenu returned an HWND before it was generated, see the README.
+//sys	CreatePopupMenu() (menu HMENU, err error) = user32.CreatePopupMenu
+//sys	DestroyMenu(menu HMENU) (err error) = user32.DestroyMenu
+//sys	DrawMenuBar(hwnd HWND) (err error) = user32.DrawMenuBar
+//sys	GetMenuItemCount(menu HMENU) (n int32, err error) [failretval==-1] = user32.GetMenuItemCount
+// CheckMenuItem returns the previous MF_CHECKED or MF_UNCHECKED, -1 if the
+// item doesn't exist.
+//sys	CheckMenuItem(menu HMENU, item uint32, check uint32) (prev int32) = user32.CheckMenuItem
+// EnableMenuItem returns the previous MF_ENABLED, MF_GRAYED or MF_DISABLED,
+// -1 if the item doesn't exist.
+//sys	EnableMenuItem(menu HMENU, item uint32, enable uint32) (prev int32) = user32.EnableMenuItem
+//sys	CheckMenuRadioItem(menu HMENU, first uint32, last uint32, check uint32, flags uint32) (err error) = user32.CheckMenuRadioItem
+//sys	RemoveMenu(menu HMENU, position uint32, flags uint32) (err error) = user32.RemoveMenu
+// InsertMenuItem inserts mii before the item at position, or with the id
+// item, mii.CbSize must be set.
+//sys	InsertMenuItem(menu HMENU, item uint32, byPosition bool, mii *MENUITEMINFO) (err error) = user32.InsertMenuItemW
+//sys	SetMenuItemInfo(menu HMENU, item uint32, byPosition bool, mii *MENUITEMINFO) (err error) = user32.SetMenuItemInfoW
+// TrackPopupMenuEx returns the chosen command with TPM_RETURNCMD, 0 when the
+// menu was dismissed. params may be nil.
+//sys	TrackPopupMenuEx(menu HMENU, flags uint32, x int32, y int32, hwnd HWND, params *TPMPARAMS) (cmd int32) = user32.TrackPopupMenuEx
+
+// shell32
+
+//sys	Shell_NotifyIcon(message uint32, data *NOTIFYICONDATA) (err error) = shell32.Shell_NotifyIconW
+
+// kernel32
+
+//sys	GetTickCount() (ms uint32) = kernel32.GetTickCount
+//sys	GetTickCount64() (ms uint64) = kernel32.GetTickCount64
+//sys	GetCurrentProcessId() (pid uint32) = kernel32.GetCurrentProcessId
+// FormatMessage writes the message into buf, n is its length without the NUL.
+//sys	FormatMessage(flags uint32, source uintptr, msgID uint32, langID uint32, buf []uint16, args *byte) (n uint32, err error) = kernel32.FormatMessageW
+//sys	SetFilePointerEx(file HANDLE, distance int64, newPos *int64, method uint32) (err error) = kernel32.SetFilePointerEx
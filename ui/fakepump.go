@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// ErrQueueFull is returned by FakePump.Post when its queue is full, as
+// PostThreadMessage fails with ERROR_NOT_ENOUGH_QUOTA.
+var ErrQueueFull = errors.New("fake pump queue full")
+
+// fakeInvokeHWND stands for the window a FakePump wakes the loop through.
+const fakeInvokeHWND winapi.HWND = 0x3f1
+
+// FakePump is a Pump without Windows, to drive a UIThread on any platform.
+// The dispatched messages are recorded rather than sent to a window
+// procedure, unless WndProc is set. The goroutine running the loop stands for
+// its thread.
+type FakePump struct {
+	// WndProc, when set, receives the dispatched messages.
+	WndProc func(msg *winapi.Msg)
+
+	msgs chan winapi.Msg
+
+	mu         sync.Mutex
+	threadID   uint32
+	invoke     func()
+	dispatched []winapi.Msg
+	dropped    []winapi.Msg
+}
+
+// NewFakePump returns a pump whose queue holds size messages.
+func NewFakePump(size int) *FakePump {
+	return &FakePump{msgs: make(chan winapi.Msg, size)}
+}
+
+func (p *FakePump) Init(invoke func()) (uint32, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.threadID = goroutineID()
+	p.invoke = invoke
+	return p.threadID, nil
+}
+
+func (p *FakePump) ThreadID() uint32 {
+	return goroutineID()
+}
+
+func (p *FakePump) Get(msg *winapi.Msg) (bool, error) {
+	*msg = <-p.msgs
+	return msg.Message != winapi.WM_QUIT, nil
+}
+
+func (p *FakePump) Dispatch(msg *winapi.Msg) {
+	p.mu.Lock()
+	if msg.Hwnd == fakeInvokeHWND {
+		invoke := p.invoke
+		p.mu.Unlock()
+		if msg.Message == wmInvoke {
+			invoke()
+		}
+		return
+	}
+	p.dispatched = append(p.dispatched, *msg)
+	p.mu.Unlock()
+	if p.WndProc != nil {
+		p.WndProc(msg)
+	}
+}
+
+func (p *FakePump) Post(threadID uint32, msg uint32, wParam, lParam uintptr) error {
+	p.mu.Lock()
+	ok := threadID == p.threadID
+	p.mu.Unlock()
+	if !ok {
+		return &winapi.Error{API: "PostThreadMessageW", Errno: winapi.ERROR_INVALID_THREAD_ID}
+	}
+	return p.Send(winapi.Msg{Message: msg, Wparam: wParam, Lparam: lParam})
+}
+
+func (p *FakePump) Wake() error {
+	return p.Send(winapi.Msg{Hwnd: fakeInvokeHWND, Message: wmInvoke})
+}
+
+func (p *FakePump) Close() {}
+
+// RunModal runs a modal loop until done is closed, as TrackPopupMenuEx or
+// MessageBox do on the loop thread: the window messages are dispatched, the
+// thread messages are dropped. WM_QUIT is posted again and ends it.
+func (p *FakePump) RunModal(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case msg := <-p.msgs:
+			switch {
+			case msg.Message == winapi.WM_QUIT:
+				p.Send(msg)
+				return
+			case msg.Hwnd == 0:
+				p.mu.Lock()
+				p.dropped = append(p.dropped, msg)
+				p.mu.Unlock()
+			default:
+				p.Dispatch(&msg)
+			}
+		}
+	}
+}
+
+// Send queues msg as is, a message for a window when msg.Hwnd is set, as
+// PostMessage would.
+func (p *FakePump) Send(msg winapi.Msg) error {
+	select {
+	case p.msgs <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Dispatched returns the messages dispatched so far.
+func (p *FakePump) Dispatched() []winapi.Msg {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]winapi.Msg(nil), p.dispatched...)
+}
+
+// Dropped returns the thread messages RunModal dropped.
+func (p *FakePump) Dropped() []winapi.Msg {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]winapi.Msg(nil), p.dropped...)
+}
+
+// goroutineID parses the id from the "goroutine 18 [running]:" header of the
+// stack trace.
+func goroutineID() uint32 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 32)
+	return uint32(id)
+}
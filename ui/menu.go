@@ -0,0 +1,297 @@
+package ui
+
+import (
+	"unsafe"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/pkg/errors"
+)
+
+// The command ids given to the items, above the ids usually given by hand to
+// controls and below the SC_ ones.
+const (
+	firstMenuID = 0x8000
+	lastMenuID  = 0xEFFF
+)
+
+var ErrMenuFull = errors.New("no command id left for the menu")
+
+// Menu describes a menu bar or a popup menu, built into an HMENU by BuildBar
+// or BuildPopup. The items get a command id as they are built, and Command
+// routes the WM_COMMAND of the window to their OnClick:
+//
+//	large := &ui.MenuItem{Text: "Large", Radio: 1, Checked: true}
+//	m := ui.NewMenu(
+//		&ui.MenuItem{Text: "&Open", OnClick: open},
+//		&ui.MenuItem{Text: "View", Items: []*ui.MenuItem{
+//			large,
+//			{Text: "Small", Radio: 1},
+//		}},
+//		ui.Separator(),
+//		&ui.MenuItem{Text: "E&xit", OnClick: exit},
+//	)
+//	bar, err := m.BuildBar()
+//	winapi.SetMenu(hwnd, bar)
+//	...
+//	case winapi.WM_COMMAND:
+//		if m.Command(wParam, lParam) {
+//			return 0
+//		}
+//
+// A Menu is used from the thread of its window.
+type Menu struct {
+	Items []*MenuItem
+
+	ids    map[uint16]*MenuItem
+	nextID uint16
+	handle winapi.HMENU
+}
+
+// MenuItem is a command, a separator when Text and Items are empty, or a
+// submenu when Items is set.
+type MenuItem struct {
+	Text     string // & before the mnemonic, \t before the shortcut
+	Checked  bool
+	Disabled bool
+	// Radio groups the consecutive items with the same non zero value,
+	// clicking one checks it and unchecks the others.
+	Radio   int
+	Items   []*MenuItem
+	OnClick func(item *MenuItem)
+
+	id    uint16
+	menu  winapi.HMENU // built into
+	group []*MenuItem  // radio group
+}
+
+func NewMenu(items ...*MenuItem) *Menu {
+	return &Menu{Items: items}
+}
+
+func Separator() *MenuItem {
+	return &MenuItem{}
+}
+
+// ID returns the command id of the item, 0 before it was assigned one.
+func (it *MenuItem) ID() uint16 {
+	return it.id
+}
+
+func (it *MenuItem) isSeparator() bool {
+	return it.Text == "" && it.Items == nil
+}
+
+// SetChecked checks the item, in the built menu too.
+func (it *MenuItem) SetChecked(checked bool) {
+	it.Checked = checked
+	if it.menu != 0 {
+		flags := uint32(winapi.MF_BYCOMMAND | winapi.MF_UNCHECKED)
+		if checked {
+			flags = winapi.MF_BYCOMMAND | winapi.MF_CHECKED
+		}
+		winapi.CheckMenuItem(it.menu, uint32(it.id), flags)
+	}
+}
+
+// SetDisabled grays the item out, in the built menu too.
+func (it *MenuItem) SetDisabled(disabled bool) {
+	it.Disabled = disabled
+	if it.menu != 0 {
+		flags := uint32(winapi.MF_BYCOMMAND | winapi.MF_ENABLED)
+		if disabled {
+			flags = winapi.MF_BYCOMMAND | winapi.MF_GRAYED
+		}
+		winapi.EnableMenuItem(it.menu, uint32(it.id), flags)
+	}
+}
+
+// Assign gives a command id to the items that have none and groups the radio
+// items, the build does it. The ids stay the same as long as the items stay in
+// the menu.
+func (m *Menu) Assign() error {
+	old := m.ids
+	m.ids = map[uint16]*MenuItem{}
+	var fresh []*MenuItem
+	var walk func(items []*MenuItem)
+	walk = func(items []*MenuItem) {
+		for i, it := range items {
+			it.group = nil
+			if it.Radio != 0 {
+				if i > 0 && items[i-1].Radio == it.Radio {
+					it.group = items[i-1].group
+				}
+				it.group = append(it.group, it)
+				for _, o := range it.group {
+					o.group = it.group
+				}
+			}
+			if it.Items != nil {
+				walk(it.Items)
+			}
+			if it.isSeparator() {
+				continue
+			}
+			if it.id != 0 && old[it.id] == it && m.ids[it.id] == nil {
+				m.ids[it.id] = it
+			} else {
+				fresh = append(fresh, it)
+			}
+		}
+	}
+	walk(m.Items)
+	for _, it := range fresh {
+		id, err := m.allocID()
+		if err != nil {
+			return err
+		}
+		it.id = id
+		m.ids[id] = it
+	}
+	return nil
+}
+
+func (m *Menu) allocID() (uint16, error) {
+	for n := 0; n <= lastMenuID-firstMenuID; n++ {
+		if m.nextID < firstMenuID || m.nextID > lastMenuID {
+			m.nextID = firstMenuID
+		}
+		id := m.nextID
+		m.nextID++
+		if m.ids[id] == nil {
+			return id, nil
+		}
+	}
+	return 0, ErrMenuFull
+}
+
+// Item returns the item with the command id.
+func (m *Menu) Item(id uint16) *MenuItem {
+	return m.ids[id]
+}
+
+// Command handles the WM_COMMAND sent by the menu, or by an accelerator
+// using the ids of its items, false for the notifications of controls and
+// the ids of other menus.
+func (m *Menu) Command(wParam winapi.WPARAM, lParam winapi.LPARAM) bool {
+	if lParam != 0 || wParam>>16&0xFFFF > 1 {
+		return false
+	}
+	return m.Click(uint16(wParam))
+}
+
+// Click runs the item with the command id as if it was chosen.
+func (m *Menu) Click(id uint16) bool {
+	it := m.ids[id]
+	if it == nil || it.Disabled {
+		return it != nil
+	}
+	if it.Radio != 0 {
+		for _, o := range it.group {
+			if o != it && o.Checked {
+				o.SetChecked(false)
+			}
+		}
+		it.SetChecked(true)
+	}
+	if it.OnClick != nil {
+		it.OnClick(it)
+	}
+	return true
+}
+
+// BuildBar builds the menu as a menu bar, for winapi.SetMenu. The window
+// destroys it with itself.
+func (m *Menu) BuildBar() (winapi.HMENU, error) {
+	return m.build(winapi.CreateMenu)
+}
+
+// BuildPopup builds the menu as a popup menu, for TrackPopupMenuEx. Destroy
+// it once done.
+func (m *Menu) BuildPopup() (winapi.HMENU, error) {
+	return m.build(winapi.CreatePopupMenu)
+}
+
+// Destroy destroys the menu built last, the menu bar of a window is destroyed
+// with the window instead.
+func (m *Menu) Destroy() error {
+	if m.handle == 0 {
+		return nil
+	}
+	err := winapi.DestroyMenu(m.handle)
+	m.detach(m.Items)
+	m.handle = 0
+	return err
+}
+
+func (m *Menu) build(create func() (winapi.HMENU, error)) (winapi.HMENU, error) {
+	if err := m.Assign(); err != nil {
+		return 0, err
+	}
+	menu, err := create()
+	if err != nil {
+		return 0, err
+	}
+	if err := m.insert(menu, m.Items); err != nil {
+		winapi.DestroyMenu(menu)
+		m.detach(m.Items)
+		return 0, err
+	}
+	m.handle = menu
+	return menu, nil
+}
+
+func (m *Menu) insert(menu winapi.HMENU, items []*MenuItem) error {
+	for i, it := range items {
+		mii := winapi.MENUITEMINFO{FMask: winapi.MIIM_FTYPE}
+		mii.CbSize = uint32(unsafe.Sizeof(mii))
+		if it.isSeparator() {
+			mii.FType = winapi.MFT_SEPARATOR
+		} else {
+			text, err := proc.UTF16PtrFromString(it.Text)
+			if err != nil {
+				return errors.Wrapf(err, "menu item %q", it.Text)
+			}
+			mii.FMask |= winapi.MIIM_STRING | winapi.MIIM_STATE | winapi.MIIM_ID
+			mii.FType = winapi.MFT_STRING
+			mii.DwTypeData = text
+			mii.WID = uint32(it.id)
+			if it.Radio != 0 {
+				mii.FType |= winapi.MFT_RADIOCHECK
+			}
+			if it.Checked {
+				mii.FState |= winapi.MFS_CHECKED
+			}
+			if it.Disabled {
+				mii.FState |= winapi.MFS_GRAYED
+			}
+			if it.Items != nil {
+				sub, err := winapi.CreatePopupMenu()
+				if err != nil {
+					return err
+				}
+				if err := m.insert(sub, it.Items); err != nil {
+					winapi.DestroyMenu(sub)
+					return err
+				}
+				mii.FMask |= winapi.MIIM_SUBMENU
+				mii.HSubMenu = sub
+			}
+		}
+		if err := winapi.InsertMenuItem(menu, uint32(i), true, &mii); err != nil {
+			if mii.HSubMenu != 0 {
+				winapi.DestroyMenu(mii.HSubMenu)
+			}
+			return errors.Wrapf(err, "insert menu item %q failed", it.Text)
+		}
+		it.menu = menu
+	}
+	return nil
+}
+
+func (m *Menu) detach(items []*MenuItem) {
+	for _, it := range items {
+		it.menu = 0
+		m.detach(it.Items)
+	}
+}
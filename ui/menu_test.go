@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+func assign(t *testing.T, m *Menu) {
+	t.Helper()
+	if err := m.Assign(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMenuAssign(t *testing.T) {
+	open, exit := &MenuItem{Text: "&Open"}, &MenuItem{Text: "E&xit"}
+	large, small := &MenuItem{Text: "Large"}, &MenuItem{Text: "Small"}
+	view := &MenuItem{Text: "View", Items: []*MenuItem{large, small}}
+	sep := Separator()
+	m := NewMenu(open, view, sep, exit)
+	assign(t, m)
+
+	seen := map[uint16]bool{}
+	for _, it := range []*MenuItem{open, view, large, small, exit} {
+		id := it.ID()
+		if id < firstMenuID || id > lastMenuID || seen[id] {
+			t.Errorf("%s: id %#x", it.Text, id)
+		}
+		seen[id] = true
+		if m.Item(id) != it {
+			t.Errorf("Item(%#x) = %v, want %s", id, m.Item(id), it.Text)
+		}
+	}
+	if sep.ID() != 0 {
+		t.Errorf("separator id %#x", sep.ID())
+	}
+
+	// the ids stay as the menu changes
+	ids := map[*MenuItem]uint16{open: open.ID(), large: large.ID(), exit: exit.ID()}
+	save := &MenuItem{Text: "&Save"}
+	m.Items = []*MenuItem{save, open, view, exit}
+	view.Items = []*MenuItem{large}
+	assign(t, m)
+	for it, id := range ids {
+		if it.ID() != id {
+			t.Errorf("%s: id %#x, was %#x", it.Text, it.ID(), id)
+		}
+	}
+	if id := save.ID(); id == 0 || seen[id] {
+		t.Errorf("new item id %#x", id)
+	}
+	if m.Item(small.ID()) != nil {
+		t.Error("removed item still has its id")
+	}
+
+	// an item moved to another menu gets an id there
+	other := NewMenu(&MenuItem{Text: "Other"}, open)
+	assign(t, other)
+	if other.Item(open.ID()) != open || other.Items[0].ID() == open.ID() {
+		t.Errorf("moved item: id %#x, other item %#x", open.ID(), other.Items[0].ID())
+	}
+}
+
+func TestMenuFull(t *testing.T) {
+	m := &Menu{}
+	for i := 0; i <= lastMenuID-firstMenuID; i++ {
+		m.Items = append(m.Items, &MenuItem{Text: "item"})
+	}
+	assign(t, m)
+	m.Items = append(m.Items, &MenuItem{Text: "one too many"})
+	if err := m.Assign(); err != ErrMenuFull {
+		t.Errorf("Assign() = %v, want ErrMenuFull", err)
+	}
+}
+
+func TestMenuRadio(t *testing.T) {
+	var clicked []string
+	onClick := func(it *MenuItem) { clicked = append(clicked, it.Text) }
+	a := &MenuItem{Text: "a", Radio: 1, Checked: true, OnClick: onClick}
+	b := &MenuItem{Text: "b", Radio: 1, OnClick: onClick}
+	c := &MenuItem{Text: "c", Radio: 1, Disabled: true, OnClick: onClick}
+	// after a separator, another group with the same value
+	d := &MenuItem{Text: "d", Radio: 1, Checked: true, OnClick: onClick}
+	e := &MenuItem{Text: "e", Radio: 2, Checked: true, OnClick: onClick}
+	m := NewMenu(a, b, c, Separator(), d, e)
+	assign(t, m)
+
+	if !m.Click(b.ID()) || !m.Click(c.ID()) {
+		t.Fatal("Click() of an item returned false")
+	}
+	if a.Checked || !b.Checked || c.Checked || !d.Checked || !e.Checked {
+		t.Errorf("checked a=%v b=%v c=%v d=%v e=%v", a.Checked, b.Checked, c.Checked, d.Checked, e.Checked)
+	}
+	if len(clicked) != 1 || clicked[0] != "b" {
+		t.Errorf("OnClick of %q, the disabled item excluded", clicked)
+	}
+
+	// the groups follow the items, b left the menu checked
+	m.Items = []*MenuItem{a, d}
+	assign(t, m)
+	m.Click(a.ID())
+	if !a.Checked || !b.Checked || d.Checked {
+		t.Errorf("regrouped: checked a=%v b=%v d=%v", a.Checked, b.Checked, d.Checked)
+	}
+}
+
+func TestMenuCommand(t *testing.T) {
+	clicks := 0
+	it := &MenuItem{Text: "item", OnClick: func(*MenuItem) { clicks++ }}
+	m := NewMenu(it)
+	assign(t, m)
+	id := winapi.WPARAM(it.ID())
+	tests := []struct {
+		name    string
+		wParam  winapi.WPARAM
+		lParam  winapi.LPARAM
+		handled bool
+	}{
+		{"menu", id, 0, true},
+		{"accelerator", 1<<16 | id, 0, true},
+		{"control notification", 5<<16 | id, 0, false},
+		{"control", id, 0x1234, false},
+		{"other id", id + 1, 0, false},
+	}
+	for _, tt := range tests {
+		if got := m.Command(tt.wParam, tt.lParam); got != tt.handled {
+			t.Errorf("%s: Command(%#x, %#x) = %v", tt.name, tt.wParam, tt.lParam, got)
+		}
+	}
+	if clicks != 2 {
+		t.Errorf("OnClick called %d times, want 2", clicks)
+	}
+}
+
+// TestMenuBuildError checks that a failed build leaves the items unattached,
+// off Windows there are no menus.
+func TestMenuBuildError(t *testing.T) {
+	it := &MenuItem{Text: "item"}
+	m := NewMenu(it)
+	if _, err := m.BuildPopup(); !errors.Is(err, winapi.ErrNotSupported) {
+		t.Skipf("BuildPopup() = %v", err)
+	}
+	if m.handle != 0 || it.menu != 0 || it.ID() == 0 {
+		t.Errorf("after a failed build: handle %#x, item menu %#x, id %#x", m.handle, it.menu, it.ID())
+	}
+	if err := m.Destroy(); err != nil {
+		t.Errorf("Destroy() = %v", err)
+	}
+	// SetChecked on an unbuilt item only changes the field
+	it.SetChecked(true)
+	it.SetDisabled(true)
+	if !it.Checked || !it.Disabled {
+		t.Error("SetChecked or SetDisabled ignored")
+	}
+}
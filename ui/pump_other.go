@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import "github.com/FxStar/winapi"
+
+// threadPump has no queue on other platforms, use a FakePump.
+type threadPump struct{}
+
+func newPump() Pump {
+	return threadPump{}
+}
+
+func (threadPump) Init(invoke func()) (uint32, error) {
+	return 0, winapi.ErrNotSupported
+}
+
+func (threadPump) ThreadID() uint32 {
+	return 0
+}
+
+func (threadPump) Get(msg *winapi.Msg) (bool, error) {
+	return false, winapi.ErrNotSupported
+}
+
+func (threadPump) Dispatch(msg *winapi.Msg) {}
+
+func (threadPump) Post(threadID uint32, msg uint32, wParam, lParam uintptr) error {
+	return winapi.ErrNotSupported
+}
+
+func (threadPump) Wake() error {
+	return winapi.ErrNotSupported
+}
+
+func (threadPump) Close() {}
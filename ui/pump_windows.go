@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// threadPump is the Windows message queue of the loop thread.
+type threadPump struct {
+	hwnd winapi.HWND // of the message-only window wmInvoke is posted to
+}
+
+func newPump() Pump {
+	return &threadPump{}
+}
+
+// invokeHandler handles the messages of the window of a threadPump.
+type invokeHandler func()
+
+func (f invokeHandler) OnMessage(w *Window, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	if msg != wmInvoke {
+		return 0, false
+	}
+	f()
+	return 0, true
+}
+
+func (p *threadPump) Init(invoke func()) (uint32, error) {
+	var msg winapi.Msg
+	// make sure the thread has a message queue before anyone posts to it
+	winapi.PeekMessage(&msg, 0, winapi.WM_USER, winapi.WM_USER, winapi.PM_NOREMOVE)
+	tid, err := winapi.GetCurrentThreadId()
+	if err != nil {
+		return 0, err
+	}
+	w := &Window{handler: invokeHandler(invoke)}
+	if err := createWindow(w, &WindowOptions{Parent: winapi.HWND_MESSAGE}); err != nil {
+		return 0, errors.Wrap(err, "create invoke window failed")
+	}
+	p.hwnd = w.hwnd
+	return uint32(tid), nil
+}
+
+func (p *threadPump) ThreadID() uint32 {
+	tid, _ := winapi.GetCurrentThreadId()
+	return uint32(tid)
+}
+
+func (p *threadPump) Get(msg *winapi.Msg) (bool, error) {
+	ret, err := winapi.GetMessage(msg, 0, 0, 0)
+	return ret != 0 && err == nil, err
+}
+
+func (p *threadPump) Dispatch(msg *winapi.Msg) {
+	winapi.TranslateMessage(msg)
+	winapi.DispatchMessageW(msg)
+}
+
+func (p *threadPump) Post(threadID uint32, msg uint32, wParam, lParam uintptr) error {
+	return winapi.PostThreadMessage(threadID, winapi.UINT(msg), winapi.WPARAM(wParam), winapi.LPARAM(lParam))
+}
+
+func (p *threadPump) Wake() error {
+	return winapi.PostMessage(p.hwnd, wmInvoke, 0, 0)
+}
+
+// Close leaves hwnd as is, a late Wake fails on the destroyed window.
+func (p *threadPump) Close() {
+	destroyWindow(p.hwnd)
+}
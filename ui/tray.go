@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"unsafe"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/wstr"
+	"github.com/pkg/errors"
+)
+
+// trayMessage is the UCallbackMessage of the tray icons, each has a window of
+// its own so that the icon id is always 1.
+const (
+	trayMessage = winapi.WM_APP + 0x3f2
+	trayIconID  = 1
+)
+
+var ErrNoTrayIcon = errors.New("tray icon not added")
+
+// TrayIcon is an icon in the notification area, with a hidden window on a
+// UIThread to receive its clicks:
+//
+//	tray := &ui.TrayIcon{OnClick: showStatus}
+//	tray.SetMenu(ui.NewMenu(
+//		&ui.MenuItem{Text: "Pause", OnClick: pause},
+//		ui.Separator(),
+//		&ui.MenuItem{Text: "Exit", OnClick: exit},
+//	))
+//	if err := tray.Add(thread, icon, "Print service"); err != nil {
+//	}
+//	defer tray.Remove()
+//	tray.ShowBalloon("Printer", "Out of paper", winapi.NIIF_WARNING)
+//
+// The handlers run on the thread, set them before Add. The icon comes back
+// by itself when Explorer restarts.
+type TrayIcon struct {
+	OnClick        func() // left click, or Enter on the focused icon
+	OnDoubleClick  func()
+	OnRightClick   func() // before the menu shows
+	OnBalloonClick func()
+	// OnError gets the failures no call returns: the icon not coming back
+	// after Explorer restarted, the menu failing to show.
+	OnError func(err error)
+
+	thread         *UIThread
+	window         *Window
+	data           winapi.NOTIFYICONDATA
+	menu           *Menu
+	taskbarCreated uint32
+}
+
+// Add creates the window of the icon on t and shows the icon.
+func (ti *TrayIcon) Add(t *UIThread, icon winapi.HICON, tooltip string) error {
+	if ti.thread != nil && ti.thread != t {
+		return errors.New("tray icon added to another thread")
+	}
+	ti.thread = t
+	return t.Invoke(func() error {
+		if ti.window != nil {
+			return errors.New("tray icon already added")
+		}
+		msg, err := winapi.RegisterWindowMessage("TaskbarCreated")
+		if err != nil {
+			return errors.Wrap(err, "register TaskbarCreated failed")
+		}
+		ti.taskbarCreated = msg
+		// a hidden top-level window, message-only windows don't receive the
+		// TaskbarCreated broadcast
+		w, err := NewWindow(t, WindowOptions{Handler: trayHandler{ti}})
+		if err != nil {
+			return errors.Wrap(err, "create tray window failed")
+		}
+		ti.window = w
+		ti.data = winapi.NOTIFYICONDATA{
+			HWnd:             w.HWND(),
+			UID:              trayIconID,
+			UCallbackMessage: trayMessage,
+			HIcon:            icon,
+		}
+		ti.data.CbSize = winapi.DWORD(unsafe.Sizeof(ti.data))
+		wstr.Copy(ti.data.SzTip[:], tooltip)
+		if err := ti.add(); err != nil {
+			w.Destroy()
+			ti.window = nil
+			return err
+		}
+		return nil
+	})
+}
+
+// Remove removes the icon and destroys its window.
+func (ti *TrayIcon) Remove() error {
+	return ti.invoke(func() error {
+		data := ti.data
+		data.UFlags = 0
+		err := winapi.Shell_NotifyIcon(winapi.NIM_DELETE, &data)
+		ti.window.Destroy()
+		ti.window = nil
+		return errors.Wrap(err, "delete tray icon failed")
+	})
+}
+
+// SetTooltip changes the tooltip, truncated to the 127 UTF-16 units szTip
+// holds.
+func (ti *TrayIcon) SetTooltip(tooltip string) error {
+	return ti.invoke(func() error {
+		wstr.Copy(ti.data.SzTip[:], tooltip)
+		return ti.modify(winapi.NIF_TIP | winapi.NIF_SHOWTIP)
+	})
+}
+
+// SetIcon changes the icon, which stays owned by the caller.
+func (ti *TrayIcon) SetIcon(icon winapi.HICON) error {
+	return ti.invoke(func() error {
+		ti.data.HIcon = icon
+		return ti.modify(winapi.NIF_ICON)
+	})
+}
+
+// ShowBalloon shows a notification next to the icon. flags is NIIF_INFO,
+// NIIF_WARNING, NIIF_ERROR or NIIF_NONE, with NIIF_NOSOUND or
+// NIIF_RESPECT_QUIET_TIME.
+func (ti *TrayIcon) ShowBalloon(title, text string, flags uint32) error {
+	return ti.invoke(func() error {
+		data := ti.data
+		data.UFlags = winapi.NIF_INFO
+		data.DwInfoFlags = winapi.DWORD(flags)
+		wstr.Copy(data.SzInfoTitle[:], title)
+		wstr.Copy(data.SzInfo[:], text)
+		return errors.Wrap(winapi.Shell_NotifyIcon(winapi.NIM_MODIFY, &data), "show balloon failed")
+	})
+}
+
+// SetMenu sets the menu shown on right click, none when m is nil. The menu
+// is built each time it shows.
+func (ti *TrayIcon) SetMenu(m *Menu) error {
+	if ti.thread == nil {
+		ti.menu = m
+		return nil
+	}
+	return ti.invoke(func() error {
+		ti.menu = m
+		return nil
+	})
+}
+
+func (ti *TrayIcon) invoke(fn func() error) error {
+	if ti.thread == nil {
+		return ErrNoTrayIcon
+	}
+	return ti.thread.Invoke(func() error {
+		if ti.window == nil {
+			return ErrNoTrayIcon
+		}
+		return fn()
+	})
+}
+
+// add adds the icon, again after Explorer restarted.
+func (ti *TrayIcon) add() error {
+	ti.data.UFlags = winapi.NIF_MESSAGE | winapi.NIF_ICON | winapi.NIF_TIP | winapi.NIF_SHOWTIP
+	if err := winapi.Shell_NotifyIcon(winapi.NIM_ADD, &ti.data); err != nil {
+		return errors.Wrap(err, "add tray icon failed")
+	}
+	// version 4 sends NIN_SELECT and WM_CONTEXTMENU with the icon position
+	ti.data.UVersion = winapi.NOTIFYICON_VERSION_4
+	return errors.Wrap(winapi.Shell_NotifyIcon(winapi.NIM_SETVERSION, &ti.data), "set tray icon version failed")
+}
+
+func (ti *TrayIcon) modify(flags uint32) error {
+	ti.data.UFlags = winapi.UINT(flags)
+	return errors.Wrap(winapi.Shell_NotifyIcon(winapi.NIM_MODIFY, &ti.data), "modify tray icon failed")
+}
+
+// handle processes the messages of the tray window, false for the ones left
+// to DefWindowProc.
+func (ti *TrayIcon) handle(msg uint32, wParam, lParam uintptr) bool {
+	if msg == ti.taskbarCreated && msg != 0 {
+		if err := ti.add(); err != nil {
+			ti.fail(errors.Wrap(err, "restore tray icon failed"))
+		}
+		return true
+	}
+	if msg != trayMessage {
+		return false
+	}
+	// wParam holds the position of the icon, or of the mouse
+	x, y := int32(int16(wParam)), int32(int16(wParam>>16))
+	switch uint32(lParam & 0xFFFF) {
+	case winapi.NIN_SELECT, winapi.NIN_KEYSELECT:
+		call(ti.OnClick)
+	case winapi.WM_LBUTTONDBLCLK:
+		call(ti.OnDoubleClick)
+	case winapi.WM_CONTEXTMENU:
+		call(ti.OnRightClick)
+		if m := ti.menu; m != nil && len(m.Items) > 0 && ti.window != nil {
+			cmd, err := showTrayMenu(ti.window.HWND(), m, x, y)
+			if err != nil {
+				ti.fail(err)
+			} else if cmd != 0 {
+				m.Click(cmd)
+			}
+		}
+	case winapi.NIN_BALLOONUSERCLICK:
+		call(ti.OnBalloonClick)
+	}
+	return true
+}
+
+func (ti *TrayIcon) fail(err error) {
+	if ti.OnError != nil {
+		ti.OnError(err)
+	}
+}
+
+// trayHandler keeps the handler method off the TrayIcon API.
+type trayHandler struct{ ti *TrayIcon }
+
+func (h trayHandler) OnMessage(w *Window, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	return 0, h.ti.handle(msg, wParam, lParam)
+}
+
+func call(fn func()) {
+	if fn != nil {
+		fn()
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import "github.com/FxStar/winapi"
+
+func showTrayMenu(hwnd winapi.HWND, m *Menu, x, y int32) (uint16, error) {
+	return 0, winapi.ErrNotSupported
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+func TestTrayIconHandle(t *testing.T) {
+	var clicks []string
+	ti := &TrayIcon{
+		OnClick:        func() { clicks = append(clicks, "click") },
+		OnDoubleClick:  func() { clicks = append(clicks, "double") },
+		OnBalloonClick: func() { clicks = append(clicks, "balloon") },
+	}
+	for _, ev := range []uintptr{winapi.NIN_SELECT, winapi.NIN_KEYSELECT, winapi.WM_LBUTTONDBLCLK, winapi.NIN_BALLOONUSERCLICK, winapi.WM_MOUSEMOVE} {
+		if !ti.handle(trayMessage, 0, ev|trayIconID<<16) {
+			t.Errorf("tray message %#x not handled", ev)
+		}
+	}
+	if want := "[click click double balloon]"; fmt.Sprint(clicks) != want {
+		t.Errorf("handlers called %v, want %s", clicks, want)
+	}
+	if ti.handle(winapi.WM_USER, 0, 0) {
+		t.Error("other message handled")
+	}
+}
+
+// TestTrayIconErrors checks that the failures without a caller reach OnError.
+// Off Windows both the icon and the menu fail with ErrNotSupported.
+func TestTrayIconErrors(t *testing.T) {
+	var errs []error
+	ti := &TrayIcon{OnError: func(err error) { errs = append(errs, err) }}
+
+	ti.taskbarCreated = 0xC0F0
+	if !ti.handle(ti.taskbarCreated, 0, 0) {
+		t.Error("TaskbarCreated not handled")
+	}
+
+	rightClicks := 0
+	ti.OnRightClick = func() { rightClicks++ }
+	ti.window = &Window{}
+	ti.menu = NewMenu(&MenuItem{Text: "Exit", OnClick: func(*MenuItem) { t.Error("menu item clicked") }})
+	ti.handle(trayMessage, 0, winapi.WM_CONTEXTMENU|trayIconID<<16)
+
+	if len(errs) != 2 || rightClicks != 1 {
+		t.Fatalf("OnError got %v after %d right clicks", errs, rightClicks)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, winapi.ErrNotSupported) {
+			t.Errorf("OnError got %v", err)
+		}
+	}
+
+	ti.OnError = nil // the failures are dropped
+	ti.handle(ti.taskbarCreated, 0, 0)
+}
@@ -0,0 +1,22 @@
+package ui
+
+import (
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// showTrayMenu returns the command id of the chosen item, 0 when the menu was
+// dismissed.
+func showTrayMenu(hwnd winapi.HWND, m *Menu, x, y int32) (uint16, error) {
+	menu, err := m.BuildPopup()
+	if err != nil {
+		return 0, errors.Wrap(err, "build tray menu failed")
+	}
+	defer m.Destroy()
+	// the menu only closes on a click elsewhere when its window is in the
+	// foreground, and the posted message makes the next show work
+	winapi.SetForegroundWindow(hwnd)
+	cmd := winapi.TrackPopupMenuEx(menu, winapi.TPM_RETURNCMD|winapi.TPM_NONOTIFY|winapi.TPM_RIGHTBUTTON, x, y, hwnd, nil)
+	winapi.PostMessage(hwnd, winapi.WM_NULL, 0, 0)
+	return uint16(cmd), nil
+}
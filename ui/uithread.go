@@ -0,0 +1,261 @@
+// Package ui runs the message loop windows need: the thread that creates a
+// window must be the one that pumps its messages, and UIThread is that thread.
+package ui
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+// wmInvoke wakes the loop up to run the queued functions. It is posted to a
+// message-only window of the thread, not to the thread: the modal loops of
+// menus, message boxes and window moves drop the thread messages.
+const wmInvoke = winapi.WM_APP + 0x3f1
+
+var (
+	ErrRunning = errors.New("ui thread already running")
+	ErrStopped = errors.New("ui thread not running")
+)
+
+// Pump is the message queue of the thread running the loop. The thread's
+// Windows queue is used by default, a FakePump exercises the loop anywhere.
+type Pump interface {
+	// Init is called first on the loop thread, it makes sure the thread has
+	// a queue, creates the window Wake posts to and returns the thread id.
+	// invoke runs the queued functions.
+	Init(invoke func()) (threadID uint32, err error)
+	// ThreadID returns the id of the calling thread.
+	ThreadID() uint32
+	// Get waits for a message, false for WM_QUIT.
+	Get(msg *winapi.Msg) (bool, error)
+	// Dispatch translates msg and sends it to its window procedure.
+	Dispatch(msg *winapi.Msg)
+	// Post queues a thread message, from any thread.
+	Post(threadID uint32, msg uint32, wParam, lParam uintptr) error
+	// Wake posts wmInvoke to the window of Init, from any thread, so that
+	// invoke runs when it is dispatched, by the loop or by a modal loop.
+	Wake() error
+	// Close is called last on the loop thread, it destroys the window.
+	Close()
+}
+
+// UIThread runs a message loop on a locked OS thread, the functions given to
+// Invoke and Post run there, one at a time and in order:
+//
+//	t := ui.NewUIThread(nil)
+//	if err := t.Start(ctx); err != nil {
+//	}
+//	err := t.Invoke(func() error {
+//		hwnd, err = winapi.CreateWindowEx(...)
+//		return err
+//	})
+//
+// The loop ends with Stop, when ctx is done or on PostQuitMessage.
+type UIThread struct {
+	// ThreadMessage, when set, is called on the thread with the messages
+	// posted to the thread rather than to a window, before they are
+	// dispatched: WM_HOTKEY of RegisterHotKey(0, ...) for instance.
+	ThreadMessage func(msg *winapi.Msg)
+
+	pump Pump
+
+	mu       sync.Mutex
+	queue    []func()
+	threadID uint32
+	done     chan struct{}
+	err      error
+}
+
+// NewUIThread returns a thread pumping its Windows message queue when pump
+// is nil.
+func NewUIThread(pump Pump) *UIThread {
+	if pump == nil {
+		pump = newPump()
+	}
+	return &UIThread{pump: pump}
+}
+
+// Start returns once the loop is ready to run functions.
+func (t *UIThread) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		select {
+		case <-t.done:
+		default:
+			return ErrRunning
+		}
+	}
+	t.err = nil
+	t.queue = nil
+	done := make(chan struct{})
+	t.done = done
+	ready := make(chan error, 1)
+	go t.run(done, ready)
+	if err := <-ready; err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.Stop()
+		case <-done:
+		}
+	}()
+	return nil
+}
+
+// Stop posts WM_QUIT to the loop and waits for it to end, unless called on the
+// thread. The functions still queued are dropped, their Invoke returns
+// ErrStopped.
+func (t *UIThread) Stop() error {
+	t.mu.Lock()
+	done, threadID := t.done, t.threadID
+	t.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+	if err := t.pump.Post(threadID, winapi.WM_QUIT, 0, 0); err != nil {
+		return errors.Wrap(err, "post quit message failed")
+	}
+	if t.OnThread() {
+		return nil // the loop ends after the current message
+	}
+	<-done
+	return nil
+}
+
+// Done is closed when the loop ends.
+func (t *UIThread) Done() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.done
+}
+
+// Err returns the error that ended the loop, nil after WM_QUIT.
+func (t *UIThread) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// OnThread reports whether the caller runs on the loop thread, from a
+// window procedure or a queued function.
+func (t *UIThread) OnThread() bool {
+	t.mu.Lock()
+	threadID, done := t.threadID, t.done
+	t.mu.Unlock()
+	if done == nil {
+		return false
+	}
+	select {
+	case <-done:
+		return false
+	default:
+	}
+	return t.pump.ThreadID() == threadID
+}
+
+// Post queues fn and returns without waiting for it.
+func (t *UIThread) Post(fn func()) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done == nil {
+		return ErrStopped
+	}
+	select {
+	case <-t.done:
+		return ErrStopped
+	default:
+	}
+	t.queue = append(t.queue, fn)
+	if len(t.queue) > 1 {
+		return nil // the loop was woken up already
+	}
+	if err := t.pump.Wake(); err != nil {
+		t.queue = t.queue[:0]
+		return errors.Wrap(err, "post invoke message failed")
+	}
+	return nil
+}
+
+// Invoke runs fn on the thread and returns its error. Called on the thread,
+// it runs fn right away instead of deadlocking.
+func (t *UIThread) Invoke(fn func() error) error {
+	if t.OnThread() {
+		return fn()
+	}
+	result := make(chan error, 1)
+	if err := t.Post(func() { result <- fn() }); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	done := t.done
+	t.mu.Unlock()
+	select {
+	case err := <-result:
+		return err
+	case <-done:
+		select {
+		case err := <-result: // ran just before the end
+			return err
+		default:
+			return ErrStopped
+		}
+	}
+}
+
+func (t *UIThread) run(done chan struct{}, ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(done)
+
+	threadID, err := t.pump.Init(t.runQueue)
+	if err != nil {
+		ready <- errors.Wrap(err, "init message queue failed")
+		return
+	}
+	defer t.pump.Close()
+	t.threadID = threadID // Start holds mu until ready
+	ready <- nil
+
+	var msg winapi.Msg
+	for {
+		ok, err := t.pump.Get(&msg)
+		if err != nil {
+			t.mu.Lock()
+			t.err = errors.Wrap(err, "get message failed")
+			t.mu.Unlock()
+			return
+		}
+		if !ok {
+			return
+		}
+		if msg.Hwnd == 0 && t.ThreadMessage != nil {
+			t.ThreadMessage(&msg)
+		}
+		t.pump.Dispatch(&msg)
+	}
+}
+
+// runQueue runs the functions queued so far, the ones they queue wait for
+// the next wake up so that window messages are not starved. A queued function
+// that enters a modal loop gets it called again from there.
+func (t *UIThread) runQueue() {
+	t.mu.Lock()
+	queue := t.queue
+	t.queue = nil
+	t.mu.Unlock()
+	for _, fn := range queue {
+		fn()
+	}
+}
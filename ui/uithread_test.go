@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FxStar/winapi"
+)
+
+func startThread(t *testing.T, size int) (*UIThread, *FakePump) {
+	t.Helper()
+	p := NewFakePump(size)
+	ut := NewUIThread(p)
+	if err := ut.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ut.Stop() })
+	return ut, p
+}
+
+// invokeWithin fails the test when Invoke doesn't return in time, rather than
+// hanging.
+func invokeWithin(t *testing.T, ut *UIThread, fn func() error) error {
+	t.Helper()
+	result := make(chan error, 1)
+	go func() { result <- ut.Invoke(fn) }()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Invoke() did not return")
+		return nil
+	}
+}
+
+func TestUIThreadOrder(t *testing.T) {
+	ut, _ := startThread(t, 16)
+	var got []int
+	for i := 0; i < 100; i++ {
+		i := i
+		if err := ut.Post(func() { got = append(got, i) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := invokeWithin(t, ut, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 100 {
+		t.Fatalf("ran %d functions", len(got))
+	}
+	for i, n := range got {
+		if n != i {
+			t.Fatalf("function %d ran at %d", n, i)
+		}
+	}
+}
+
+func TestUIThreadInvoke(t *testing.T) {
+	ut, _ := startThread(t, 16)
+	if ut.OnThread() {
+		t.Error("OnThread() off the thread")
+	}
+	errTest := errors.New("test")
+	err := invokeWithin(t, ut, func() error {
+		if !ut.OnThread() {
+			t.Error("OnThread() on the thread")
+		}
+		// on the thread, Invoke runs fn right away
+		ran := false
+		ut.Invoke(func() error { ran = true; return nil })
+		if !ran {
+			t.Error("nested Invoke() did not run")
+		}
+		return errTest
+	})
+	if err != errTest {
+		t.Errorf("Invoke() = %v, want %v", err, errTest)
+	}
+}
+
+// TestUIThreadModal checks that functions run while a queued function is in a
+// modal loop, which drops the messages posted to the thread: the wake ups go
+// to a window.
+func TestUIThreadModal(t *testing.T) {
+	ut, p := startThread(t, 16)
+	var threadMsgs []uint32
+	ut.ThreadMessage = func(msg *winapi.Msg) { threadMsgs = append(threadMsgs, msg.Message) }
+
+	done := make(chan struct{})
+	entered := make(chan struct{})
+	if err := ut.Post(func() {
+		close(entered)
+		p.RunModal(done)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-entered
+	if err := p.Post(ut.threadID, winapi.WM_HOTKEY, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := invokeWithin(t, ut, func() error { return nil }); err != nil {
+			t.Fatalf("Invoke() %d in the modal loop: %v", i, err)
+		}
+	}
+	close(done)
+	if err := invokeWithin(t, ut, func() error { return nil }); err != nil {
+		t.Fatalf("Invoke() after the modal loop: %v", err)
+	}
+	if d := p.Dropped(); len(d) != 1 || d[0].Message != winapi.WM_HOTKEY {
+		t.Errorf("dropped %+v, want the WM_HOTKEY", d)
+	}
+	if len(threadMsgs) != 0 {
+		t.Errorf("ThreadMessage got %v", threadMsgs)
+	}
+}
+
+func TestUIThreadModalQuit(t *testing.T) {
+	ut, p := startThread(t, 16)
+	entered := make(chan struct{})
+	ut.Post(func() {
+		close(entered)
+		p.RunModal(nil)
+	})
+	<-entered
+	ut.Stop() // WM_QUIT ends the modal loop, then the loop
+	select {
+	case <-ut.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the loop did not end")
+	}
+	if err := ut.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+// TestUIThreadWakeFailure checks that a wake up that can't be posted fails
+// its Post only, the next one wakes the loop.
+func TestUIThreadWakeFailure(t *testing.T) {
+	ut, p := startThread(t, 2)
+	block := make(chan struct{})
+	if err := ut.Post(func() { <-block }); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond) // let the loop take the wake up
+	p.Send(winapi.Msg{Hwnd: 1, Message: winapi.WM_USER})
+	p.Send(winapi.Msg{Hwnd: 1, Message: winapi.WM_USER})
+	if err := ut.Post(func() { t.Error("dropped function ran") }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Post() on a full queue = %v", err)
+	}
+	close(block)
+	for deadline := time.Now().Add(5 * time.Second); len(p.Dispatched()) < 2; {
+		if time.Now().After(deadline) {
+			t.Fatal("the loop did not dispatch the queued messages")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := invokeWithin(t, ut, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUIThreadThreadMessage(t *testing.T) {
+	ut, p := startThread(t, 16)
+	got := make(chan uint32, 1)
+	ut.ThreadMessage = func(msg *winapi.Msg) { got <- msg.Message }
+	if err := p.Post(ut.threadID, winapi.WM_HOTKEY, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-got; msg != winapi.WM_HOTKEY {
+		t.Errorf("ThreadMessage got %#x", msg)
+	}
+	if err := p.Post(ut.threadID+1, winapi.WM_HOTKEY, 1, 0); err == nil {
+		t.Error("Post() to another thread succeeded")
+	}
+}
+
+func TestUIThreadStop(t *testing.T) {
+	ut, _ := startThread(t, 16)
+	if err := ut.Start(context.Background()); err != ErrRunning {
+		t.Errorf("Start() while running = %v", err)
+	}
+	if err := ut.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ut.Post(func() {}); err != ErrStopped {
+		t.Errorf("Post() after Stop = %v", err)
+	}
+	if err := ut.Invoke(func() error { return nil }); err != ErrStopped {
+		t.Errorf("Invoke() after Stop = %v", err)
+	}
+
+	// restart
+	if err := ut.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := invokeWithin(t, ut, func() error { return nil }); err != nil {
+		t.Errorf("Invoke() after a restart = %v", err)
+	}
+
+	// Stop from the thread drops the functions queued after it
+	err := invokeWithin(t, ut, func() error {
+		err := ut.Stop()
+		ut.Post(func() { t.Error("queued function ran after Stop") })
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ut.Done()
+}
+
+func TestUIThreadContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ut := NewUIThread(NewFakePump(16))
+	if err := ut.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case <-ut.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the loop did not end with its context")
+	}
+}
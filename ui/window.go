@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"sync"
+
+	"github.com/FxStar/winapi"
+	"github.com/pkg/errors"
+)
+
+const defaultClassName = "FxStarWinapiWindow"
+
+// The handler of a Window implements the interfaces of the messages it wants,
+// the others get the default processing.
+type (
+	// CreateHandler runs on WM_CREATE, an error fails NewWindow.
+	CreateHandler interface {
+		OnCreate(w *Window) error
+	}
+	// PaintHandler paints between BeginPaint and EndPaint.
+	PaintHandler interface {
+		OnPaint(w *Window, hdc winapi.HDC, ps *winapi.PAINTSTRUCT)
+	}
+	// SizeHandler gets the SIZE_ type and the new client size.
+	SizeHandler interface {
+		OnSize(w *Window, sizeType uint32, width, height int32)
+	}
+	// CloseHandler returns false to keep the window open.
+	CloseHandler interface {
+		OnClose(w *Window) bool
+	}
+	// DestroyHandler runs on WM_DESTROY, the HWND is still valid.
+	DestroyHandler interface {
+		OnDestroy(w *Window)
+	}
+	// MessageHandler sees every message first, handled false passes it on.
+	MessageHandler interface {
+		OnMessage(w *Window, msg uint32, wParam, lParam uintptr) (result uintptr, handled bool)
+	}
+)
+
+// WindowOptions describes the window NewWindow creates. The zero value is a
+// hidden overlapped window of the default size.
+type WindowOptions struct {
+	ClassName  string // the package class when empty
+	ClassStyle uint32 // CS_HREDRAW|CS_VREDRAW when 0, on registration
+	Icon       winapi.HICON
+
+	Title         string
+	Style         uint32 // WS_OVERLAPPEDWINDOW when 0
+	ExStyle       uint32
+	X, Y          int32 // CW_USEDEFAULT when both 0
+	Width, Height int32 // CW_USEDEFAULT when both 0
+	Parent        winapi.HWND
+	Menu          *Menu // built as the menu bar, its WM_COMMAND routed
+
+	Handler interface{} // implements some of the handler interfaces
+	// QuitOnDestroy posts WM_QUIT when the window is destroyed, ending the
+	// loop of its UIThread.
+	QuitOnDestroy bool
+}
+
+// Window is a window created by NewWindow or subclassed by SubclassWindow,
+// whose messages go to a Go handler. All the windows share a single window
+// procedure and the HWND maps back to the Window, so that the number of
+// syscall.NewCallback stays the same however many windows there are.
+type Window struct {
+	thread  *UIThread
+	hwnd    winapi.HWND
+	handler interface{}
+	menu    *Menu
+	quit    bool
+	prev    uintptr // window procedure replaced by SubclassWindow
+	err     error   // of OnCreate
+}
+
+var (
+	windowsMu sync.Mutex
+	windows   = map[winapi.HWND]*Window{}
+	// pending is the window being created on each thread, until its first
+	// message tells its HWND
+	pending = map[uint32]*Window{}
+)
+
+// WindowFromHWND returns the Window of hwnd, nil for windows of others.
+func WindowFromHWND(hwnd winapi.HWND) *Window {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	return windows[hwnd]
+}
+
+// NewWindow creates a window on t, the class is registered the first time its
+// name is used.
+func NewWindow(t *UIThread, opts WindowOptions) (*Window, error) {
+	w := &Window{thread: t, handler: opts.Handler, menu: opts.Menu, quit: opts.QuitOnDestroy}
+	err := t.Invoke(func() error {
+		return createWindow(w, &opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SubclassWindow routes the messages of an existing window of t's thread to
+// handler, the window procedure it had gets the messages left.
+func SubclassWindow(t *UIThread, hwnd winapi.HWND, handler interface{}) (*Window, error) {
+	w := &Window{thread: t, hwnd: hwnd, handler: handler}
+	err := t.Invoke(func() error {
+		if WindowFromHWND(hwnd) != nil {
+			return errors.New("window already handled")
+		}
+		return subclassWindow(w)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Window) HWND() winapi.HWND {
+	return w.hwnd
+}
+
+func (w *Window) Thread() *UIThread {
+	return w.thread
+}
+
+// Destroy destroys the window, after OnDestroy.
+func (w *Window) Destroy() error {
+	return w.thread.Invoke(func() error {
+		if WindowFromHWND(w.hwnd) != w {
+			return nil
+		}
+		return destroyWindow(w.hwnd)
+	})
+}
+
+// Unsubclass gives the window its window procedure back.
+func (w *Window) Unsubclass() error {
+	return w.thread.Invoke(func() error {
+		if w.prev == 0 || WindowFromHWND(w.hwnd) != w {
+			return nil
+		}
+		return unsubclassWindow(w)
+	})
+}
+
+func (w *Window) bind(hwnd winapi.HWND) {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	w.hwnd = hwnd
+	windows[hwnd] = w
+}
+
+func (w *Window) unbind() {
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	if windows[w.hwnd] == w {
+		delete(windows, w.hwnd)
+	}
+}
+
+// dispatch calls the handler for msg, false for the default processing.
+func (w *Window) dispatch(msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	if h, ok := w.handler.(MessageHandler); ok {
+		if r, handled := h.OnMessage(w, msg, wParam, lParam); handled {
+			return r, true
+		}
+	}
+	switch msg {
+	case winapi.WM_CREATE:
+		if h, ok := w.handler.(CreateHandler); ok {
+			if w.err = h.OnCreate(w); w.err != nil {
+				return ^uintptr(0), true // -1 fails CreateWindowEx
+			}
+			return 0, true
+		}
+	case winapi.WM_PAINT:
+		if h, ok := w.handler.(PaintHandler); ok {
+			paint(w, h)
+			return 0, true
+		}
+	case winapi.WM_SIZE:
+		if h, ok := w.handler.(SizeHandler); ok {
+			h.OnSize(w, uint32(wParam), int32(lParam&0xFFFF), int32(lParam>>16&0xFFFF))
+			return 0, true
+		}
+	case winapi.WM_COMMAND:
+		if w.menu != nil && w.menu.Command(winapi.WPARAM(wParam), winapi.LPARAM(lParam)) {
+			return 0, true
+		}
+	case winapi.WM_CLOSE:
+		if h, ok := w.handler.(CloseHandler); ok && !h.OnClose(w) {
+			return 0, true
+		}
+	case winapi.WM_DESTROY:
+		if h, ok := w.handler.(DestroyHandler); ok {
+			h.OnDestroy(w)
+		}
+		if w.quit {
+			postQuit()
+		}
+	}
+	return 0, false
+}
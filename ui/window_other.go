@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import "github.com/FxStar/winapi"
+
+func createWindow(w *Window, opts *WindowOptions) error {
+	return winapi.ErrNotSupported
+}
+
+func subclassWindow(w *Window) error {
+	return winapi.ErrNotSupported
+}
+
+func unsubclassWindow(w *Window) error {
+	return winapi.ErrNotSupported
+}
+
+func destroyWindow(hwnd winapi.HWND) error {
+	return winapi.ErrNotSupported
+}
+
+func paint(w *Window, h PaintHandler) {}
+
+func postQuit() {}
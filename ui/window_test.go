@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FxStar/winapi"
+)
+
+// testHandler implements the handler interfaces and records their calls.
+type testHandler struct {
+	createErr error
+	close     bool
+	handle    uint32 // the message OnMessage handles
+
+	calls         []string
+	width, height int32
+	sizeType      uint32
+}
+
+func (h *testHandler) OnCreate(w *Window) error {
+	h.calls = append(h.calls, "create")
+	return h.createErr
+}
+
+func (h *testHandler) OnSize(w *Window, sizeType uint32, width, height int32) {
+	h.calls = append(h.calls, "size")
+	h.sizeType, h.width, h.height = sizeType, width, height
+}
+
+func (h *testHandler) OnClose(w *Window) bool {
+	h.calls = append(h.calls, "close")
+	return h.close
+}
+
+func (h *testHandler) OnMessage(w *Window, msg uint32, wParam, lParam uintptr) (uintptr, bool) {
+	h.calls = append(h.calls, winapi.MessageName(msg))
+	if msg == h.handle {
+		return 42, true
+	}
+	return 0, false
+}
+
+func TestWindowCreate(t *testing.T) {
+	errCreate := errors.New("no resources")
+	tests := []struct {
+		err     error
+		result  uintptr
+		handled bool
+	}{
+		{nil, 0, true},
+		{errCreate, ^uintptr(0), true}, // -1 fails CreateWindowEx
+	}
+	for _, tt := range tests {
+		w := &Window{handler: &testHandler{createErr: tt.err}}
+		r, handled := w.dispatch(winapi.WM_CREATE, 0, 0)
+		if r != tt.result || handled != tt.handled || w.err != tt.err {
+			t.Errorf("WM_CREATE with OnCreate() = %v: %#x, %v, err %v, want %#x, %v", tt.err, r, handled, w.err, tt.result, tt.handled)
+		}
+	}
+	if _, handled := (&Window{}).dispatch(winapi.WM_CREATE, 0, 0); handled {
+		t.Error("WM_CREATE without a handler handled")
+	}
+}
+
+func TestWindowClose(t *testing.T) {
+	for _, closes := range []bool{false, true} {
+		w := &Window{handler: &testHandler{close: closes}}
+		// handled keeps the window open, DefWindowProc destroys it
+		if _, handled := w.dispatch(winapi.WM_CLOSE, 0, 0); handled == closes {
+			t.Errorf("WM_CLOSE with OnClose() = %v: handled %v", closes, handled)
+		}
+	}
+}
+
+func TestWindowSize(t *testing.T) {
+	tests := []struct {
+		sizeType      uintptr
+		lParam        uintptr
+		width, height int32
+	}{
+		{winapi.SIZE_RESTORED, 600<<16 | 800, 800, 600},
+		{winapi.SIZE_MAXIMIZED, 0xFFFF<<16 | 0xFFFF, 0xFFFF, 0xFFFF},
+		{winapi.SIZE_MINIMIZED, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		h := &testHandler{}
+		w := &Window{handler: h}
+		r, handled := w.dispatch(winapi.WM_SIZE, tt.sizeType, tt.lParam)
+		if r != 0 || !handled || h.sizeType != uint32(tt.sizeType) || h.width != tt.width || h.height != tt.height {
+			t.Errorf("WM_SIZE(%d, %#x) = %d, %v, OnSize(%d, %d, %d), want OnSize(%d, %d, %d)",
+				tt.sizeType, tt.lParam, r, handled, h.sizeType, h.width, h.height, tt.sizeType, tt.width, tt.height)
+		}
+	}
+}
+
+func TestWindowCommand(t *testing.T) {
+	clicks := 0
+	it := &MenuItem{Text: "item", OnClick: func(*MenuItem) { clicks++ }}
+	m := NewMenu(it)
+	assign(t, m)
+	w := &Window{menu: m}
+	if _, handled := w.dispatch(winapi.WM_COMMAND, uintptr(it.ID()), 0); !handled || clicks != 1 {
+		t.Errorf("WM_COMMAND of the menu item: handled %v, %d clicks", handled, clicks)
+	}
+	if _, handled := w.dispatch(winapi.WM_COMMAND, uintptr(it.ID())+1, 0); handled {
+		t.Error("WM_COMMAND of another id handled")
+	}
+	if _, handled := (&Window{}).dispatch(winapi.WM_COMMAND, uintptr(it.ID()), 0); handled || clicks != 1 {
+		t.Errorf("WM_COMMAND without a menu: handled %v, %d clicks", handled, clicks)
+	}
+}
+
+func TestWindowMessageHandler(t *testing.T) {
+	h := &testHandler{handle: winapi.WM_CLOSE}
+	w := &Window{handler: h}
+
+	// handled, the other handlers are not called
+	if r, handled := w.dispatch(winapi.WM_CLOSE, 0, 0); r != 42 || !handled {
+		t.Errorf("WM_CLOSE handled by OnMessage = %d, %v", r, handled)
+	}
+	// not handled, on to OnSize
+	if _, handled := w.dispatch(winapi.WM_SIZE, 0, 2<<16|1); !handled || h.width != 1 || h.height != 2 {
+		t.Errorf("WM_SIZE passed on by OnMessage: handled %v, size %dx%d", handled, h.width, h.height)
+	}
+	// not handled by anyone
+	if _, handled := w.dispatch(winapi.WM_USER, 0, 0); handled {
+		t.Error("WM_USER passed on by OnMessage handled")
+	}
+	want := []string{"WM_CLOSE", "WM_SIZE", "size", "WM_USER"}
+	if len(h.calls) != len(want) {
+		t.Fatalf("calls %q, want %q", h.calls, want)
+	}
+	for i := range want {
+		if h.calls[i] != want[i] {
+			t.Errorf("calls %q, want %q", h.calls, want)
+			break
+		}
+	}
+}
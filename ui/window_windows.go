@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/FxStar/winapi"
+	"github.com/FxStar/winapi/wstr"
+	"github.com/pkg/errors"
+)
+
+var (
+	wndProcOnce sync.Once
+	wndProcPtr  uintptr
+
+	classesMu sync.Mutex
+	classes   = map[string]bool{}
+)
+
+// windowProc returns the window procedure of every Window, the only callback
+// they need.
+func windowProc() uintptr {
+	wndProcOnce.Do(func() {
+		wndProcPtr = syscall.NewCallback(wndProc)
+	})
+	return wndProcPtr
+}
+
+func wndProc(hwnd winapi.HWND, msg winapi.UINT, wParam winapi.WPARAM, lParam winapi.LPARAM) uintptr {
+	w := WindowFromHWND(hwnd)
+	if w == nil {
+		w = claimPending(hwnd)
+	}
+	if w == nil {
+		return winapi.DefWindowProcW(hwnd, msg, wParam, lParam)
+	}
+	r, handled := w.dispatch(uint32(msg), uintptr(wParam), uintptr(lParam))
+	if !handled {
+		if w.prev != 0 {
+			r = winapi.CallWindowProcW(w.prev, hwnd, uint32(msg), uintptr(wParam), uintptr(lParam))
+		} else {
+			r = winapi.DefWindowProcW(hwnd, msg, wParam, lParam)
+		}
+	}
+	if msg == winapi.WM_NCDESTROY {
+		w.unbind()
+	}
+	return r
+}
+
+// claimPending binds hwnd to the window being created on the thread, its
+// first message comes before CreateWindowEx returns.
+func claimPending(hwnd winapi.HWND) *Window {
+	tid := currentThreadID()
+	windowsMu.Lock()
+	defer windowsMu.Unlock()
+	w := pending[tid]
+	if w == nil {
+		return nil
+	}
+	delete(pending, tid)
+	w.hwnd = hwnd
+	windows[hwnd] = w
+	return w
+}
+
+func currentThreadID() uint32 {
+	tid, _ := winapi.GetCurrentThreadId()
+	return uint32(tid)
+}
+
+func registerClass(name string, opts *WindowOptions) error {
+	classesMu.Lock()
+	defer classesMu.Unlock()
+	if classes[name] {
+		return nil
+	}
+	inst, err := winapi.GetModuleHandle("")
+	if err != nil {
+		return err
+	}
+	className, err := wstr.PtrFromString(name)
+	if err != nil {
+		return errors.Wrapf(err, "bad window class name %q", name)
+	}
+	cursor, _ := winapi.LoadCursor(0, winapi.IDC_ARROW)
+	wc := winapi.Wndclassex{
+		Style:      opts.ClassStyle,
+		WndProc:    windowProc(),
+		Instance:   inst,
+		Icon:       opts.Icon,
+		Cursor:     cursor,
+		Background: winapi.HANDLE(winapi.COLOR_WINDOW + 1),
+		ClassName:  className,
+	}
+	if wc.Style == 0 {
+		wc.Style = winapi.CS_HREDRAW | winapi.CS_VREDRAW
+	}
+	wc.Size = uint32(unsafe.Sizeof(wc))
+	if _, err := winapi.RegisterClassExW(&wc); err != nil {
+		return errors.Wrapf(err, "register window class %s failed", name)
+	}
+	classes[name] = true
+	return nil
+}
+
+func createWindow(w *Window, opts *WindowOptions) error {
+	className := opts.ClassName
+	if className == "" {
+		className = defaultClassName
+	}
+	if err := registerClass(className, opts); err != nil {
+		return err
+	}
+	inst, err := winapi.GetModuleHandle("")
+	if err != nil {
+		return err
+	}
+	style := opts.Style
+	if style == 0 {
+		style = winapi.WS_OVERLAPPEDWINDOW
+	}
+	x, y, width, height := opts.X, opts.Y, opts.Width, opts.Height
+	if x == 0 && y == 0 {
+		x, y = winapi.CW_USEDEFAULT, winapi.CW_USEDEFAULT
+	}
+	if width == 0 && height == 0 {
+		width, height = winapi.CW_USEDEFAULT, winapi.CW_USEDEFAULT
+	}
+	var bar winapi.HMENU
+	if opts.Menu != nil {
+		if bar, err = opts.Menu.BuildBar(); err != nil {
+			return errors.Wrap(err, "build menu bar failed")
+		}
+	}
+
+	tid := currentThreadID()
+	windowsMu.Lock()
+	pending[tid] = w
+	windowsMu.Unlock()
+	hwnd, err := winapi.CreateWindowExW(opts.ExStyle, className, opts.Title, style, x, y, width, height, opts.Parent, bar, inst, 0)
+	windowsMu.Lock()
+	if pending[tid] == w {
+		delete(pending, tid)
+	}
+	windowsMu.Unlock()
+	if err != nil {
+		if bar != 0 {
+			opts.Menu.Destroy()
+		}
+		if w.err != nil {
+			return w.err
+		}
+		return errors.Wrap(err, "create window failed")
+	}
+	if w.hwnd != hwnd { // no message during creation, unlikely
+		w.bind(hwnd)
+	}
+	return nil
+}
+
+func subclassWindow(w *Window) error {
+	if tid, _ := winapi.GetWindowThreadProcessId(w.hwnd); uint32(tid) != currentThreadID() {
+		return errors.New("window of another thread")
+	}
+	prev, err := winapi.SetWindowLongPtrW(w.hwnd, winapi.GWLP_WNDPROC, windowProc())
+	if prev == 0 {
+		return errors.Wrap(err, "subclass window failed")
+	}
+	w.prev = prev
+	w.bind(w.hwnd)
+	return nil
+}
+
+func unsubclassWindow(w *Window) error {
+	if cur, _ := winapi.GetWindowLongPtr(w.hwnd, winapi.GWLP_WNDPROC); cur != windowProc() {
+		return errors.New("window subclassed again since")
+	}
+	if prev, err := winapi.SetWindowLongPtrW(w.hwnd, winapi.GWLP_WNDPROC, w.prev); prev == 0 {
+		return errors.Wrap(err, "unsubclass window failed")
+	}
+	w.unbind()
+	w.prev = 0
+	return nil
+}
+
+func destroyWindow(hwnd winapi.HWND) error {
+	return winapi.DestroyWindow(hwnd)
+}
+
+func paint(w *Window, h PaintHandler) {
+	var ps winapi.PAINTSTRUCT
+	hdc := winapi.BeginPaint(w.hwnd, &ps)
+	defer winapi.EndPaint(w.hwnd, &ps)
+	h.OnPaint(w, hdc, &ps)
+}
+
+func postQuit() {
+	winapi.PostQuitMessage(0)
+}
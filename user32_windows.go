@@ -1,7 +1,6 @@
 package winapi
 
 import (
-	"errors"
 	"log"
 	"syscall"
 	"unicode/utf16"
@@ -11,23 +10,16 @@ import (
 var (
 	moduser32 = syscall.NewLazyDLL("user32.dll")
 
-	procSetCursor        = moduser32.NewProc("SetCursor")
 	procGetKeyboardState = moduser32.NewProc("GetKeyboardState")
 	procSetFocus         = moduser32.NewProc("SetFocus")
 
 	procBeginPaint          = moduser32.NewProc("BeginPaint")
 	procCreateDialogParamW  = moduser32.NewProc("CreateDialogParamW")
-	procCreateWindowExW     = moduser32.NewProc("CreateWindowExW")
-	procCreateWindowExA     = moduser32.NewProc("CreateWindowExA")
-	procDefWindowProcW      = moduser32.NewProc("DefWindowProcW")
-	procDestroyWindow       = moduser32.NewProc("DestroyWindow")
 	procDialogBoxParamW     = moduser32.NewProc("DialogBoxParamW")
-	procDispatchMessageW    = moduser32.NewProc("DispatchMessageW")
 	procEndDialog           = moduser32.NewProc("EndDialog")
 	procEndPaint            = moduser32.NewProc("EndPaint")
 	procGetDC               = moduser32.NewProc("GetDC")
 	procGetDlgItem          = moduser32.NewProc("GetDlgItem")
-	procGetMessageW         = moduser32.NewProc("GetMessageW")
 	procGetWindowLongW      = moduser32.NewProc("GetWindowLongW")
 	procGetWindowLongPtrW   = moduser32.NewProc("GetWindowLongPtrW")
 	procLoadCursorW         = moduser32.NewProc("LoadCursorW")
@@ -36,19 +28,11 @@ var (
 	procLoadStringW         = moduser32.NewProc("LoadStringW")
 	procMessageBoxW         = moduser32.NewProc("MessageBoxW")
 	procUnregisterClassW    = moduser32.NewProc("UnregisterClassW")
-	procPostMessageW        = moduser32.NewProc("PostMessageW")
-	procPostQuitMessage     = moduser32.NewProc("PostQuitMessage")
-	procRegisterClassExW    = moduser32.NewProc("RegisterClassExW")
-	procRegisterClassW      = moduser32.NewProc("RegisterClassW")
 	procReleaseDC           = moduser32.NewProc("ReleaseDC")
-	procSendMessageW        = moduser32.NewProc("SendMessageW")
 	procSendDlgItemMessageW = moduser32.NewProc("SendDlgItemMessageW")
 	procSetMenu             = moduser32.NewProc("SetMenu")
 	procSetWindowLongW      = moduser32.NewProc("SetWindowLongW")
 	procSetWindowLongPtrW   = moduser32.NewProc("SetWindowLongPtrW")
-	procShowWindow          = moduser32.NewProc("ShowWindow")
-	procTranslateMessage    = moduser32.NewProc("TranslateMessage")
-	procUpdateWindow        = moduser32.NewProc("UpdateWindow")
 	procRedrawWindow        = moduser32.NewProc("RedrawWindow")
 	procInvalidateRect      = moduser32.NewProc("InvalidateRect")
 
@@ -91,8 +75,7 @@ var (
 
 	procGetDesktopWindow = moduser32.NewProc("GetDesktopWindow")
 
-	procCreatePopupMenu = moduser32.NewProc("CreatePopupMenu")
-	procAppendMenu      = moduser32.NewProc("AppendMenuW")
+	procAppendMenu = moduser32.NewProc("AppendMenuW")
 
 	procLoadImage = moduser32.NewProc("LoadImageW")
 
@@ -108,20 +91,16 @@ var (
 
 	procGetWindowThreadProcessId = moduser32.NewProc("GetWindowThreadProcessId")
 
-	procRegisterHotKey = moduser32.NewProc("RegisterHotKey")
+	procRegisterHotKey   = moduser32.NewProc("RegisterHotKey")
+	procUnregisterHotKey = moduser32.NewProc("UnregisterHotKey")
 
 	procToAscii     = moduser32.NewProc("ToAscii")
 	procGetKeyState = moduser32.NewProc("GetKeyState")
-)
+	procSendInput   = moduser32.NewProc("SendInput")
 
-// Virtual Keys, Standard Set
-// https://docs.microsoft.com/zh-cn/windows/win32/inputdev/virtual-key-codes
-const (
-	VK_SHIFT   = 0x10
-	VK_CONTROL = 0x11
-	VK_MENU    = 0x12
-	VK_PAUSE   = 0x13
-	VK_CAPITAL = 0x14
+	procSetWindowsHookExW   = moduser32.NewProc("SetWindowsHookExW")
+	procCallNextHookEx      = moduser32.NewProc("CallNextHookEx")
+	procUnhookWindowsHookEx = moduser32.NewProc("UnhookWindowsHookEx")
 )
 
 //__in_opt HWND hWnd,
@@ -141,6 +120,18 @@ func RegisterHotKey(hwnd HWND, id int, fsModifiers UINT, vk UINT) (err error) {
 	return
 }
 
+func UnregisterHotKey(hwnd HWND, id int) (err error) {
+	r, _, e1 := procUnregisterHotKey.Call(uintptr(hwnd), uintptr(id))
+	if r == 0 {
+		if e1 == nil {
+			err = syscall.EINVAL
+		} else {
+			err = error(e1)
+		}
+	}
+	return
+}
+
 func GetWindowThreadProcessId(hwnd HWND) (id, pid uintptr) {
 	id, _, _ = procGetWindowThreadProcessId.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&pid)))
 	return
@@ -228,19 +219,6 @@ func AppendMenu(h HWND, wFlags, wIDNewItem int, lpNewItem uintptr) (mh HWND, err
 	return
 }
 
-func CreatePopupMenu() (HWND, error) {
-	r, err := Syscall(procCreatePopupMenu.Addr())
-	if r == 0 {
-		if err != nil {
-			return 0, err
-		} else {
-			return 0, errors.New("CreatePopupMenu error")
-		}
-
-	}
-	return HWND(r), err
-}
-
 func GetDesktopWindow() (HWND, error) {
 	r, err := Syscall(procGetDesktopWindow.Addr())
 	return HWND(r), err
@@ -386,8 +364,6 @@ func LoadBitmapW(instance HINSTANCE, name uintptr) (icon HBITMAP, err error) {
 //  uIDEvent: UINT {定时器标识符}
 //): BOOL;
 
-type TFNTimerProc func(hWnd uintptr, uMsg UINT, idEvent TimerEventID, Time DWORD)
-
 func SetTimer(hWnd uintptr, nIDEvent TimerEventID, uElapse UINT, lpTimerFunc TFNTimerProc) UINT {
 	var lpTimerFunc_ uintptr
 	if lpTimerFunc != nil {
@@ -460,129 +436,6 @@ func EndPaint(hwnd HWND, ps *PAINTSTRUCT) bool {
 	return PtrToBool(r0)
 }
 
-func RegisterClassExW(wndclass *Wndclassex) (atom uint16, err error) {
-	r0, _, e1 := syscall.Syscall(procRegisterClassExW.Addr(), 1, uintptr(unsafe.Pointer(wndclass)), 0, 0)
-	atom = uint16(r0)
-	if atom == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func RegisterClassW(wndclass *Wndclass) (atom uint16, err error) {
-	r0, _, e1 := syscall.Syscall(procRegisterClassW.Addr(), 1, uintptr(unsafe.Pointer(wndclass)), 0, 0)
-	atom = uint16(r0)
-	if atom == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func CreateWindowExW(exstyle uint32, classname string, windowname string, style uint32, x int32, y int32, width int32, height int32, wndparent HWND, menu HMENU, instance HINSTANCE, param uintptr) (hwnd HWND, err error) {
-	classnamePtr := uintptr(0)
-	if classname != "" {
-		classnamePtr = uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(classname)))
-	}
-
-	r0, _, e1 := syscall.Syscall12(procCreateWindowExW.Addr(), 12, uintptr(exstyle), classnamePtr, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(windowname))), uintptr(style), uintptr(x), uintptr(y), uintptr(width), uintptr(height), uintptr(wndparent), uintptr(menu), uintptr(instance), uintptr(param))
-	hwnd = HWND(r0)
-	if hwnd == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func CreateWindowExA(exstyle uint32, classname string, windowname string, style uint32, x int32, y int32, width int32, height int32, wndparent HWND, menu HMENU, instance HINSTANCE, param uintptr) (hwnd HWND, err error) {
-	r0, _, e1 := syscall.Syscall12(procCreateWindowExA.Addr(), 12, uintptr(exstyle), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(classname))), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(windowname))), uintptr(style), uintptr(x), uintptr(y), uintptr(width), uintptr(height), uintptr(wndparent), uintptr(menu), uintptr(instance), uintptr(param))
-	hwnd = HWND(r0)
-	if hwnd == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func DefWindowProcW(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (lresult uintptr) {
-	r0, _, _ := syscall.Syscall6(procDefWindowProcW.Addr(), 4, uintptr(hwnd), uintptr(msg), uintptr(wparam), uintptr(lparam), 0, 0)
-	lresult = uintptr(r0)
-	return
-}
-
-func DestroyWindow(hwnd HWND) (err error) {
-	r1, _, e1 := syscall.Syscall(procDestroyWindow.Addr(), 1, uintptr(hwnd), 0, 0)
-	if int(r1) == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func PostQuitMessage(exitcode int32) {
-	syscall.Syscall(procPostQuitMessage.Addr(), 1, uintptr(exitcode), 0, 0)
-	return
-}
-
-func ShowWindow(hwnd HWND, cmdshow int32) (wasvisible bool) {
-	r0, _, _ := syscall.Syscall(procShowWindow.Addr(), 2, uintptr(hwnd), uintptr(cmdshow), 0)
-	wasvisible = bool(r0 != 0)
-	return
-}
-
-func UpdateWindow(hwnd HWND) (err error) {
-	r1, _, e1 := syscall.Syscall(procUpdateWindow.Addr(), 1, uintptr(hwnd), 0, 0)
-	if int(r1) == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func GetMessage(msg *Msg, hwnd HWND, MsgFilterMin uint32, MsgFilterMax uint32) (ret int32, err error) {
-	r0, _, e1 := syscall.Syscall6(procGetMessageW.Addr(), 4, uintptr(unsafe.Pointer(msg)), uintptr(hwnd), uintptr(MsgFilterMin), uintptr(MsgFilterMax), 0, 0)
-	ret = int32(r0)
-	if ret == -1 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func TranslateMessage(msg *Msg) (done bool) {
-	r0, _, _ := syscall.Syscall(procTranslateMessage.Addr(), 1, uintptr(unsafe.Pointer(msg)), 0, 0)
-	done = bool(r0 != 0)
-	return
-}
-
-func DispatchMessageW(msg *Msg) (ret int32) {
-	r0, _, _ := syscall.Syscall(procDispatchMessageW.Addr(), 1, uintptr(unsafe.Pointer(msg)), 0, 0)
-	ret = int32(r0)
-	return
-}
-
 func LoadIconS(instance HINSTANCE, iconname string) (icon HICON, err error) {
 	return LoadIconW(instance, resourceNameToPtr(iconname))
 }
@@ -625,37 +478,6 @@ func LoadCursorW(instance HINSTANCE, cursorname uintptr) (cursor HCURSOR, err er
 	return
 }
 
-func SetCursor(cursor HCURSOR) (precursor HCURSOR, err error) {
-	r0, _, e1 := syscall.Syscall(procSetCursor.Addr(), 1, uintptr(cursor), 0, 0)
-	precursor = HCURSOR(r0)
-	if precursor == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
-func SendMessage(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (lresult uintptr) {
-	r0, _, _ := syscall.Syscall6(procSendMessageW.Addr(), 4, uintptr(hwnd), uintptr(msg), uintptr(wparam), uintptr(lparam), 0, 0)
-	lresult = uintptr(r0)
-	return
-}
-
-func PostMessage(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (err error) {
-	r1, _, e1 := syscall.Syscall6(procPostMessageW.Addr(), 4, uintptr(hwnd), uintptr(msg), uintptr(wparam), uintptr(lparam), 0, 0)
-	if int(r1) == 0 {
-		if e1 != 0 {
-			err = error(e1)
-		} else {
-			err = syscall.EINVAL
-		}
-	}
-	return
-}
-
 func GetKeyboardState() (keyState []byte, err error) {
 	var keys [256]byte
 	r0, _, e1 := syscall.Syscall(procGetKeyboardState.Addr(), 1, uintptr(unsafe.Pointer(&keys)), 0, 0)
@@ -793,3 +615,38 @@ func GetKeyState(uVirtKey uint32) int16 {
 	r1, _, _ := procGetKeyState.Call(uintptr(uVirtKey))
 	return int16(r1)
 }
+
+// SendInput returns the number of events inserted, which is less than len(inputs)
+// when input is blocked by another thread.
+func SendInput(inputs []KeyboardInput) (uint32, error) {
+	if len(inputs) == 0 {
+		return 0, nil
+	}
+	r1, _, err := procSendInput.Call(uintptr(len(inputs)), uintptr(unsafe.Pointer(&inputs[0])), unsafe.Sizeof(inputs[0]))
+	if r1 == 0 {
+		return 0, err
+	}
+	return uint32(r1), nil
+}
+
+// SetWindowsHookEx installs a hook procedure, lpfn is a syscall.NewCallback
+// of a HOOKPROC. Low level hooks are called on the installing thread, which
+// must run a message loop.
+func SetWindowsHookEx(idHook int, lpfn uintptr, hMod HINSTANCE, dwThreadId uint32) (HHOOK, error) {
+	r1, _, err := procSetWindowsHookExW.Call(uintptr(idHook), lpfn, uintptr(hMod), uintptr(dwThreadId))
+	if r1 == 0 {
+		return 0, err
+	}
+	return HHOOK(r1), nil
+}
+
+func (hhk HHOOK) CallNextHookEx(nCode int, wParam WPARAM, lParam LPARAM) LRESULT {
+	r1, _, _ := procCallNextHookEx.Call(uintptr(hhk), uintptr(nCode), uintptr(wParam), uintptr(lParam))
+	return LRESULT(r1)
+}
+
+func (hhk *HHOOK) UnhookWindowsHookEx() bool {
+	r1, _, _ := procUnhookWindowsHookEx.Call(uintptr(*hhk))
+	*hhk = 0
+	return r1 != 0
+}
@@ -1,7 +1,7 @@
 package winapi
 
 import (
-	"unsafe"
+	"github.com/FxStar/winapi/internal/proc"
 )
 
 const (
@@ -250,6 +250,7 @@ const (
 	WM_XBUTTONDOWN            = 523
 	WM_XBUTTONUP              = 524
 	WM_XBUTTONDBLCLK          = 525
+	WM_MOUSEHWHEEL            = 526
 	WM_MOUSELAST              = 525
 	WM_MOUSEHOVER             = 0X2A1
 	WM_MOUSELEAVE             = 0X2A3
@@ -666,6 +667,7 @@ const (
 
 const (
 	HWND_BROADCAST = HWND(0xffff)
+	HWND_MESSAGE   = ^HWND(2) // -3, parent of message-only windows
 )
 
 var (
@@ -710,6 +712,8 @@ func HIWORD(h INT) INT {
 
 func KeyState(key byte) bool { return (key & 0x80) > 0 }
 
+// MakeIntResource returns id as a resource name pointer, it must only be
+// passed to Windows.
 func MakeIntResource(id uint16) *uint16 {
-	return (*uint16)(unsafe.Pointer(uintptr(id)))
+	return (*uint16)(proc.Pointer(uintptr(id)))
 }
@@ -0,0 +1,19 @@
+package winapi
+
+// FormatMessage flags
+const (
+	FORMAT_MESSAGE_ALLOCATE_BUFFER = 0x00000100
+	FORMAT_MESSAGE_IGNORE_INSERTS  = 0x00000200
+	FORMAT_MESSAGE_FROM_STRING     = 0x00000400
+	FORMAT_MESSAGE_FROM_HMODULE    = 0x00000800
+	FORMAT_MESSAGE_FROM_SYSTEM     = 0x00001000
+	FORMAT_MESSAGE_ARGUMENT_ARRAY  = 0x00002000
+	FORMAT_MESSAGE_MAX_WIDTH_MASK  = 0x000000FF
+)
+
+// SetFilePointerEx move methods
+const (
+	FILE_BEGIN   = 0
+	FILE_CURRENT = 1
+	FILE_END     = 2
+)
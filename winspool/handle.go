@@ -0,0 +1,7 @@
+package winspool
+
+// The handle types are portable, the root winapi package aliases HDC.
+type (
+	HANDLE uintptr // printer handle
+	HDC    uintptr
+)
@@ -0,0 +1,54 @@
+package winspool
+
+import (
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/FxStar/winapi/internal/winerr"
+)
+
+var (
+	gdi32    = proc.NewDLL("gdi32.dll")
+	kernel32 = proc.NewDLL("kernel32.dll")
+	ntoskrnl = proc.NewDLL("ntoskrnl.exe")
+	winspool = proc.NewDLL("winspool.drv")
+	user32   = proc.NewDLL("user32.dll")
+
+	abortDocProc                   = gdi32.NewProc("AbortDoc")
+	closePrinterProc               = winspool.NewProc("ClosePrinter")
+	createDCProc                   = gdi32.NewProc("CreateDCW")
+	deleteDCProc                   = gdi32.NewProc("DeleteDC")
+	deviceCapabilitiesProc         = winspool.NewProc("DeviceCapabilitiesW")
+	documentPropertiesProc         = winspool.NewProc("DocumentPropertiesW")
+	endDocProc                     = gdi32.NewProc("EndDoc")
+	endPageProc                    = gdi32.NewProc("EndPage")
+	enumPrintersProc               = winspool.NewProc("EnumPrintersW")
+	getDeviceCapsProc              = gdi32.NewProc("GetDeviceCaps")
+	getJobProc                     = winspool.NewProc("GetJobW")
+	openPrinterProc                = winspool.NewProc("OpenPrinterW")
+	resetDCProc                    = gdi32.NewProc("ResetDCW")
+	rtlGetVersionProc              = ntoskrnl.NewProc("RtlGetVersion")
+	setGraphicsModeProc            = gdi32.NewProc("SetGraphicsMode")
+	setJobProc                     = winspool.NewProc("SetJobW")
+	setWorldTransformProc          = gdi32.NewProc("SetWorldTransform")
+	startDocProc                   = gdi32.NewProc("StartDocW")
+	startPageProc                  = gdi32.NewProc("StartPage")
+	registerDeviceNotificationProc = user32.NewProc("RegisterDeviceNotificationW")
+
+	unregisterDeviceNotificationProc = user32.NewProc("UnregisterDeviceNotification")
+
+	startDocPrinterProc    = winspool.NewProc("StartDocPrinterW")
+	startPagePrinterProc   = winspool.NewProc("StartPagePrinter")
+	writePrinterProc       = winspool.NewProc("WritePrinter")
+	endPagePrinterProc     = winspool.NewProc("EndPagePrinter")
+	endDocPrinterProc      = winspool.NewProc("EndDocPrinter")
+	procGetDefaultPrinterW = winspool.NewProc("GetDefaultPrinterW")
+)
+
+// Errors returned by GetLastError().
+const (
+	NO_ERROR                  = winerr.ERROR_SUCCESS
+	ERROR_SUCCESS             = winerr.ERROR_SUCCESS
+	ERROR_MORE_DATA           = winerr.ERROR_MORE_DATA
+	ERROR_INVALID_PARAMETER   = winerr.ERROR_INVALID_PARAMETER
+	ERROR_INSUFFICIENT_BUFFER = winerr.ERROR_INSUFFICIENT_BUFFER
+	ERROR_NO_MORE_ITEMS       = winerr.ERROR_NO_MORE_ITEMS
+)
@@ -0,0 +1,99 @@
+package winspool
+
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/FxStar/winapi/internal/winerr"
+	"github.com/FxStar/winapi/wstr"
+)
+
+// The two-call functions are portable, so their buffer handling can be
+// exercised with a proc.Fake.
+
+func GetDefaultPrinter(buf *uint16, bufN *uint32) (err error) {
+	r1, _, e1 := procGetDefaultPrinterW.Call(uintptr(unsafe.Pointer(buf)), uintptr(unsafe.Pointer(bufN)))
+	if r1 == 0 {
+		err = winerr.New("GetDefaultPrinterW", e1)
+	}
+	return
+}
+
+func Default() (string, error) {
+	b := make([]uint16, 3)
+	n := uint32(len(b))
+	err := GetDefaultPrinter(&b[0], &n)
+	if err != nil {
+		if !errors.Is(err, ERROR_INSUFFICIENT_BUFFER) {
+			return "", err
+		}
+		if n == 0 {
+			return "", winerr.New("GetDefaultPrinterW", winerr.ERROR_INVALID_DATA)
+		}
+		b = make([]uint16, n)
+		err = GetDefaultPrinter(&b[0], &n)
+		if err != nil {
+			return "", err
+		}
+	}
+	return wstr.FromArray(b), nil
+}
+
+// JOB_INFO_1 struct.
+type JobInfo1 struct {
+	jobID        uint32
+	pPrinterName *uint16
+	pMachineName *uint16
+	pUserName    *uint16
+	pDocument    *uint16
+	pDatatype    *uint16
+	pStatus      *uint16
+	status       uint32
+	priority     uint32
+	position     uint32
+	totalPages   uint32
+	pagesPrinted uint32
+
+	// SYSTEMTIME structure, in line.
+	wSubmittedYear         uint16
+	wSubmittedMonth        uint16
+	wSubmittedDayOfWeek    uint16
+	wSubmittedDay          uint16
+	wSubmittedHour         uint16
+	wSubmittedMinute       uint16
+	wSubmittedSecond       uint16
+	wSubmittedMilliseconds uint16
+}
+
+func (ji1 *JobInfo1) GetStatus() uint32 {
+	return ji1.status
+}
+
+func (ji1 *JobInfo1) GetTotalPages() uint32 {
+	return ji1.totalPages
+}
+
+func (ji1 *JobInfo1) GetPagesPrinted() uint32 {
+	return ji1.pagesPrinted
+}
+
+func (hPrinter HANDLE) GetJob(jobID int32) (*JobInfo1, error) {
+	var cbBuf uint32
+	_, _, err := getJobProc.Call(uintptr(hPrinter), uintptr(jobID), 1, 0, 0, uintptr(unsafe.Pointer(&cbBuf)))
+	if err != ERROR_INSUFFICIENT_BUFFER {
+		return nil, winerr.New("GetJobW", err)
+	}
+	if cbBuf < uint32(unsafe.Sizeof(JobInfo1{})) {
+		return nil, winerr.New("GetJobW", winerr.ERROR_INVALID_DATA)
+	}
+
+	var pJob []byte = make([]byte, cbBuf)
+	r1, _, err := getJobProc.Call(uintptr(hPrinter), uintptr(jobID), 1, uintptr(unsafe.Pointer(&pJob[0])), uintptr(cbBuf), uintptr(unsafe.Pointer(&cbBuf)))
+	if r1 == 0 {
+		return nil, winerr.New("GetJobW", err)
+	}
+
+	var ji1 JobInfo1 = *(*JobInfo1)(unsafe.Pointer(&pJob[0]))
+
+	return &ji1, nil
+}
@@ -0,0 +1,137 @@
+package winspool
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/FxStar/winapi/internal/proc"
+	"github.com/FxStar/winapi/internal/winerr"
+)
+
+func swap(t *testing.T, p *proc.Proc, steps ...proc.Step) *proc.Fake {
+	fake := &proc.Fake{Steps: steps}
+	prev := p.Swap(fake)
+	t.Cleanup(func() { p.Swap(prev) })
+	return fake
+}
+
+func TestDefault(t *testing.T) {
+	const name = "Brother HL-2130 series"
+	want := uint32(len(name) + 1)
+	fake := swap(t, procGetDefaultPrinterW,
+		proc.Step{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) {
+			if n := proc.Uint32(a[1]); n != 3 {
+				t.Errorf("first call with size %d", n)
+			}
+			proc.PutUint32(a[1], want)
+		}},
+		proc.Step{R1: 1, Do: func(a []uintptr) {
+			if n := proc.Uint32(a[1]); n != want {
+				t.Errorf("second call with size %d, want %d", n, want)
+			}
+			proc.PutUint16s(a[0], utf16.Encode([]rune(name+"\x00")))
+		}},
+	)
+	got, err := Default()
+	if err != nil || got != name {
+		t.Errorf("Default() = %q, %v, want %q", got, err, name)
+	}
+	if n := len(fake.Calls()); n != 2 {
+		t.Errorf("GetDefaultPrinterW called %d times", n)
+	}
+}
+
+func TestDefaultShort(t *testing.T) {
+	fake := swap(t, procGetDefaultPrinterW, proc.Step{R1: 1, Do: func(a []uintptr) {
+		proc.PutUint16s(a[0], []uint16{'P', '1', 0})
+	}})
+	if got, err := Default(); err != nil || got != "P1" {
+		t.Errorf("Default() = %q, %v", got, err)
+	}
+	if n := len(fake.Calls()); n != 1 {
+		t.Errorf("GetDefaultPrinterW called %d times", n)
+	}
+}
+
+func TestDefaultError(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []proc.Step
+		err   error
+	}{
+		{"no default printer", []proc.Step{{Errno: winerr.ERROR_FILE_NOT_FOUND}}, winerr.ERROR_FILE_NOT_FOUND},
+		{"second call", []proc.Step{
+			{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) { proc.PutUint32(a[1], 10) }},
+			{Errno: winerr.ERROR_FILE_NOT_FOUND},
+		}, winerr.ERROR_FILE_NOT_FOUND},
+		{"no size", []proc.Step{
+			{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) { proc.PutUint32(a[1], 0) }},
+		}, winerr.ERROR_INVALID_DATA},
+	}
+	for _, tt := range tests {
+		swap(t, procGetDefaultPrinterW, tt.steps...)
+		_, err := Default()
+		var e *winerr.Error
+		if !errors.As(err, &e) || e.API != "GetDefaultPrinterW" || !errors.Is(err, tt.err) {
+			t.Errorf("%s: Default() error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	job := JobInfo1{jobID: 7, status: 0x10, totalPages: 3, pagesPrinted: 1}
+	size := uint32(unsafe.Sizeof(job)) + 200 // the strings follow the struct
+	fake := swap(t, getJobProc,
+		proc.Step{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) {
+			if a[3] != 0 || a[4] != 0 {
+				t.Errorf("first call with buffer %#x, size %d", a[3], a[4])
+			}
+			proc.PutUint32(a[5], size)
+		}},
+		proc.Step{R1: 1, Do: func(a []uintptr) {
+			if uint32(a[4]) != size {
+				t.Errorf("second call with size %d, want %d", a[4], size)
+			}
+			proc.PutBytes(a[3], unsafe.Slice((*byte)(unsafe.Pointer(&job)), unsafe.Sizeof(job)))
+		}},
+	)
+	ji, err := HANDLE(0x40).GetJob(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ji.jobID != 7 || ji.GetStatus() != 0x10 || ji.GetTotalPages() != 3 || ji.GetPagesPrinted() != 1 {
+		t.Errorf("GetJob() = %+v", ji)
+	}
+	for i, a := range fake.Calls() {
+		if a[0] != 0x40 || a[1] != 7 || a[2] != 1 {
+			t.Errorf("call %d: handle %#x, job %d, level %d", i+1, a[0], a[1], a[2])
+		}
+	}
+}
+
+func TestGetJobError(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []proc.Step
+		err   error
+	}{
+		{"no such job", []proc.Step{{Errno: ERROR_INVALID_PARAMETER}}, ERROR_INVALID_PARAMETER},
+		{"first call succeeds", []proc.Step{{R1: 1, Errno: NO_ERROR}}, ERROR_INVALID_PARAMETER},
+		{"second call", []proc.Step{
+			{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) { proc.PutUint32(a[5], 200) }},
+			{Errno: ERROR_INSUFFICIENT_BUFFER},
+		}, ERROR_INSUFFICIENT_BUFFER},
+		{"no size", []proc.Step{{Errno: ERROR_INSUFFICIENT_BUFFER}}, winerr.ERROR_INVALID_DATA},
+		{"size short of a JOB_INFO_1", []proc.Step{
+			{Errno: ERROR_INSUFFICIENT_BUFFER, Do: func(a []uintptr) { proc.PutUint32(a[5], 16) }},
+		}, winerr.ERROR_INVALID_DATA},
+	}
+	for _, tt := range tests {
+		swap(t, getJobProc, tt.steps...)
+		if _, err := HANDLE(1).GetJob(1); !errors.Is(err, tt.err) {
+			t.Errorf("%s: GetJob() error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
@@ -9,28 +9,15 @@
 package winspool
 
 import (
-	"reflect"
-	"syscall"
-	"unsafe"
+	"github.com/FxStar/winapi/wstr"
 )
 
-const utf16StringMaxBytes = 1024
-
+// utf16PtrToStringSize decodes at most bytes/2 units, up to the NUL.
 func utf16PtrToStringSize(s *uint16, bytes uint32) string {
-	if s == nil {
-		return ""
-	}
-
-	hdr := reflect.SliceHeader{
-		Data: uintptr(unsafe.Pointer(s)),
-		Len:  int(bytes / 2),
-		Cap:  int(bytes / 2),
-	}
-	c := *(*[]uint16)(unsafe.Pointer(&hdr))
-
-	return syscall.UTF16ToString(c)
+	return wstr.FromPtrN(s, int(bytes/2))
 }
 
+// utf16PtrToString decodes up to the NUL, for the strings the API returns.
 func utf16PtrToString(s *uint16) string {
-	return utf16PtrToStringSize(s, utf16StringMaxBytes)
+	return wstr.FromPtr(s)
 }
@@ -17,52 +17,11 @@ import (
 	"syscall"
 	"unsafe"
 
+	"github.com/FxStar/winapi/regvalue"
+	"github.com/FxStar/winapi/wstr"
 	"golang.org/x/sys/windows"
 )
 
-var (
-	gdi32    = syscall.NewLazyDLL("gdi32.dll")
-	kernel32 = syscall.NewLazyDLL("kernel32.dll")
-	ntoskrnl = syscall.NewLazyDLL("ntoskrnl.exe")
-	winspool = syscall.NewLazyDLL("winspool.drv")
-	user32   = syscall.NewLazyDLL("user32.dll")
-
-	abortDocProc                   = gdi32.NewProc("AbortDoc")
-	closePrinterProc               = winspool.NewProc("ClosePrinter")
-	createDCProc                   = gdi32.NewProc("CreateDCW")
-	deleteDCProc                   = gdi32.NewProc("DeleteDC")
-	deviceCapabilitiesProc         = winspool.NewProc("DeviceCapabilitiesW")
-	documentPropertiesProc         = winspool.NewProc("DocumentPropertiesW")
-	endDocProc                     = gdi32.NewProc("EndDoc")
-	endPageProc                    = gdi32.NewProc("EndPage")
-	enumPrintersProc               = winspool.NewProc("EnumPrintersW")
-	getDeviceCapsProc              = gdi32.NewProc("GetDeviceCaps")
-	getJobProc                     = winspool.NewProc("GetJobW")
-	openPrinterProc                = winspool.NewProc("OpenPrinterW")
-	resetDCProc                    = gdi32.NewProc("ResetDCW")
-	rtlGetVersionProc              = ntoskrnl.NewProc("RtlGetVersion")
-	setGraphicsModeProc            = gdi32.NewProc("SetGraphicsMode")
-	setJobProc                     = winspool.NewProc("SetJobW")
-	setWorldTransformProc          = gdi32.NewProc("SetWorldTransform")
-	startDocProc                   = gdi32.NewProc("StartDocW")
-	startPageProc                  = gdi32.NewProc("StartPage")
-	registerDeviceNotificationProc = user32.NewProc("RegisterDeviceNotificationW")
-)
-
-// System error codes.
-const (
-	ERROR_SUCCESS   = 0
-	ERROR_MORE_DATA = 234
-)
-
-// Errors returned by GetLastError().
-const (
-	NO_ERROR                  = syscall.Errno(0)
-	ERROR_INVALID_PARAMETER   = syscall.Errno(87)
-	ERROR_INSUFFICIENT_BUFFER = syscall.Errno(122)
-	ERROR_NO_MORE_ITEMS       = syscall.Errno(259)
-)
-
 // First parameter to EnumPrinters().
 const (
 	PRINTER_ENUM_DEFAULT     = 0x00000001
@@ -89,20 +48,20 @@ const (
 
 // Registry value types.
 const (
-	REG_NONE                       = 0
-	REG_SZ                         = 1
-	REG_EXPAND_SZ                  = 2
-	REG_BINARY                     = 3
-	REG_DWORD                      = 4
-	REG_DWORD_LITTLE_ENDIAN        = 4
-	REG_DWORD_BIG_ENDIAN           = 5
-	REG_LINK                       = 6
-	REG_MULTI_SZ                   = 7
-	REG_RESOURCE_LIST              = 8
-	REG_FULL_RESOURCE_DESCRIPTOR   = 9
-	REG_RESOURCE_REQUIREMENTS_LIST = 10
-	REG_QWORD                      = 11
-	REG_QWORD_LITTLE_ENDIAN        = 11
+	REG_NONE                       = regvalue.REG_NONE
+	REG_SZ                         = regvalue.REG_SZ
+	REG_EXPAND_SZ                  = regvalue.REG_EXPAND_SZ
+	REG_BINARY                     = regvalue.REG_BINARY
+	REG_DWORD                      = regvalue.REG_DWORD
+	REG_DWORD_LITTLE_ENDIAN        = regvalue.REG_DWORD_LITTLE_ENDIAN
+	REG_DWORD_BIG_ENDIAN           = regvalue.REG_DWORD_BIG_ENDIAN
+	REG_LINK                       = regvalue.REG_LINK
+	REG_MULTI_SZ                   = regvalue.REG_MULTI_SZ
+	REG_RESOURCE_LIST              = regvalue.REG_RESOURCE_LIST
+	REG_FULL_RESOURCE_DESCRIPTOR   = regvalue.REG_FULL_RESOURCE_DESCRIPTOR
+	REG_RESOURCE_REQUIREMENTS_LIST = regvalue.REG_RESOURCE_REQUIREMENTS_LIST
+	REG_QWORD                      = regvalue.REG_QWORD
+	REG_QWORD_LITTLE_ENDIAN        = regvalue.REG_QWORD_LITTLE_ENDIAN
 )
 
 // PRINTER_INFO_2 attribute values
@@ -239,6 +198,26 @@ type PrinterEnumValues struct {
 	cbData      uint32
 }
 
+func (pev *PrinterEnumValues) GetValueName() string {
+	return utf16PtrToStringSize(pev.pValueName, pev.cbValueName)
+}
+
+func (pev *PrinterEnumValues) GetType() uint32 {
+	return pev.dwType
+}
+
+func (pev *PrinterEnumValues) GetData() []byte {
+	if pev.pData == 0 || pev.cbData == 0 {
+		return nil
+	}
+	return binaryRegValueToBytes(pev.pData, pev.cbData)
+}
+
+// GetValue decodes the data according to its type, see regvalue.Decode.
+func (pev *PrinterEnumValues) GetValue() (interface{}, error) {
+	return regvalue.Decode(pev.dwType, pev.GetData())
+}
+
 // DEVMODE constants.
 const (
 	CCHDEVICENAME = 32
@@ -384,7 +363,7 @@ func (dm *DevMode) String() string {
 		s = append(s, fmt.Sprintf("collate: %d", dm.dmCollate))
 	}
 	if dm.dmFields&DM_FORMNAME != 0 {
-		s = append(s, fmt.Sprintf("formname: %s", utf16PtrToString(&dm.dmFormName)))
+		s = append(s, fmt.Sprintf("formname: %s", utf16PtrToStringSize(&dm.dmFormName, CCHFORMNAME*2)))
 	}
 	if dm.dmFields&DM_LOGPIXELS != 0 {
 		s = append(s, fmt.Sprintf("log pixels: %d", dm.dmLogPixels))
@@ -662,8 +641,6 @@ func EnumPrinters4() ([]PrinterInfo4, error) {
 	return printers, nil
 }
 
-type HANDLE uintptr
-
 func OpenPrinter(printerName string) (HANDLE, error) {
 	var pPrinterName *uint16
 	pPrinterName, err := syscall.UTF16PtrFromString(printerName)
@@ -746,62 +723,6 @@ const (
 	JOB_STATUS_RENDERING_LOCALLY uint32 = 0x00004000
 )
 
-// JOB_INFO_1 struct.
-type JobInfo1 struct {
-	jobID        uint32
-	pPrinterName *uint16
-	pMachineName *uint16
-	pUserName    *uint16
-	pDocument    *uint16
-	pDatatype    *uint16
-	pStatus      *uint16
-	status       uint32
-	priority     uint32
-	position     uint32
-	totalPages   uint32
-	pagesPrinted uint32
-
-	// SYSTEMTIME structure, in line.
-	wSubmittedYear         uint16
-	wSubmittedMonth        uint16
-	wSubmittedDayOfWeek    uint16
-	wSubmittedDay          uint16
-	wSubmittedHour         uint16
-	wSubmittedMinute       uint16
-	wSubmittedSecond       uint16
-	wSubmittedMilliseconds uint16
-}
-
-func (ji1 *JobInfo1) GetStatus() uint32 {
-	return ji1.status
-}
-
-func (ji1 *JobInfo1) GetTotalPages() uint32 {
-	return ji1.totalPages
-}
-
-func (ji1 *JobInfo1) GetPagesPrinted() uint32 {
-	return ji1.pagesPrinted
-}
-
-func (hPrinter HANDLE) GetJob(jobID int32) (*JobInfo1, error) {
-	var cbBuf uint32
-	_, _, err := getJobProc.Call(uintptr(hPrinter), uintptr(jobID), 1, 0, 0, uintptr(unsafe.Pointer(&cbBuf)))
-	if err != ERROR_INSUFFICIENT_BUFFER {
-		return nil, err
-	}
-
-	var pJob []byte = make([]byte, cbBuf)
-	r1, _, err := getJobProc.Call(uintptr(hPrinter), uintptr(jobID), 1, uintptr(unsafe.Pointer(&pJob[0])), uintptr(cbBuf), uintptr(unsafe.Pointer(&cbBuf)))
-	if r1 == 0 {
-		return nil, err
-	}
-
-	var ji1 JobInfo1 = *(*JobInfo1)(unsafe.Pointer(&pJob[0]))
-
-	return &ji1, nil
-}
-
 // SetJob command values.
 const (
 	JOB_CONTROL_PAUSE             uint32 = 1
@@ -851,8 +772,6 @@ func (hPrinter HANDLE) SetJobUserName(jobID int32, userName string) error {
 	return nil
 }
 
-type HDC uintptr
-
 func CreateDC(deviceName string, devMode *DevMode) (HDC, error) {
 	lpszDevice, err := syscall.UTF16PtrFromString(deviceName)
 	if err != nil {
@@ -1024,10 +943,11 @@ func DeviceCapabilitiesStrings(device, port string, fwCapability uint16, stringL
 		return nil, err
 	}
 
+	// nString strings of stringLength units, NUL terminated when shorter
+	output := unsafe.Slice((*uint16)(unsafe.Pointer(&pOutput[0])), len(pOutput)/uint16Size)
 	values := make([]string, 0, nString)
 	for i := int32(0); i < nString; i++ {
-		value := utf16PtrToString((*uint16)(unsafe.Pointer(&pOutput[i*stringLength])))
-		values = append(values, value)
+		values = append(values, wstr.FromArray(output[i*stringLength:(i+1)*stringLength]))
 	}
 
 	return values, nil
@@ -1233,7 +1153,7 @@ func GetWindowsVersion() string {
 
 	version := fmt.Sprintf("%d.%d.%d", osVersionInfo.dwMajorVersion, osVersionInfo.dwMinorVersion, osVersionInfo.dwBuildNumber)
 
-	servicePackVersion := utf16PtrToString((*uint16)(&osVersionInfo.szCSDVersion))
+	servicePackVersion := utf16PtrToStringSize(&osVersionInfo.szCSDVersion, 128*2)
 	if servicePackVersion != "" {
 		version = fmt.Sprintf("%s %s", version, servicePackVersion)
 	}
@@ -1280,7 +1200,23 @@ func RegisterDeviceNotification(handle windows.Handle) error {
 	notificationFilter.classGuid = PRINTERS_DEVICE_CLASS
 	notificationFilter.szName = 0
 
-	r1, _, err := registerDeviceNotificationProc.Call(uintptr(handle), uintptr(unsafe.Pointer(&notificationFilter)), DEVICE_NOTIFY_SERVICE_HANDLE|DEVICE_NOTIFY_ALL_INTERFACE_CLASSES)
+	_, err := RegisterDeviceNotificationFilter(uintptr(handle), unsafe.Pointer(&notificationFilter), DEVICE_NOTIFY_SERVICE_HANDLE|DEVICE_NOTIFY_ALL_INTERFACE_CLASSES)
+	return err
+}
+
+// RegisterDeviceNotificationFilter registers recipient, a window or a service
+// handle as flags tell, for the events that match filter, a DEV_BROADCAST_*
+// struct. The notification handle goes to UnregisterDeviceNotification.
+func RegisterDeviceNotificationFilter(recipient uintptr, filter unsafe.Pointer, flags uint32) (uintptr, error) {
+	r1, _, err := registerDeviceNotificationProc.Call(recipient, uintptr(filter), uintptr(flags))
+	if r1 == 0 {
+		return 0, err
+	}
+	return r1, nil
+}
+
+func UnregisterDeviceNotification(h uintptr) error {
+	r1, _, err := unregisterDeviceNotificationProc.Call(h)
 	if r1 == 0 {
 		return err
 	}
@@ -128,3 +128,250 @@ type Msg struct {
 	Time    uint32
 	Pt      POINT
 }
+
+type KEYBDINPUT struct {
+	Vk        WORD
+	Scan      WORD
+	Flags     DWORD
+	Time      DWORD
+	ExtraInfo uintptr
+}
+
+type HHOOK HANDLE
+
+type HOOKPROC func(nCode int, wParam WPARAM, lParam LPARAM) LRESULT
+
+// KBDLLHOOKSTRUCT is the lParam of a WH_KEYBOARD_LL hook.
+type KBDLLHOOKSTRUCT struct {
+	VkCode      uint32
+	ScanCode    uint32
+	Flags       uint32 // LLKHF_*
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// MSLLHOOKSTRUCT is the lParam of a WH_MOUSE_LL hook. Pt is spelled out as
+// X and Y, POINT has pointer sized fields.
+type MSLLHOOKSTRUCT struct {
+	X, Y        int32
+	MouseData   uint32 // wheel delta or XBUTTON* in the high word
+	Flags       uint32 // LLMHF_*
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// KeyboardInput is an INPUT of type INPUT_KEYBOARD, padded to the size of
+// the MOUSEINPUT member of the union.
+type KeyboardInput struct {
+	Type DWORD
+	Ki   KEYBDINPUT
+	_    [8]byte
+}
+
+// LASTINPUTINFO is filled by GetLastInputInfo, CbSize must be set.
+type LASTINPUTINFO struct {
+	CbSize uint32
+	DwTime uint32 // GetTickCount of the last input event
+}
+
+// TPMPARAMS is the area TrackPopupMenuEx keeps the menu off, CbSize must be
+// set.
+type TPMPARAMS struct {
+	CbSize    uint32
+	RcExclude RECT
+}
+
+// WNDPROC is a window procedure, passed to Windows as a syscall.NewCallback.
+type WNDPROC func(hwnd HWND, msg UINT, wParam WPARAM, lParam LPARAM) uintptr
+
+type TFNTimerProc func(hWnd uintptr, uMsg UINT, idEvent TimerEventID, Time DWORD)
+
+// Virtual Keys, Standard Set
+// https://docs.microsoft.com/zh-cn/windows/win32/inputdev/virtual-key-codes
+const (
+	VK_LBUTTON  = 0x01
+	VK_RBUTTON  = 0x02
+	VK_CANCEL   = 0x03
+	VK_MBUTTON  = 0x04
+	VK_BACK     = 0x08
+	VK_TAB      = 0x09
+	VK_CLEAR    = 0x0C
+	VK_RETURN   = 0x0D
+	VK_SHIFT    = 0x10
+	VK_CONTROL  = 0x11
+	VK_MENU     = 0x12
+	VK_PAUSE    = 0x13
+	VK_CAPITAL  = 0x14
+	VK_KANA     = 0x15
+	VK_KANJI    = 0x19
+	VK_ESCAPE   = 0x1B
+	VK_CONVERT  = 0x1C
+	VK_SPACE    = 0x20
+	VK_PRIOR    = 0x21
+	VK_NEXT     = 0x22
+	VK_END      = 0x23
+	VK_HOME     = 0x24
+	VK_LEFT     = 0x25
+	VK_UP       = 0x26
+	VK_RIGHT    = 0x27
+	VK_DOWN     = 0x28
+	VK_SELECT   = 0x29
+	VK_PRINT    = 0x2A
+	VK_EXECUTE  = 0x2B
+	VK_SNAPSHOT = 0x2C
+	VK_INSERT   = 0x2D
+	VK_DELETE   = 0x2E
+	VK_HELP     = 0x2F
+	// VK_0 - VK_9 are the same as ASCII '0' - '9' (0x30 - 0x39)
+	// VK_A - VK_Z are the same as ASCII 'A' - 'Z' (0x41 - 0x5A)
+	VK_LWIN      = 0x5B
+	VK_RWIN      = 0x5C
+	VK_APPS      = 0x5D
+	VK_SLEEP     = 0x5F
+	VK_NUMPAD0   = 0x60
+	VK_NUMPAD1   = 0x61
+	VK_NUMPAD2   = 0x62
+	VK_NUMPAD3   = 0x63
+	VK_NUMPAD4   = 0x64
+	VK_NUMPAD5   = 0x65
+	VK_NUMPAD6   = 0x66
+	VK_NUMPAD7   = 0x67
+	VK_NUMPAD8   = 0x68
+	VK_NUMPAD9   = 0x69
+	VK_MULTIPLY  = 0x6A
+	VK_ADD       = 0x6B
+	VK_SEPARATOR = 0x6C
+	VK_SUBTRACT  = 0x6D
+	VK_DECIMAL   = 0x6E
+	VK_DIVIDE    = 0x6F
+	VK_F1        = 0x70
+	VK_F2        = 0x71
+	VK_F3        = 0x72
+	VK_F4        = 0x73
+	VK_F5        = 0x74
+	VK_F6        = 0x75
+	VK_F7        = 0x76
+	VK_F8        = 0x77
+	VK_F9        = 0x78
+	VK_F10       = 0x79
+	VK_F11       = 0x7A
+	VK_F12       = 0x7B
+	VK_F13       = 0x7C
+	VK_F14       = 0x7D
+	VK_F15       = 0x7E
+	VK_F16       = 0x7F
+	VK_F17       = 0x80
+	VK_F18       = 0x81
+	VK_F19       = 0x82
+	VK_F20       = 0x83
+	VK_F21       = 0x84
+	VK_F22       = 0x85
+	VK_F23       = 0x86
+	VK_F24       = 0x87
+	VK_NUMLOCK   = 0x90
+	VK_SCROLL    = 0x91
+	VK_LSHIFT    = 0xA0
+	VK_RSHIFT    = 0xA1
+	VK_LCONTROL  = 0xA2
+	VK_RCONTROL  = 0xA3
+	VK_LMENU     = 0xA4
+	VK_RMENU     = 0xA5
+
+	VK_VOLUME_MUTE      = 0xAD
+	VK_VOLUME_DOWN      = 0xAE
+	VK_VOLUME_UP        = 0xAF
+	VK_MEDIA_NEXT_TRACK = 0xB0
+	VK_MEDIA_PREV_TRACK = 0xB1
+	VK_MEDIA_STOP       = 0xB2
+	VK_MEDIA_PLAY_PAUSE = 0xB3
+
+	VK_OEM_1      = 0xBA // ';:' for US
+	VK_OEM_PLUS   = 0xBB // '+' any country
+	VK_OEM_COMMA  = 0xBC // ',' any country
+	VK_OEM_MINUS  = 0xBD // '-' any country
+	VK_OEM_PERIOD = 0xBE // '.' any country
+	VK_OEM_2      = 0xBF // '/?' for US
+	VK_OEM_3      = 0xC0 // '`~' for US
+	VK_OEM_4      = 0xDB // '[{' for US
+	VK_OEM_5      = 0xDC // '\\|' for US
+	VK_OEM_6      = 0xDD // ']}' for US
+	VK_OEM_7      = 0xDE // ''"' for US
+	VK_OEM_8      = 0xDF
+	VK_OEM_102    = 0xE2 // '<>' or '\\|' on RT 102-key kbd
+	VK_PROCESSKEY = 0xE5
+	VK_PACKET     = 0xE7
+)
+
+const (
+	INPUT_MOUSE    = 0
+	INPUT_KEYBOARD = 1
+	INPUT_HARDWARE = 2
+
+	KEYEVENTF_EXTENDEDKEY = 0x0001
+	KEYEVENTF_KEYUP       = 0x0002
+	KEYEVENTF_UNICODE     = 0x0004
+	KEYEVENTF_SCANCODE    = 0x0008
+
+	// KBDLLHOOKSTRUCT flags
+	LLKHF_EXTENDED = 0x01
+	LLKHF_INJECTED = 0x10
+	LLKHF_ALTDOWN  = 0x20
+	LLKHF_UP       = 0x80
+
+	// MSLLHOOKSTRUCT flags
+	LLMHF_INJECTED          = 0x01
+	LLMHF_LOWER_IL_INJECTED = 0x02
+)
+
+// PeekMessage wRemoveMsg
+const (
+	PM_NOREMOVE = 0x0000
+	PM_REMOVE   = 0x0001
+	PM_NOYIELD  = 0x0002
+)
+
+// SetWindowsHookEx hook types
+const (
+	WH_KEYBOARD    = 2
+	WH_GETMESSAGE  = 3
+	WH_CALLWNDPROC = 4
+	WH_CBT         = 5
+	WH_MOUSE       = 7
+	WH_KEYBOARD_LL = 13
+	WH_MOUSE_LL    = 14
+
+	HC_ACTION = 0
+)
+
+// MSLLHOOKSTRUCT.MouseData high word of WM_XBUTTON* messages
+const (
+	XBUTTON1 = 0x0001
+	XBUTTON2 = 0x0002
+
+	WHEEL_DELTA = 120
+)
+
+// RegisterHotKey modifiers
+const (
+	MOD_ALT      = 0x0001
+	MOD_CONTROL  = 0x0002
+	MOD_SHIFT    = 0x0004
+	MOD_WIN      = 0x0008
+	MOD_NOREPEAT = 0x4000
+)
+
+// WM_SIZE wParam
+const (
+	SIZE_RESTORED  = 0
+	SIZE_MINIMIZED = 1
+	SIZE_MAXIMIZED = 2
+	SIZE_MAXSHOW   = 3
+	SIZE_MAXHIDE   = 4
+)
+
+// WM_ACTIVATE wParam low word
+const (
+	WA_INACTIVE    = 0
+	WA_ACTIVE      = 1
+	WA_CLICKACTIVE = 2
+)
@@ -0,0 +1,100 @@
+// Package wstr converts between Go strings and the UTF-16 strings of the API:
+// NUL terminated pointers, fixed WCHAR arrays and double-NUL terminated lists.
+// Nothing reads past the NUL or the given length.
+package wstr
+
+import (
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// PtrFromString returns a NUL terminated copy of s to pass to the API,
+// syscall.EINVAL if s holds a NUL.
+func PtrFromString(s string) (*uint16, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 {
+			return nil, syscall.EINVAL
+		}
+	}
+	u := utf16.Encode([]rune(s + "\x00"))
+	return &u[0], nil
+}
+
+// FromPtr decodes the NUL terminated string at p, for the strings the API
+// returns. Use FromPtrN when the buffer may lack the NUL.
+func FromPtr(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	n := 0
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; n++ {
+		ptr = unsafe.Add(ptr, 2)
+	}
+	return string(utf16.Decode(unsafe.Slice(p, n)))
+}
+
+// FromPtrN decodes at most n units at p, up to the NUL.
+func FromPtrN(p *uint16, n int) string {
+	if p == nil || n <= 0 {
+		return ""
+	}
+	return FromArray(unsafe.Slice(p, n))
+}
+
+// FromArray decodes a WCHAR array up to the NUL, all of it when there is none:
+//
+//	tip := wstr.FromArray(nid.SzTip[:])
+func FromArray(a []uint16) string {
+	for i, c := range a {
+		if c == 0 {
+			a = a[:i]
+			break
+		}
+	}
+	return string(utf16.Decode(a))
+}
+
+// Copy copies s into the WCHAR array dst, NUL terminated and zero padded. A
+// string too long is truncated, never between the halves of a surrogate
+// pair, and Copy returns true.
+func Copy(dst []uint16, s string) (truncated bool) {
+	if len(dst) == 0 {
+		return s != ""
+	}
+	u := utf16.Encode([]rune(s))
+	if n := len(dst) - 1; len(u) > n {
+		u = u[:n]
+		if n > 0 && utf16.IsSurrogate(rune(u[n-1])) && u[n-1] < 0xDC00 {
+			u = u[:n-1] // high half without its low half
+		}
+		truncated = true
+	}
+	n := copy(dst, u)
+	for i := n; i < len(dst); i++ {
+		dst[i] = 0
+	}
+	return truncated
+}
+
+// Split decodes a double-NUL terminated list, REG_MULTI_SZ or the
+// dependencies of a service. It stops at the empty string that ends the list
+// and tolerates missing terminators.
+func Split(a []uint16) []string {
+	var list []string
+	for len(a) > 0 {
+		n := 0
+		for n < len(a) && a[n] != 0 {
+			n++
+		}
+		if n == 0 {
+			break
+		}
+		list = append(list, string(utf16.Decode(a[:n])))
+		if n == len(a) {
+			break
+		}
+		a = a[n+1:]
+	}
+	return list
+}
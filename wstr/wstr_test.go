@@ -0,0 +1,166 @@
+package wstr_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/FxStar/winapi/wstr"
+)
+
+func TestPtrFromString(t *testing.T) {
+	for _, s := range []string{"", "printer", "a😀b"} {
+		p, err := wstr.PtrFromString(s)
+		if err != nil {
+			t.Fatalf("PtrFromString(%q): %v", s, err)
+		}
+		if got := wstr.FromPtr(p); got != s {
+			t.Errorf("FromPtr(PtrFromString(%q)) = %q", s, got)
+		}
+	}
+	if _, err := wstr.PtrFromString("a\x00b"); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("PtrFromString with a NUL = %v, want EINVAL", err)
+	}
+}
+
+func TestFromPtr(t *testing.T) {
+	u := utf16.Encode([]rune("abc\x00def"))
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{2, "ab"},
+		{3, "abc"},
+		{len(u), "abc"},
+	}
+	for _, tt := range tests {
+		if got := wstr.FromPtrN(&u[0], tt.n); got != tt.want {
+			t.Errorf("FromPtrN(%q, %d) = %q, want %q", u, tt.n, got, tt.want)
+		}
+	}
+	if got := wstr.FromPtr(&u[0]); got != "abc" {
+		t.Errorf("FromPtr() = %q, want %q", got, "abc")
+	}
+	if wstr.FromPtr(nil) != "" || wstr.FromPtrN(nil, 4) != "" {
+		t.Error("nil pointer decoded")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	tests := []struct {
+		s         string
+		n         int
+		want      string
+		truncated bool
+	}{
+		{"tip", 8, "tip", false},
+		{"tip", 4, "tip", false},
+		{"tip", 3, "ti", true},
+		{"", 0, "", false},
+		{"tip", 0, "", true},
+		{"a😀", 3, "a", true}, // the pair needs 2 units and the NUL one
+		{"a😀", 4, "a😀", false},
+	}
+	for _, tt := range tests {
+		dst := make([]uint16, tt.n)
+		for i := range dst {
+			dst[i] = 0xFFFF
+		}
+		truncated := wstr.Copy(dst, tt.s)
+		if got := wstr.FromArray(dst); got != tt.want || truncated != tt.truncated {
+			t.Errorf("Copy([%d], %q) = %q, %v, want %q, %v", tt.n, tt.s, got, truncated, tt.want, tt.truncated)
+		}
+		for i := len(utf16.Encode([]rune(tt.want))); i < len(dst); i++ {
+			if dst[i] != 0 {
+				t.Errorf("Copy([%d], %q) left %#x at %d", tt.n, tt.s, dst[i], i)
+			}
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{"\x00\x00", nil},
+		{"a\x00bc\x00\x00", []string{"a", "bc"}},
+		{"a\x00bc\x00", []string{"a", "bc"}},
+		{"a\x00bc", []string{"a", "bc"}},
+		{"a\x00\x00b\x00\x00", []string{"a"}},
+	}
+	for _, tt := range tests {
+		got := wstr.Split(utf16.Encode([]rune(tt.s)))
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || len(got) != len(tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+// FuzzCopy checks that Copy stays in dst, never splits a surrogate pair and
+// keeps a prefix of s.
+func FuzzCopy(f *testing.F) {
+	f.Add("hello", 4)
+	f.Add("a😀b", 3)
+	f.Add("a\x00b", 8)
+	f.Fuzz(func(t *testing.T, s string, n int) {
+		if n < 0 || n > 300 {
+			return
+		}
+		dst := make([]uint16, n+1)
+		dst[n] = 0xBEEF
+		truncated := wstr.Copy(dst[:n], s)
+		if dst[n] != 0xBEEF {
+			t.Fatalf("Copy([%d], %q) wrote past dst", n, s)
+		}
+		got := wstr.FromArray(dst[:n])
+		// what the API sees: s as runes, up to the NUL
+		want := string([]rune(s))
+		if i := strings.IndexByte(want, 0); i >= 0 {
+			want = want[:i]
+		}
+		if !strings.HasPrefix(want, got) || !utf8.ValidString(got) {
+			t.Fatalf("Copy([%d], %q) = %q, not a prefix of %q", n, s, got, want)
+		}
+		if !truncated && got != want {
+			t.Fatalf("Copy([%d], %q) = %q, want %q", n, s, got, want)
+		}
+		if strings.Count(got, "�") > strings.Count(want, "�") {
+			t.Fatalf("Copy([%d], %q) = %q, split a surrogate pair", n, s, got)
+		}
+	})
+}
+
+// FuzzSplit checks Split and the bounded decoders on arbitrary buffers.
+func FuzzSplit(f *testing.F) {
+	f.Add([]byte("a\x00\x00b\x00\x00\x00"))
+	f.Add([]byte("ab"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+		}
+		list := wstr.Split(u)
+		for _, s := range list {
+			if s == "" || strings.IndexByte(s, 0) >= 0 {
+				t.Fatalf("Split(%q) = %q", u, list)
+			}
+		}
+		if len(u) == 0 {
+			return
+		}
+		first := wstr.FromPtrN(&u[0], len(u))
+		if first != wstr.FromArray(u) {
+			t.Fatalf("FromPtrN(%q) = %q, FromArray %q", u, first, wstr.FromArray(u))
+		}
+		if len(list) > 0 && first != list[0] {
+			t.Fatalf("Split(%q)[0] = %q, FromPtrN %q", u, list[0], first)
+		}
+	})
+}
@@ -55,7 +55,7 @@ type (
 	LPSTR    *byte
 	LPCSTR   *byte //syscall.StringBytePtr()
 	LPWSTR   *uint16
-	LPCWSTR  *uint16 //syscall.StringToUTF16Ptr()
+	LPCWSTR  *uint16 //wstr.PtrFromString()
 	LPBYTE   *BYTE
 
 	TimerEventID UINT
@@ -275,6 +275,58 @@ const (
 	MF_MOUSESELECT = 0x00008000
 )
 
+// MENUITEMINFO fields, FMask
+const (
+	MIIM_STATE      = 0x00000001
+	MIIM_ID         = 0x00000002
+	MIIM_SUBMENU    = 0x00000004
+	MIIM_CHECKMARKS = 0x00000008
+	MIIM_TYPE       = 0x00000010
+	MIIM_DATA       = 0x00000020
+	MIIM_STRING     = 0x00000040
+	MIIM_BITMAP     = 0x00000080
+	MIIM_FTYPE      = 0x00000100
+)
+
+// MENUITEMINFO types and states, FType and FState
+const (
+	MFT_STRING       = MF_STRING
+	MFT_BITMAP       = MF_BITMAP
+	MFT_MENUBARBREAK = MF_MENUBARBREAK
+	MFT_MENUBREAK    = MF_MENUBREAK
+	MFT_OWNERDRAW    = MF_OWNERDRAW
+	MFT_RADIOCHECK   = 0x00000200
+	MFT_SEPARATOR    = MF_SEPARATOR
+	MFT_RIGHTORDER   = 0x00002000
+	MFT_RIGHTJUSTIFY = 0x00004000
+
+	MFS_GRAYED    = 0x00000003
+	MFS_DISABLED  = MFS_GRAYED
+	MFS_CHECKED   = MF_CHECKED
+	MFS_HILITE    = MF_HILITE
+	MFS_ENABLED   = MF_ENABLED
+	MFS_UNCHECKED = MF_UNCHECKED
+	MFS_UNHILITE  = MF_UNHILITE
+	MFS_DEFAULT   = MF_DEFAULT
+)
+
+// MENUITEMINFO describes a menu item for InsertMenuItem, FMask tells the
+// fields used. DwTypeData is the text of MFT_STRING items.
+type MENUITEMINFO struct {
+	CbSize        uint32
+	FMask         uint32
+	FType         uint32
+	FState        uint32
+	WID           uint32
+	HSubMenu      HMENU
+	HbmpChecked   HBITMAP
+	HbmpUnchecked HBITMAP
+	DwItemData    uintptr
+	DwTypeData    *uint16
+	Cch           uint32
+	HbmpItem      HBITMAP
+}
+
 const (
 	/*
 	 * Flags for TrackPopupMenu
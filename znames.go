@@ -0,0 +1,513 @@
+// Code generated by mknames; DO NOT EDIT.
+
+package winapi
+
+// WM_*
+var wmNames = enumNames{
+	{0x0, "WM_NULL"},
+	{0x1, "WM_CREATE"},
+	{0x2, "WM_DESTROY"},
+	{0x3, "WM_MOVE"},
+	{0x5, "WM_SIZE"},
+	{0x6, "WM_ACTIVATE"},
+	{0x7, "WM_SETFOCUS"},
+	{0x8, "WM_KILLFOCUS"},
+	{0xa, "WM_ENABLE"},
+	{0xb, "WM_SETREDRAW"},
+	{0xc, "WM_SETTEXT"},
+	{0xd, "WM_GETTEXT"},
+	{0xe, "WM_GETTEXTLENGTH"},
+	{0xf, "WM_PAINT"},
+	{0x10, "WM_CLOSE"},
+	{0x11, "WM_QUERYENDSESSION"},
+	{0x12, "WM_QUIT"},
+	{0x13, "WM_QUERYOPEN"},
+	{0x14, "WM_ERASEBKGND"},
+	{0x15, "WM_SYSCOLORCHANGE"},
+	{0x16, "WM_ENDSESSION"},
+	{0x18, "WM_SHOWWINDOW"},
+	{0x1a, "WM_SETTINGCHANGE"},
+	{0x1b, "WM_DEVMODECHANGE"},
+	{0x1c, "WM_ACTIVATEAPP"},
+	{0x1d, "WM_FONTCHANGE"},
+	{0x1e, "WM_TIMECHANGE"},
+	{0x1f, "WM_CANCELMODE"},
+	{0x20, "WM_SETCURSOR"},
+	{0x21, "WM_MOUSEACTIVATE"},
+	{0x22, "WM_CHILDACTIVATE"},
+	{0x23, "WM_QUEUESYNC"},
+	{0x24, "WM_GETMINMAXINFO"},
+	{0x26, "WM_PAINTICON"},
+	{0x27, "WM_ICONERASEBKGND"},
+	{0x28, "WM_NEXTDLGCTL"},
+	{0x2a, "WM_SPOOLERSTATUS"},
+	{0x2b, "WM_DRAWITEM"},
+	{0x2c, "WM_MEASUREITEM"},
+	{0x2d, "WM_DELETEITEM"},
+	{0x2e, "WM_VKEYTOITEM"},
+	{0x2f, "WM_CHARTOITEM"},
+	{0x30, "WM_SETFONT"},
+	{0x31, "WM_GETFONT"},
+	{0x32, "WM_SETHOTKEY"},
+	{0x33, "WM_GETHOTKEY"},
+	{0x37, "WM_QUERYDRAGICON"},
+	{0x39, "WM_COMPAREITEM"},
+	{0x3d, "WM_GETOBJECT"},
+	{0x41, "WM_COMPACTING"},
+	{0x44, "WM_COMMNOTIFY"},
+	{0x46, "WM_WINDOWPOSCHANGING"},
+	{0x47, "WM_WINDOWPOSCHANGED"},
+	{0x48, "WM_POWER"},
+	{0x4a, "WM_COPYDATA"},
+	{0x4b, "WM_CANCELJOURNAL"},
+	{0x4e, "WM_NOTIFY"},
+	{0x50, "WM_INPUTLANGCHANGEREQUEST"},
+	{0x51, "WM_INPUTLANGCHANGE"},
+	{0x52, "WM_TCARD"},
+	{0x53, "WM_HELP"},
+	{0x54, "WM_USERCHANGED"},
+	{0x55, "WM_NOTIFYFORMAT"},
+	{0x7b, "WM_CONTEXTMENU"},
+	{0x7c, "WM_STYLECHANGING"},
+	{0x7d, "WM_STYLECHANGED"},
+	{0x7e, "WM_DISPLAYCHANGE"},
+	{0x7f, "WM_GETICON"},
+	{0x80, "WM_SETICON"},
+	{0x81, "WM_NCCREATE"},
+	{0x82, "WM_NCDESTROY"},
+	{0x83, "WM_NCCALCSIZE"},
+	{0x84, "WM_NCHITTEST"},
+	{0x85, "WM_NCPAINT"},
+	{0x86, "WM_NCACTIVATE"},
+	{0x87, "WM_GETDLGCODE"},
+	{0x88, "WM_SYNCPAINT"},
+	{0xa0, "WM_NCMOUSEMOVE"},
+	{0xa1, "WM_NCLBUTTONDOWN"},
+	{0xa2, "WM_NCLBUTTONUP"},
+	{0xa3, "WM_NCLBUTTONDBLCLK"},
+	{0xa4, "WM_NCRBUTTONDOWN"},
+	{0xa5, "WM_NCRBUTTONUP"},
+	{0xa6, "WM_NCRBUTTONDBLCLK"},
+	{0xa7, "WM_NCMBUTTONDOWN"},
+	{0xa8, "WM_NCMBUTTONUP"},
+	{0xa9, "WM_NCMBUTTONDBLCLK"},
+	{0xab, "WM_NCXBUTTONDOWN"},
+	{0xac, "WM_NCXBUTTONUP"},
+	{0xad, "WM_NCXBUTTONDBLCLK"},
+	{0xff, "WM_INPUT"},
+	{0x100, "WM_KEYDOWN"},
+	{0x101, "WM_KEYUP"},
+	{0x102, "WM_CHAR"},
+	{0x103, "WM_DEADCHAR"},
+	{0x104, "WM_SYSKEYDOWN"},
+	{0x105, "WM_SYSKEYUP"},
+	{0x106, "WM_SYSCHAR"},
+	{0x107, "WM_SYSDEADCHAR"},
+	{0x108, "WM_KEYLAST"},
+	{0x110, "WM_INITDIALOG"},
+	{0x111, "WM_COMMAND"},
+	{0x112, "WM_SYSCOMMAND"},
+	{0x113, "WM_TIMER"},
+	{0x114, "WM_HSCROLL"},
+	{0x115, "WM_VSCROLL"},
+	{0x116, "WM_INITMENU"},
+	{0x117, "WM_INITMENUPOPUP"},
+	{0x11f, "WM_MENUSELECT"},
+	{0x120, "WM_MENUCHAR"},
+	{0x121, "WM_ENTERIDLE"},
+	{0x122, "WM_MENURBUTTONUP"},
+	{0x123, "WM_MENUDRAG"},
+	{0x124, "WM_MENUGETOBJECT"},
+	{0x125, "WM_UNINITMENUPOPUP"},
+	{0x126, "WM_MENUCOMMAND"},
+	{0x127, "WM_CHANGEUISTATE"},
+	{0x128, "WM_UPDATEUISTATE"},
+	{0x129, "WM_QUERYUISTATE"},
+	{0x132, "WM_CTLCOLORMSGBOX"},
+	{0x133, "WM_CTLCOLOREDIT"},
+	{0x134, "WM_CTLCOLORLISTBOX"},
+	{0x135, "WM_CTLCOLORBTN"},
+	{0x136, "WM_CTLCOLORDLG"},
+	{0x137, "WM_CTLCOLORSCROLLBAR"},
+	{0x138, "WM_CTLCOLORSTATIC"},
+	{0x200, "WM_MOUSEMOVE"},
+	{0x201, "WM_LBUTTONDOWN"},
+	{0x202, "WM_LBUTTONUP"},
+	{0x203, "WM_LBUTTONDBLCLK"},
+	{0x204, "WM_RBUTTONDOWN"},
+	{0x205, "WM_RBUTTONUP"},
+	{0x206, "WM_RBUTTONDBLCLK"},
+	{0x207, "WM_MBUTTONDOWN"},
+	{0x208, "WM_MBUTTONUP"},
+	{0x209, "WM_MBUTTONDBLCLK"},
+	{0x20a, "WM_MOUSEWHEEL"},
+	{0x20b, "WM_XBUTTONDOWN"},
+	{0x20c, "WM_XBUTTONUP"},
+	{0x20d, "WM_XBUTTONDBLCLK"},
+	{0x20e, "WM_MOUSEHWHEEL"},
+	{0x210, "WM_PARENTNOTIFY"},
+	{0x211, "WM_ENTERMENULOOP"},
+	{0x212, "WM_EXITMENULOOP"},
+	{0x213, "WM_NEXTMENU"},
+	{0x214, "WM_SIZING"},
+	{0x215, "WM_CAPTURECHANGED"},
+	{0x216, "WM_MOVING"},
+	{0x218, "WM_POWERBROADCAST"},
+	{0x219, "WM_DEVICECHANGE"},
+	{0x220, "WM_MDICREATE"},
+	{0x221, "WM_MDIDESTROY"},
+	{0x222, "WM_MDIACTIVATE"},
+	{0x223, "WM_MDIRESTORE"},
+	{0x224, "WM_MDINEXT"},
+	{0x225, "WM_MDIMAXIMIZE"},
+	{0x226, "WM_MDITILE"},
+	{0x227, "WM_MDICASCADE"},
+	{0x228, "WM_MDIICONARRANGE"},
+	{0x229, "WM_MDIGETACTIVE"},
+	{0x230, "WM_MDISETMENU"},
+	{0x231, "WM_ENTERSIZEMOVE"},
+	{0x232, "WM_EXITSIZEMOVE"},
+	{0x233, "WM_DROPFILES"},
+	{0x234, "WM_MDIREFRESHMENU"},
+	{0x2a0, "WM_NCMOUSEHOVER"},
+	{0x2a1, "WM_MOUSEHOVER"},
+	{0x2a2, "WM_NCMOUSELEAVE"},
+	{0x2a3, "WM_MOUSELEAVE"},
+	{0x300, "WM_CUT"},
+	{0x301, "WM_COPY"},
+	{0x302, "WM_PASTE"},
+	{0x303, "WM_CLEAR"},
+	{0x304, "WM_UNDO"},
+	{0x305, "WM_RENDERFORMAT"},
+	{0x306, "WM_RENDERALLFORMATS"},
+	{0x307, "WM_DESTROYCLIPBOARD"},
+	{0x308, "WM_DRAWCLIPBOARD"},
+	{0x309, "WM_PAINTCLIPBOARD"},
+	{0x30a, "WM_VSCROLLCLIPBOARD"},
+	{0x30b, "WM_SIZECLIPBOARD"},
+	{0x30c, "WM_ASKCBFORMATNAME"},
+	{0x30d, "WM_CHANGECBCHAIN"},
+	{0x30e, "WM_HSCROLLCLIPBOARD"},
+	{0x30f, "WM_QUERYNEWPALETTE"},
+	{0x310, "WM_PALETTEISCHANGING"},
+	{0x311, "WM_PALETTECHANGED"},
+	{0x312, "WM_HOTKEY"},
+	{0x317, "WM_PRINT"},
+	{0x318, "WM_PRINTCLIENT"},
+	{0x319, "WM_APPCOMMAND"},
+	{0x31a, "WM_THEMECHANGED"},
+	{0x358, "WM_HANDHELDFIRST"},
+	{0x35f, "WM_HANDHELDLAST"},
+	{0x360, "WM_AFXFIRST"},
+	{0x37f, "WM_AFXLAST"},
+	{0x380, "WM_PENWINFIRST"},
+	{0x38f, "WM_PENWINLAST"},
+	{0x400, "WM_USER"},
+	{0x8000, "WM_APP"},
+}
+
+// VK_*
+var vkNames = enumNames{
+	{0x1, "VK_LBUTTON"},
+	{0x2, "VK_RBUTTON"},
+	{0x3, "VK_CANCEL"},
+	{0x4, "VK_MBUTTON"},
+	{0x8, "VK_BACK"},
+	{0x9, "VK_TAB"},
+	{0xc, "VK_CLEAR"},
+	{0xd, "VK_RETURN"},
+	{0x10, "VK_SHIFT"},
+	{0x11, "VK_CONTROL"},
+	{0x12, "VK_MENU"},
+	{0x13, "VK_PAUSE"},
+	{0x14, "VK_CAPITAL"},
+	{0x15, "VK_KANA"},
+	{0x19, "VK_KANJI"},
+	{0x1b, "VK_ESCAPE"},
+	{0x1c, "VK_CONVERT"},
+	{0x20, "VK_SPACE"},
+	{0x21, "VK_PRIOR"},
+	{0x22, "VK_NEXT"},
+	{0x23, "VK_END"},
+	{0x24, "VK_HOME"},
+	{0x25, "VK_LEFT"},
+	{0x26, "VK_UP"},
+	{0x27, "VK_RIGHT"},
+	{0x28, "VK_DOWN"},
+	{0x29, "VK_SELECT"},
+	{0x2a, "VK_PRINT"},
+	{0x2b, "VK_EXECUTE"},
+	{0x2c, "VK_SNAPSHOT"},
+	{0x2d, "VK_INSERT"},
+	{0x2e, "VK_DELETE"},
+	{0x2f, "VK_HELP"},
+	{0x5b, "VK_LWIN"},
+	{0x5c, "VK_RWIN"},
+	{0x5d, "VK_APPS"},
+	{0x5f, "VK_SLEEP"},
+	{0x60, "VK_NUMPAD0"},
+	{0x61, "VK_NUMPAD1"},
+	{0x62, "VK_NUMPAD2"},
+	{0x63, "VK_NUMPAD3"},
+	{0x64, "VK_NUMPAD4"},
+	{0x65, "VK_NUMPAD5"},
+	{0x66, "VK_NUMPAD6"},
+	{0x67, "VK_NUMPAD7"},
+	{0x68, "VK_NUMPAD8"},
+	{0x69, "VK_NUMPAD9"},
+	{0x6a, "VK_MULTIPLY"},
+	{0x6b, "VK_ADD"},
+	{0x6c, "VK_SEPARATOR"},
+	{0x6d, "VK_SUBTRACT"},
+	{0x6e, "VK_DECIMAL"},
+	{0x6f, "VK_DIVIDE"},
+	{0x70, "VK_F1"},
+	{0x71, "VK_F2"},
+	{0x72, "VK_F3"},
+	{0x73, "VK_F4"},
+	{0x74, "VK_F5"},
+	{0x75, "VK_F6"},
+	{0x76, "VK_F7"},
+	{0x77, "VK_F8"},
+	{0x78, "VK_F9"},
+	{0x79, "VK_F10"},
+	{0x7a, "VK_F11"},
+	{0x7b, "VK_F12"},
+	{0x7c, "VK_F13"},
+	{0x7d, "VK_F14"},
+	{0x7e, "VK_F15"},
+	{0x7f, "VK_F16"},
+	{0x80, "VK_F17"},
+	{0x81, "VK_F18"},
+	{0x82, "VK_F19"},
+	{0x83, "VK_F20"},
+	{0x84, "VK_F21"},
+	{0x85, "VK_F22"},
+	{0x86, "VK_F23"},
+	{0x87, "VK_F24"},
+	{0x90, "VK_NUMLOCK"},
+	{0x91, "VK_SCROLL"},
+	{0xa0, "VK_LSHIFT"},
+	{0xa1, "VK_RSHIFT"},
+	{0xa2, "VK_LCONTROL"},
+	{0xa3, "VK_RCONTROL"},
+	{0xa4, "VK_LMENU"},
+	{0xa5, "VK_RMENU"},
+	{0xad, "VK_VOLUME_MUTE"},
+	{0xae, "VK_VOLUME_DOWN"},
+	{0xaf, "VK_VOLUME_UP"},
+	{0xb0, "VK_MEDIA_NEXT_TRACK"},
+	{0xb1, "VK_MEDIA_PREV_TRACK"},
+	{0xb2, "VK_MEDIA_STOP"},
+	{0xb3, "VK_MEDIA_PLAY_PAUSE"},
+	{0xba, "VK_OEM_1"},
+	{0xbb, "VK_OEM_PLUS"},
+	{0xbc, "VK_OEM_COMMA"},
+	{0xbd, "VK_OEM_MINUS"},
+	{0xbe, "VK_OEM_PERIOD"},
+	{0xbf, "VK_OEM_2"},
+	{0xc0, "VK_OEM_3"},
+	{0xdb, "VK_OEM_4"},
+	{0xdc, "VK_OEM_5"},
+	{0xdd, "VK_OEM_6"},
+	{0xde, "VK_OEM_7"},
+	{0xdf, "VK_OEM_8"},
+	{0xe2, "VK_OEM_102"},
+	{0xe5, "VK_PROCESSKEY"},
+	{0xe7, "VK_PACKET"},
+}
+
+// SC_*
+var scNames = enumNames{
+	{0xf000, "SC_SIZE"},
+	{0xf00f, "SC_SEPARATOR"},
+	{0xf010, "SC_MOVE"},
+	{0xf020, "SC_MINIMIZE"},
+	{0xf030, "SC_MAXIMIZE"},
+	{0xf040, "SC_NEXTWINDOW"},
+	{0xf050, "SC_PREVWINDOW"},
+	{0xf060, "SC_CLOSE"},
+	{0xf070, "SC_VSCROLL"},
+	{0xf080, "SC_HSCROLL"},
+	{0xf090, "SC_MOUSEMENU"},
+	{0xf100, "SC_KEYMENU"},
+	{0xf110, "SC_ARRANGE"},
+	{0xf120, "SC_RESTORE"},
+	{0xf130, "SC_TASKLIST"},
+	{0xf140, "SC_SCREENSAVE"},
+	{0xf150, "SC_HOTKEY"},
+	{0xf160, "SC_DEFAULT"},
+	{0xf170, "SC_MONITORPOWER"},
+	{0xf180, "SC_CONTEXTHELP"},
+}
+
+// SIZE_*
+var sizeNames = enumNames{
+	{0x0, "SIZE_RESTORED"},
+	{0x1, "SIZE_MINIMIZED"},
+	{0x2, "SIZE_MAXIMIZED"},
+	{0x3, "SIZE_MAXSHOW"},
+	{0x4, "SIZE_MAXHIDE"},
+}
+
+// WA_*
+var waNames = enumNames{
+	{0x0, "WA_INACTIVE"},
+	{0x1, "WA_ACTIVE"},
+	{0x2, "WA_CLICKACTIVE"},
+}
+
+// SWP_*
+var swpNames = flagNames{
+	names: []constName{
+		{0x1, "SWP_NOSIZE"},
+		{0x2, "SWP_NOMOVE"},
+		{0x4, "SWP_NOZORDER"},
+		{0x8, "SWP_NOREDRAW"},
+		{0x10, "SWP_NOACTIVATE"},
+		{0x20, "SWP_FRAMECHANGED"},
+		{0x40, "SWP_SHOWWINDOW"},
+		{0x80, "SWP_HIDEWINDOW"},
+		{0x100, "SWP_NOCOPYBITS"},
+		{0x200, "SWP_NOOWNERZORDER"},
+		{0x400, "SWP_NOSENDCHANGING"},
+		{0x2000, "SWP_DEFERERASE"},
+		{0x4000, "SWP_ASYNCWINDOWPOS"},
+	},
+}
+
+// WS_*
+var wsNames = flagNames{
+	zero: "WS_OVERLAPPED",
+	names: []constName{
+		{0xcf0000, "WS_OVERLAPPEDWINDOW"},
+		{0x80880000, "WS_POPUPWINDOW"},
+		{0xc00000, "WS_CAPTION"},
+		{0x80000000, "WS_POPUP"},
+		{0x40000000, "WS_CHILD"},
+		{0x20000000, "WS_MINIMIZE"},
+		{0x10000000, "WS_VISIBLE"},
+		{0x8000000, "WS_DISABLED"},
+		{0x4000000, "WS_CLIPSIBLINGS"},
+		{0x2000000, "WS_CLIPCHILDREN"},
+		{0x1000000, "WS_MAXIMIZE"},
+		{0x800000, "WS_BORDER"},
+		{0x400000, "WS_DLGFRAME"},
+		{0x200000, "WS_VSCROLL"},
+		{0x100000, "WS_HSCROLL"},
+		{0x80000, "WS_SYSMENU"},
+		{0x40000, "WS_THICKFRAME"},
+		{0x20000, "WS_GROUP"},
+		{0x10000, "WS_TABSTOP"},
+	},
+}
+
+// WS_EX_*
+var wsExNames = flagNames{
+	zero: "WS_EX_LEFT",
+	names: []constName{
+		{0x188, "WS_EX_PALETTEWINDOW"},
+		{0x300, "WS_EX_OVERLAPPEDWINDOW"},
+		{0x1, "WS_EX_DLGMODALFRAME"},
+		{0x4, "WS_EX_NOPARENTNOTIFY"},
+		{0x8, "WS_EX_TOPMOST"},
+		{0x10, "WS_EX_ACCEPTFILES"},
+		{0x20, "WS_EX_TRANSPARENT"},
+		{0x40, "WS_EX_MDICHILD"},
+		{0x80, "WS_EX_TOOLWINDOW"},
+		{0x100, "WS_EX_WINDOWEDGE"},
+		{0x200, "WS_EX_CLIENTEDGE"},
+		{0x400, "WS_EX_CONTEXTHELP"},
+		{0x1000, "WS_EX_RIGHT"},
+		{0x2000, "WS_EX_RTLREADING"},
+		{0x4000, "WS_EX_LEFTSCROLLBAR"},
+		{0x10000, "WS_EX_CONTROLPARENT"},
+		{0x20000, "WS_EX_STATICEDGE"},
+		{0x40000, "WS_EX_APPWINDOW"},
+		{0x80000, "WS_EX_LAYERED"},
+		{0x100000, "WS_EX_NOINHERITLAYOUT"},
+		{0x400000, "WS_EX_LAYOUTRTL"},
+		{0x8000000, "WS_EX_NOACTIVATE"},
+		{0x2000000, "WS_EX_COMPOSITED"},
+	},
+}
+
+// MB_*
+var mbNames = flagNames{
+	zero:  "MB_OK",
+	masks: []uint32{0xf, 0xf0, 0xf00, 0x3000},
+	names: []constName{
+		{0x3, "MB_YESNOCANCEL"},
+		{0x5, "MB_RETRYCANCEL"},
+		{0x6, "MB_CANCELTRYCONTINUE"},
+		{0x30, "MB_ICONEXCLAMATION"},
+		{0x300, "MB_DEFBUTTON4"},
+		{0x1, "MB_OKCANCEL"},
+		{0x2, "MB_ABORTRETRYIGNORE"},
+		{0x4, "MB_YESNO"},
+		{0x10, "MB_ICONHAND"},
+		{0x20, "MB_ICONQUESTION"},
+		{0x40, "MB_ICONASTERISK"},
+		{0x80, "MB_USERICON"},
+		{0x100, "MB_DEFBUTTON2"},
+		{0x200, "MB_DEFBUTTON3"},
+		{0x1000, "MB_SYSTEMMODAL"},
+		{0x2000, "MB_TASKMODAL"},
+		{0x4000, "MB_HELP"},
+		{0x8000, "MB_NOFOCUS"},
+		{0x10000, "MB_SETFOREGROUND"},
+		{0x20000, "MB_DEFAULT_DESKTOP_ONLY"},
+		{0x40000, "MB_TOPMOST"},
+		{0x80000, "MB_RIGHT"},
+		{0x100000, "MB_RTLREADING"},
+		{0x200000, "MB_SERVICE_NOTIFICATION"},
+	},
+}
+
+// TPM_*
+var tpmNames = flagNames{
+	zero:  "TPM_LEFTBUTTON",
+	masks: []uint32{0xc, 0x30},
+	names: []constName{
+		{0x2, "TPM_RIGHTBUTTON"},
+		{0x4, "TPM_CENTERALIGN"},
+		{0x8, "TPM_RIGHTALIGN"},
+		{0x10, "TPM_VCENTERALIGN"},
+		{0x20, "TPM_BOTTOMALIGN"},
+		{0x40, "TPM_VERTICAL"},
+		{0x80, "TPM_NONOTIFY"},
+		{0x100, "TPM_RETURNCMD"},
+		{0x1, "TPM_RECURSE"},
+		{0x400, "TPM_HORPOSANIMATION"},
+		{0x800, "TPM_HORNEGANIMATION"},
+		{0x1000, "TPM_VERPOSANIMATION"},
+		{0x2000, "TPM_VERNEGANIMATION"},
+		{0x4000, "TPM_NOANIMATION"},
+		{0x8000, "TPM_LAYOUTRTL"},
+		{0x10000, "TPM_WORKAREA"},
+	},
+}
+
+// MK_*
+var mkNames = flagNames{
+	names: []constName{
+		{0x8, "MK_CONTROL"},
+		{0x1, "MK_LBUTTON"},
+		{0x10, "MK_MBUTTON"},
+		{0x2, "MK_RBUTTON"},
+		{0x4, "MK_SHIFT"},
+		{0x20, "MK_XBUTTON1"},
+		{0x40, "MK_XBUTTON2"},
+	},
+}
+
+// MOD_*
+var modNames = flagNames{
+	names: []constName{
+		{0x1, "MOD_ALT"},
+		{0x2, "MOD_CONTROL"},
+		{0x4, "MOD_SHIFT"},
+		{0x8, "MOD_WIN"},
+		{0x4000, "MOD_NOREPEAT"},
+	},
+}
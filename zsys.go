@@ -0,0 +1,439 @@
+// Code generated by mkwinapi; DO NOT EDIT.
+
+package winapi
+
+import (
+	"unsafe"
+
+	"github.com/FxStar/winapi/internal/proc"
+)
+
+var (
+	dllKernel32 = proc.NewDLL("kernel32.dll")
+	dllShell32  = proc.NewDLL("shell32.dll")
+	dllUser32   = proc.NewDLL("user32.dll")
+
+	procCheckMenuItem          = dllUser32.NewProc("CheckMenuItem")
+	procCheckMenuRadioItem     = dllUser32.NewProc("CheckMenuRadioItem")
+	procCreateMenu             = dllUser32.NewProc("CreateMenu")
+	procCreatePopupMenu        = dllUser32.NewProc("CreatePopupMenu")
+	procCreateWindowExA        = dllUser32.NewProc("CreateWindowExA")
+	procCreateWindowExW        = dllUser32.NewProc("CreateWindowExW")
+	procDefWindowProcW         = dllUser32.NewProc("DefWindowProcW")
+	procDestroyIcon            = dllUser32.NewProc("DestroyIcon")
+	procDestroyMenu            = dllUser32.NewProc("DestroyMenu")
+	procDestroyWindow          = dllUser32.NewProc("DestroyWindow")
+	procDispatchMessageW       = dllUser32.NewProc("DispatchMessageW")
+	procDrawMenuBar            = dllUser32.NewProc("DrawMenuBar")
+	procEnableMenuItem         = dllUser32.NewProc("EnableMenuItem")
+	procFormatMessageW         = dllKernel32.NewProc("FormatMessageW")
+	procGetCurrentProcessId    = dllKernel32.NewProc("GetCurrentProcessId")
+	procGetLastInputInfo       = dllUser32.NewProc("GetLastInputInfo")
+	procGetMenuItemCount       = dllUser32.NewProc("GetMenuItemCount")
+	procGetMessageW            = dllUser32.NewProc("GetMessageW")
+	procGetSystemMetrics       = dllUser32.NewProc("GetSystemMetrics")
+	procGetTickCount           = dllKernel32.NewProc("GetTickCount")
+	procGetTickCount64         = dllKernel32.NewProc("GetTickCount64")
+	procInsertMenuItemW        = dllUser32.NewProc("InsertMenuItemW")
+	procIsWindow               = dllUser32.NewProc("IsWindow")
+	procIsWindowVisible        = dllUser32.NewProc("IsWindowVisible")
+	procMapVirtualKeyW         = dllUser32.NewProc("MapVirtualKeyW")
+	procPostMessageW           = dllUser32.NewProc("PostMessageW")
+	procPostQuitMessage        = dllUser32.NewProc("PostQuitMessage")
+	procPostThreadMessageW     = dllUser32.NewProc("PostThreadMessageW")
+	procRegisterClassExW       = dllUser32.NewProc("RegisterClassExW")
+	procRegisterClassW         = dllUser32.NewProc("RegisterClassW")
+	procRegisterWindowMessageW = dllUser32.NewProc("RegisterWindowMessageW")
+	procRemoveMenu             = dllUser32.NewProc("RemoveMenu")
+	procSendMessageW           = dllUser32.NewProc("SendMessageW")
+	procSetCursor              = dllUser32.NewProc("SetCursor")
+	procSetFilePointerEx       = dllKernel32.NewProc("SetFilePointerEx")
+	procSetMenuItemInfoW       = dllUser32.NewProc("SetMenuItemInfoW")
+	procShell_NotifyIconW      = dllShell32.NewProc("Shell_NotifyIconW")
+	procShowCursor             = dllUser32.NewProc("ShowCursor")
+	procShowWindow             = dllUser32.NewProc("ShowWindow")
+	procTrackPopupMenuEx       = dllUser32.NewProc("TrackPopupMenuEx")
+	procTranslateMessage       = dllUser32.NewProc("TranslateMessage")
+	procUpdateWindow           = dllUser32.NewProc("UpdateWindow")
+)
+
+func RegisterClassExW(wndclass *Wndclassex) (atom uint16, err error) {
+	r1, _, e1 := procRegisterClassExW.Call(uintptr(unsafe.Pointer(wndclass)))
+	atom = uint16(r1)
+	if atom == 0 {
+		err = proc.LastError("RegisterClassExW", e1)
+	}
+	return
+}
+
+func RegisterClassW(wndclass *Wndclass) (atom uint16, err error) {
+	r1, _, e1 := procRegisterClassW.Call(uintptr(unsafe.Pointer(wndclass)))
+	atom = uint16(r1)
+	if atom == 0 {
+		err = proc.LastError("RegisterClassW", e1)
+	}
+	return
+}
+
+// CreateWindowExW passes an empty classname as NULL.
+func CreateWindowExW(exstyle uint32, classname string, windowname string, style uint32, x int32, y int32, width int32, height int32, wndparent HWND, menu HMENU, instance HINSTANCE, param uintptr) (hwnd HWND, err error) {
+	var _p1 *uint16
+	if classname != "" {
+		_p1, err = proc.UTF16PtrFromString(classname)
+		if err != nil {
+			return
+		}
+	}
+	var _p2 *uint16
+	_p2, err = proc.UTF16PtrFromString(windowname)
+	if err != nil {
+		return
+	}
+	r1, _, e1 := procCreateWindowExW.Call(uintptr(exstyle), uintptr(unsafe.Pointer(_p1)), uintptr(unsafe.Pointer(_p2)), uintptr(style), uintptr(x), uintptr(y), uintptr(width), uintptr(height), uintptr(wndparent), uintptr(menu), uintptr(instance), uintptr(param))
+	hwnd = HWND(r1)
+	if hwnd == 0 {
+		err = proc.LastError("CreateWindowExW", e1)
+	}
+	return
+}
+
+// CreateWindowExA passes classname and windowname as bytes, they should be
+// ASCII.
+func CreateWindowExA(exstyle uint32, classname string, windowname string, style uint32, x int32, y int32, width int32, height int32, wndparent HWND, menu HMENU, instance HINSTANCE, param uintptr) (hwnd HWND, err error) {
+	var _p1 *byte
+	_p1, err = proc.BytePtrFromString(classname)
+	if err != nil {
+		return
+	}
+	var _p2 *byte
+	_p2, err = proc.BytePtrFromString(windowname)
+	if err != nil {
+		return
+	}
+	r1, _, e1 := procCreateWindowExA.Call(uintptr(exstyle), uintptr(unsafe.Pointer(_p1)), uintptr(unsafe.Pointer(_p2)), uintptr(style), uintptr(x), uintptr(y), uintptr(width), uintptr(height), uintptr(wndparent), uintptr(menu), uintptr(instance), uintptr(param))
+	hwnd = HWND(r1)
+	if hwnd == 0 {
+		err = proc.LastError("CreateWindowExA", e1)
+	}
+	return
+}
+
+func DefWindowProcW(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (lresult uintptr) {
+	r1, _, _ := procDefWindowProcW.Call(uintptr(hwnd), uintptr(msg), uintptr(wparam), uintptr(lparam))
+	lresult = uintptr(r1)
+	return
+}
+
+func DestroyWindow(hwnd HWND) (err error) {
+	r1, _, e1 := procDestroyWindow.Call(uintptr(hwnd))
+	if r1 == 0 {
+		err = proc.LastError("DestroyWindow", e1)
+	}
+	return
+}
+
+func PostQuitMessage(exitcode int32) {
+	procPostQuitMessage.Call(uintptr(exitcode))
+}
+
+func ShowWindow(hwnd HWND, cmdshow int32) (wasvisible bool) {
+	r1, _, _ := procShowWindow.Call(uintptr(hwnd), uintptr(cmdshow))
+	wasvisible = r1 != 0
+	return
+}
+
+func UpdateWindow(hwnd HWND) (err error) {
+	r1, _, e1 := procUpdateWindow.Call(uintptr(hwnd))
+	if r1 == 0 {
+		err = proc.LastError("UpdateWindow", e1)
+	}
+	return
+}
+
+// GetMessage returns 0 for WM_QUIT.
+func GetMessage(msg *Msg, hwnd HWND, MsgFilterMin uint32, MsgFilterMax uint32) (ret int32, err error) {
+	r1, _, e1 := procGetMessageW.Call(uintptr(unsafe.Pointer(msg)), uintptr(hwnd), uintptr(MsgFilterMin), uintptr(MsgFilterMax))
+	ret = int32(r1)
+	if ret == -1 {
+		err = proc.LastError("GetMessageW", e1)
+	}
+	return
+}
+
+func TranslateMessage(msg *Msg) (done bool) {
+	r1, _, _ := procTranslateMessage.Call(uintptr(unsafe.Pointer(msg)))
+	done = r1 != 0
+	return
+}
+
+func DispatchMessageW(msg *Msg) (ret int32) {
+	r1, _, _ := procDispatchMessageW.Call(uintptr(unsafe.Pointer(msg)))
+	ret = int32(r1)
+	return
+}
+
+func SetCursor(cursor HCURSOR) (precursor HCURSOR, err error) {
+	r1, _, e1 := procSetCursor.Call(uintptr(cursor))
+	precursor = HCURSOR(r1)
+	if precursor == 0 {
+		err = proc.LastError("SetCursor", e1)
+	}
+	return
+}
+
+func SendMessage(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (lresult uintptr) {
+	r1, _, _ := procSendMessageW.Call(uintptr(hwnd), uintptr(msg), uintptr(wparam), uintptr(lparam))
+	lresult = uintptr(r1)
+	return
+}
+
+func PostMessage(hwnd HWND, msg UINT, wparam WPARAM, lparam LPARAM) (err error) {
+	r1, _, e1 := procPostMessageW.Call(uintptr(hwnd), uintptr(msg), uintptr(wparam), uintptr(lparam))
+	if r1 == 0 {
+		err = proc.LastError("PostMessageW", e1)
+	}
+	return
+}
+
+func PostThreadMessage(threadID uint32, msg UINT, wparam WPARAM, lparam LPARAM) (err error) {
+	r1, _, e1 := procPostThreadMessageW.Call(uintptr(threadID), uintptr(msg), uintptr(wparam), uintptr(lparam))
+	if r1 == 0 {
+		err = proc.LastError("PostThreadMessageW", e1)
+	}
+	return
+}
+
+// GetLastInputInfo sets lii.DwTime to the tick count of the last input event
+// of the session, lii.CbSize must be set.
+func GetLastInputInfo(lii *LASTINPUTINFO) (err error) {
+	r1, _, e1 := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(lii)))
+	if r1 == 0 {
+		err = proc.LastError("GetLastInputInfo", e1)
+	}
+	return
+}
+
+func RegisterWindowMessage(name string) (msg uint32, err error) {
+	var _p0 *uint16
+	_p0, err = proc.UTF16PtrFromString(name)
+	if err != nil {
+		return
+	}
+	r1, _, e1 := procRegisterWindowMessageW.Call(uintptr(unsafe.Pointer(_p0)))
+	msg = uint32(r1)
+	if msg == 0 {
+		err = proc.LastError("RegisterWindowMessageW", e1)
+	}
+	return
+}
+
+func IsWindow(hwnd HWND) (ok bool) {
+	r1, _, _ := procIsWindow.Call(uintptr(hwnd))
+	ok = r1 != 0
+	return
+}
+
+func IsWindowVisible(hwnd HWND) (visible bool) {
+	r1, _, _ := procIsWindowVisible.Call(uintptr(hwnd))
+	visible = r1 != 0
+	return
+}
+
+// ShowCursor returns the new display counter, the cursor shows when it is >= 0.
+func ShowCursor(show bool) (count int32) {
+	var _p0 uint32
+	if show {
+		_p0 = 1
+	}
+	r1, _, _ := procShowCursor.Call(uintptr(_p0))
+	count = int32(r1)
+	return
+}
+
+func GetSystemMetrics(index int32) (value int32) {
+	r1, _, _ := procGetSystemMetrics.Call(uintptr(index))
+	value = int32(r1)
+	return
+}
+
+func MapVirtualKey(code uint32, mapType uint32) (ret uint32) {
+	r1, _, _ := procMapVirtualKeyW.Call(uintptr(code), uintptr(mapType))
+	ret = uint32(r1)
+	return
+}
+
+func DestroyIcon(icon HICON) (err error) {
+	r1, _, e1 := procDestroyIcon.Call(uintptr(icon))
+	if r1 == 0 {
+		err = proc.LastError("DestroyIcon", e1)
+	}
+	return
+}
+
+func CreateMenu() (menu HMENU, err error) {
+	r1, _, e1 := procCreateMenu.Call()
+	menu = HMENU(r1)
+	if menu == 0 {
+		err = proc.LastError("CreateMenu", e1)
+	}
+	return
+}
+
+// CreatePopupMenu returned an HWND before it was generated, see the README.
+func CreatePopupMenu() (menu HMENU, err error) {
+	r1, _, e1 := procCreatePopupMenu.Call()
+	menu = HMENU(r1)
+	if menu == 0 {
+		err = proc.LastError("CreatePopupMenu", e1)
+	}
+	return
+}
+
+func DestroyMenu(menu HMENU) (err error) {
+	r1, _, e1 := procDestroyMenu.Call(uintptr(menu))
+	if r1 == 0 {
+		err = proc.LastError("DestroyMenu", e1)
+	}
+	return
+}
+
+func DrawMenuBar(hwnd HWND) (err error) {
+	r1, _, e1 := procDrawMenuBar.Call(uintptr(hwnd))
+	if r1 == 0 {
+		err = proc.LastError("DrawMenuBar", e1)
+	}
+	return
+}
+
+func GetMenuItemCount(menu HMENU) (n int32, err error) {
+	r1, _, e1 := procGetMenuItemCount.Call(uintptr(menu))
+	n = int32(r1)
+	if n == -1 {
+		err = proc.LastError("GetMenuItemCount", e1)
+	}
+	return
+}
+
+// CheckMenuItem returns the previous MF_CHECKED or MF_UNCHECKED, -1 if the
+// item doesn't exist.
+func CheckMenuItem(menu HMENU, item uint32, check uint32) (prev int32) {
+	r1, _, _ := procCheckMenuItem.Call(uintptr(menu), uintptr(item), uintptr(check))
+	prev = int32(r1)
+	return
+}
+
+// EnableMenuItem returns the previous MF_ENABLED, MF_GRAYED or MF_DISABLED,
+// -1 if the item doesn't exist.
+func EnableMenuItem(menu HMENU, item uint32, enable uint32) (prev int32) {
+	r1, _, _ := procEnableMenuItem.Call(uintptr(menu), uintptr(item), uintptr(enable))
+	prev = int32(r1)
+	return
+}
+
+func CheckMenuRadioItem(menu HMENU, first uint32, last uint32, check uint32, flags uint32) (err error) {
+	r1, _, e1 := procCheckMenuRadioItem.Call(uintptr(menu), uintptr(first), uintptr(last), uintptr(check), uintptr(flags))
+	if r1 == 0 {
+		err = proc.LastError("CheckMenuRadioItem", e1)
+	}
+	return
+}
+
+func RemoveMenu(menu HMENU, position uint32, flags uint32) (err error) {
+	r1, _, e1 := procRemoveMenu.Call(uintptr(menu), uintptr(position), uintptr(flags))
+	if r1 == 0 {
+		err = proc.LastError("RemoveMenu", e1)
+	}
+	return
+}
+
+// InsertMenuItem inserts mii before the item at position, or with the id
+// item, mii.CbSize must be set.
+func InsertMenuItem(menu HMENU, item uint32, byPosition bool, mii *MENUITEMINFO) (err error) {
+	var _p2 uint32
+	if byPosition {
+		_p2 = 1
+	}
+	r1, _, e1 := procInsertMenuItemW.Call(uintptr(menu), uintptr(item), uintptr(_p2), uintptr(unsafe.Pointer(mii)))
+	if r1 == 0 {
+		err = proc.LastError("InsertMenuItemW", e1)
+	}
+	return
+}
+
+func SetMenuItemInfo(menu HMENU, item uint32, byPosition bool, mii *MENUITEMINFO) (err error) {
+	var _p2 uint32
+	if byPosition {
+		_p2 = 1
+	}
+	r1, _, e1 := procSetMenuItemInfoW.Call(uintptr(menu), uintptr(item), uintptr(_p2), uintptr(unsafe.Pointer(mii)))
+	if r1 == 0 {
+		err = proc.LastError("SetMenuItemInfoW", e1)
+	}
+	return
+}
+
+// TrackPopupMenuEx returns the chosen command with TPM_RETURNCMD, 0 when the
+// menu was dismissed. params may be nil.
+func TrackPopupMenuEx(menu HMENU, flags uint32, x int32, y int32, hwnd HWND, params *TPMPARAMS) (cmd int32) {
+	r1, _, _ := procTrackPopupMenuEx.Call(uintptr(menu), uintptr(flags), uintptr(x), uintptr(y), uintptr(hwnd), uintptr(unsafe.Pointer(params)))
+	cmd = int32(r1)
+	return
+}
+
+func Shell_NotifyIcon(message uint32, data *NOTIFYICONDATA) (err error) {
+	r1, _, e1 := procShell_NotifyIconW.Call(uintptr(message), uintptr(unsafe.Pointer(data)))
+	if r1 == 0 {
+		err = proc.LastError("Shell_NotifyIconW", e1)
+	}
+	return
+}
+
+func GetTickCount() (ms uint32) {
+	r1, _, _ := procGetTickCount.Call()
+	ms = uint32(r1)
+	return
+}
+
+func GetTickCount64() (ms uint64) {
+	r1, r2, _ := procGetTickCount64.Call()
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		ms = uint64(r1) | uint64(r2)<<32
+	} else {
+		ms = uint64(r1)
+	}
+	return
+}
+
+func GetCurrentProcessId() (pid uint32) {
+	r1, _, _ := procGetCurrentProcessId.Call()
+	pid = uint32(r1)
+	return
+}
+
+// FormatMessage writes the message into buf, n is its length without the NUL.
+func FormatMessage(flags uint32, source uintptr, msgID uint32, langID uint32, buf []uint16, args *byte) (n uint32, err error) {
+	var _p4 *uint16
+	if len(buf) > 0 {
+		_p4 = &buf[0]
+	}
+	r1, _, e1 := procFormatMessageW.Call(uintptr(flags), uintptr(source), uintptr(msgID), uintptr(langID), uintptr(unsafe.Pointer(_p4)), uintptr(len(buf)), uintptr(unsafe.Pointer(args)))
+	n = uint32(r1)
+	if n == 0 {
+		err = proc.LastError("FormatMessageW", e1)
+	}
+	return
+}
+
+func SetFilePointerEx(file HANDLE, distance int64, newPos *int64, method uint32) (err error) {
+	var r1 uintptr
+	var e1 error
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		r1, _, e1 = procSetFilePointerEx.Call(uintptr(file), uintptr(distance), uintptr(distance>>32), uintptr(unsafe.Pointer(newPos)), uintptr(method))
+	} else {
+		r1, _, e1 = procSetFilePointerEx.Call(uintptr(file), uintptr(distance), uintptr(unsafe.Pointer(newPos)), uintptr(method))
+	}
+	if r1 == 0 {
+		err = proc.LastError("SetFilePointerEx", e1)
+	}
+	return
+}